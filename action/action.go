@@ -2,6 +2,7 @@ package action
 
 import (
 	"fmt"
+	"gglow/glow"
 	"gglow/iohandler"
 	"gglow/store"
 	"strings"
@@ -14,6 +15,7 @@ type Action struct {
 	Input       *iohandler.Accessor
 	FilterItems []*FilterItem
 	Outputs     []*iohandler.Accessor
+	Power       *glow.PowerAnalyzer `yaml:",omitempty"`
 	Notes       []string
 	Errors      []string
 	filter      Filter
@@ -24,6 +26,7 @@ type ActionView struct {
 	Input       *iohandler.AccessorView
 	FilterItems []*FilterItem
 	Outputs     []*iohandler.AccessorView
+	Power       *glow.PowerAnalyzer `yaml:",omitempty"`
 	Notes       []string
 	Errors      []string
 }
@@ -44,6 +47,7 @@ func (a *Action) NewActionView() string {
 		Method:      a.Method,
 		Input:       iohandler.NewAccessorView(a.Input),
 		FilterItems: a.FilterItems,
+		Power:       a.Power,
 		Notes:       a.Notes,
 		Errors:      a.Errors,
 		Outputs:     make([]*iohandler.AccessorView, len(a.Outputs)),
@@ -60,8 +64,13 @@ func (a *Action) Process() (err error) {
 	switch strings.ToLower(a.Method) {
 	case "verify":
 		a.Verify()
+		if !a.HasErrors() {
+			err = a.Lint()
+		}
 	case "update", "clone":
 		err = a.Copy()
+	case "power":
+		err = a.Estimate()
 	default:
 		err = a.AddError(fmt.Errorf("unknown method %s", a.Method))
 	}
@@ -83,6 +92,87 @@ func (a *Action) Copy() (err error) {
 	return
 }
 
+// Estimate reports the current each selected effect draws and
+// records an error for each one over the power budget.
+func (a *Action) Estimate() (err error) {
+	if a.Power == nil {
+		a.Power = glow.NewPowerAnalyzer()
+	}
+	return a.eachEffect(a.estimateEffect)
+}
+
+// Lint records the diagnostics of each selected effect, errors as
+// errors and warnings as notes.
+func (a *Action) Lint() error {
+	return a.eachEffect(a.lintEffect)
+}
+
+// eachEffect calls do with every effect of the input the filter selects.
+func (a *Action) eachEffect(do func(dataIn iohandler.IoHandler, folder, item string)) (err error) {
+	var dataIn iohandler.IoHandler
+	dataIn, err = store.NewIoHandler(a.Input)
+	if err != nil {
+		return a.AddError(err)
+	}
+	defer dataIn.OnExit()
+
+	folders, err := dataIn.ListFolders()
+	if err != nil {
+		return a.AddError(fmt.Errorf("dataIn ListFolders %s", err))
+	}
+
+	a.filter = NewFilter(a.FilterItems)
+	for _, folder := range folders {
+		if !a.filter.IsSelected(folder) {
+			continue
+		}
+		items, err := dataIn.ListEffects(folder)
+		if err != nil {
+			return a.AddError(fmt.Errorf("ReadFolder %s: %v", folder, err))
+		}
+		for _, item := range items {
+			if iohandler.IsFolder(item) || !a.filter.IsSelected(folder, item) {
+				continue
+			}
+			do(dataIn, folder, item)
+		}
+	}
+	return
+}
+
+func (a *Action) lintEffect(dataIn iohandler.IoHandler, folder, item string) {
+	frame, err := dataIn.ReadEffect(folder, item)
+	if err != nil {
+		a.AddError(fmt.Errorf("ReadEffect %s.%s: %v", folder, item, err))
+		return
+	}
+	for _, diagnostic := range frame.Lint() {
+		if diagnostic.Severity == glow.SeverityError {
+			a.AddError(fmt.Errorf("lint %s.%s %s", folder, item, diagnostic))
+			continue
+		}
+		a.AddNote(fmt.Sprintf("lint %s.%s %s", folder, item, diagnostic))
+	}
+}
+
+func (a *Action) estimateEffect(dataIn iohandler.IoHandler, folder, item string) {
+	frame, err := dataIn.ReadEffect(folder, item)
+	if err != nil {
+		a.AddError(fmt.Errorf("ReadEffect %s.%s: %v", folder, item, err))
+		return
+	}
+	report, err := a.Power.Analyze(frame)
+	if err != nil {
+		a.AddError(fmt.Errorf("power %s.%s: %v", folder, item, err))
+		return
+	}
+	a.AddNote(fmt.Sprintf("power %s.%s %s", folder, item, report.String()))
+	if report.Exceeds(a.Power.Budget) {
+		a.AddError(fmt.Errorf("power %s.%s peak %.0f mA exceeds budget %.0f mA",
+			folder, item, report.Peak, a.Power.Budget))
+	}
+}
+
 func (a *Action) Verify() {
 	a.verifyInput(a.Input, true)
 	for _, output := range a.Outputs {
@@ -191,6 +281,18 @@ func (action *Action) writeDatabase(dataIn iohandler.IoHandler, dataOut iohandle
 
 	action.filter = NewFilter(action.FilterItems)
 
+	err = iohandler.CopyPalettes(dataIn, dataOut)
+	if err != nil {
+		err = fmt.Errorf("CopyPalettes %s", err)
+		return err
+	}
+
+	err = iohandler.CopyPlaylists(dataIn, dataOut)
+	if err != nil {
+		err = fmt.Errorf("CopyPlaylists %s", err)
+		return err
+	}
+
 	for _, folder := range folders {
 
 		if action.filter.IsSelected(folder) {
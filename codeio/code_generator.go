@@ -1,6 +1,7 @@
 package codeio
 
 import (
+	"gglow/glow"
 	"gglow/iohandler"
 	"os"
 	"strings"
@@ -81,10 +82,10 @@ const templSource = `
 // CAUTION GENERATED FILE
 #include "catalog.h"
 namespace glow {
-const char *catalog_names[FRAME_COUNT] = {
-{{range .}}{{printf "\"%s\",\n" .Title}}{{end}}};
+{{range .Expressions}}{{.}}{{end}}const char *catalog_names[FRAME_COUNT] = {
+{{range .Items}}{{printf "\"%s\",\n" .Title}}{{end}}};
 Frame catalog[FRAME_COUNT]={
-{{range .}}{{.Frame.MakeCode }}{{end}}};
+{{range .Items}}{{.Frame.MakeCode }}{{end}}};
 Frame &from_catalog(CATALOG_INDEX index){return catalog[index%FRAME_COUNT];}
 const char *catalog_name(CATALOG_INDEX index){return catalog_names[index%FRAME_COUNT];}
 } // namespace glow
@@ -93,7 +94,10 @@ const char *catalog_name(CATALOG_INDEX index){return catalog_names[index%FRAME_C
 func (sg *SourceGenerator) Write(folders []*iohandler.EffectItems) (err error) {
 	t := template.Must(template.New("source").Parse(templSource))
 	var gen_list = sg.makeList(folders)
-	err = t.Execute(sg.CodeGenerator.file, gen_list)
+	err = t.Execute(sg.CodeGenerator.file, struct {
+		Items       []iohandler.EffectItem
+		Expressions []string
+	}{gen_list, makeExpressions(gen_list)})
 	return
 }
 
@@ -101,15 +105,18 @@ var _ iohandler.Generator = (*EffectGenerator)(nil)
 
 type EffectGenerator struct {
 	CodeGenerator
+	wiring glow.Wiring
 }
 
-func NewEffectGenerator() *EffectGenerator {
-	eg := &EffectGenerator{}
+// NewEffectGenerator makes the effects that play the catalog on
+// lights connected with wiring.
+func NewEffectGenerator(wiring glow.Wiring) *EffectGenerator {
+	eg := &EffectGenerator{wiring: wiring}
 	return eg
 }
 
 const templEffect = `
-{{range .}}
+{{range .Items}}
 - addressable_lambda: 
     name: "{{.Title}}"
     update_interval: 16ms
@@ -117,6 +124,7 @@ const templEffect = `
       #include "glow/catalog.h"
       static glow::Frame frame(glow::from_catalog(glow::{{.Constant}}));
       if (initial_run) {
+        frame.set_wiring({{$.Wiring}});
         frame.setup(it.size(), 4, {{.Frame.Interval}});
       }
       if (frame.is_ready()) {
@@ -128,7 +136,239 @@ const templEffect = `
 func (eg *EffectGenerator) Write(folders []*iohandler.EffectItems) (err error) {
 	t := template.Must(template.New("effect").Parse(templEffect))
 	var gen_list = eg.makeList(folders)
-	err = t.Execute(eg.CodeGenerator.file, gen_list)
+	err = t.Execute(eg.CodeGenerator.file, struct {
+		Items  []iohandler.EffectItem
+		Wiring uint16
+	}{gen_list, uint16(eg.wiring)})
+	return
+}
+
+var _ iohandler.Generator = (*PixelMapGenerator)(nil)
+
+type PixelMapGenerator struct {
+	CodeGenerator
+	pixelMap *glow.PixelMap
+}
+
+func NewPixelMapGenerator(pm *glow.PixelMap) *PixelMapGenerator {
+	pg := &PixelMapGenerator{pixelMap: pm}
+	return pg
+}
+
+const templPixelMap = `
+// CAUTION GENERATED FILE
+#pragma once
+#include "PixelMap.h"
+namespace glow {
+// {{.Name}}: {{.Count}} lights on a {{.Columns}}x{{.Rows}} grid
+const PixelMap pixel_map{{.MakeCode}};
+} // namespace glow
+`
+
+func (pg *PixelMapGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	t := template.Must(template.New("pixel_map").Parse(templPixelMap))
+	err = t.Execute(pg.CodeGenerator.file, pg.pixelMap)
+	return
+}
+
+var _ iohandler.Generator = (*OutputGenerator)(nil)
+
+type OutputGenerator struct {
+	CodeGenerator
+	output *glow.Output
+}
+
+func NewOutputGenerator(out *glow.Output) *OutputGenerator {
+	og := &OutputGenerator{output: out}
+	return og
+}
+
+const templOutput = `
+// CAUTION GENERATED FILE
+#pragma once
+#include "Output.h"
+namespace glow {
+// gamma {{.Gamma}} white point {{.WhitePoint}} temperature {{.Temperature}}K brightness {{.Brightness}}%
+const Output output{{.MakeCode}};
+} // namespace glow
+`
+
+func (og *OutputGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	og.output.Setup()
+	t := template.Must(template.New("output").Parse(templOutput))
+	err = t.Execute(og.CodeGenerator.file, og.output)
+	return
+}
+
+var _ iohandler.Generator = (*WhiteGenerator)(nil)
+
+type WhiteGenerator struct {
+	CodeGenerator
+	white *glow.White
+}
+
+func NewWhiteGenerator(white *glow.White) *WhiteGenerator {
+	wg := &WhiteGenerator{white: white}
+	return wg
+}
+
+const templWhite = `
+// CAUTION GENERATED FILE
+#pragma once
+#include "White.h"
+namespace glow {
+// format {{.Format}} mode {{.Mode}} white {{.Temperature}}K warm white {{.WarmTemperature}}K
+const White white{{.MakeCode}};
+} // namespace glow
+`
+
+func (wg *WhiteGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	wg.white.Setup()
+	t := template.Must(template.New("white").Parse(templWhite))
+	err = t.Execute(wg.CodeGenerator.file, wg.white)
+	return
+}
+
+// PlaylistItem is a playlist ready to generate, its entries
+// initializers of the firmware's PlaylistEntry.
+type PlaylistItem struct {
+	Title    string
+	Constant string
+	Entries  string
+}
+
+// MakePlaylistItems keeps the entries of each playlist whose effect is
+// in the catalog and drops the playlists left without any.
+func MakePlaylistItems(playlists []*glow.Playlist, folders []*iohandler.EffectItems) (items []*PlaylistItem) {
+	catalog := make(map[string]bool)
+	for _, folder := range folders {
+		for _, item := range folder.List {
+			catalog[MakeConstant(folder.Title, item.Title)] = true
+		}
+	}
+	for _, playlist := range playlists {
+		playlist.Validate()
+		entries := make([]string, 0, len(playlist.Entries))
+		for i := range playlist.Entries {
+			entry := &playlist.Entries[i]
+			constant := MakeConstant(entry.Folder, entry.Title)
+			if catalog[constant] {
+				entries = append(entries, entry.MakeCode(constant))
+			}
+		}
+		if len(entries) > 0 {
+			items = append(items, &PlaylistItem{
+				Title:    playlist.Title,
+				Constant: MakeConstant("playlist", playlist.Title),
+				Entries:  strings.Join(entries, ","),
+			})
+		}
+	}
+	return
+}
+
+var _ iohandler.Generator = (*PlaylistHeaderGenerator)(nil)
+
+type PlaylistHeaderGenerator struct {
+	CodeGenerator
+	items []*PlaylistItem
+}
+
+func NewPlaylistHeaderGenerator(items []*PlaylistItem) *PlaylistHeaderGenerator {
+	pg := &PlaylistHeaderGenerator{items: items}
+	return pg
+}
+
+const templPlaylistHeader = `
+// CAUTION GENERATED FILE
+#pragma once
+#include "Playlist.h"
+#include "catalog.h"
+namespace glow {
+enum PLAYLIST_INDEX : uint8_t {
+{{range .}}{{printf "%s,\n" .Constant}}{{end}}{{printf "PLAYLIST_COUNT"}}};
+extern const char *playlist_names[PLAYLIST_COUNT];
+extern Playlist playlists[PLAYLIST_COUNT];
+extern Playlist &from_playlists(PLAYLIST_INDEX index);
+} // namespace glow
+`
+
+func (pg *PlaylistHeaderGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	t := template.Must(template.New("playlist_header").Parse(templPlaylistHeader))
+	err = t.Execute(pg.CodeGenerator.file, pg.items)
+	return
+}
+
+var _ iohandler.Generator = (*PlaylistSourceGenerator)(nil)
+
+type PlaylistSourceGenerator struct {
+	CodeGenerator
+	items []*PlaylistItem
+}
+
+func NewPlaylistSourceGenerator(items []*PlaylistItem) *PlaylistSourceGenerator {
+	pg := &PlaylistSourceGenerator{items: items}
+	return pg
+}
+
+const templPlaylistSource = `
+// CAUTION GENERATED FILE
+#include "playlists.h"
+namespace glow {
+const char *playlist_names[PLAYLIST_COUNT] = {
+{{range .}}{{printf "%q,\n" .Title}}{{end}}};
+Playlist playlists[PLAYLIST_COUNT]={
+{{range .}}Playlist({{"{"}}{{.Entries}}{{"}"}}),
+{{end}}};
+Playlist &from_playlists(PLAYLIST_INDEX index){return playlists[index%PLAYLIST_COUNT];}
+} // namespace glow
+`
+
+func (pg *PlaylistSourceGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	t := template.Must(template.New("playlist_source").Parse(templPlaylistSource))
+	err = t.Execute(pg.CodeGenerator.file, pg.items)
+	return
+}
+
+var _ iohandler.Generator = (*PlaylistEffectGenerator)(nil)
+
+type PlaylistEffectGenerator struct {
+	CodeGenerator
+	items  []*PlaylistItem
+	wiring glow.Wiring
+}
+
+// NewPlaylistEffectGenerator makes the effects that play the
+// playlists on lights connected with wiring.
+func NewPlaylistEffectGenerator(items []*PlaylistItem, wiring glow.Wiring) *PlaylistEffectGenerator {
+	pg := &PlaylistEffectGenerator{items: items, wiring: wiring}
+	return pg
+}
+
+const templPlaylistEffect = `
+{{range .Items}}
+- addressable_lambda: 
+    name: "{{.Title}}"
+    update_interval: 16ms
+    lambda: |-
+      #include "glow/playlists.h"
+      static glow::Playlist playlist(glow::from_playlists(glow::{{.Constant}}));
+      if (initial_run) {
+        playlist.set_wiring({{$.Wiring}});
+        playlist.setup(it.size(), 4, glow::catalog, glow::FRAME_COUNT);
+      }
+      if (playlist.is_ready()) {
+        playlist.spin(it);
+      }
+{{end}}
+`
+
+func (pg *PlaylistEffectGenerator) Write(folders []*iohandler.EffectItems) (err error) {
+	t := template.Must(template.New("playlist_effect").Parse(templPlaylistEffect))
+	err = t.Execute(pg.CodeGenerator.file, struct {
+		Items  []*PlaylistItem
+		Wiring uint16
+	}{pg.items, uint16(pg.wiring)})
 	return
 }
 
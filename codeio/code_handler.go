@@ -1,6 +1,7 @@
 package codeio
 
 import (
+	"fmt"
 	"gglow/glow"
 	"gglow/iohandler"
 	"io/fs"
@@ -9,6 +10,7 @@ import (
 )
 
 var _ iohandler.OutHandler = (*CodeHandler)(nil)
+var _ iohandler.PlaylistHandler = (*CodeHandler)(nil)
 
 var emptyList []string
 
@@ -17,6 +19,11 @@ type CodeHandler struct {
 	folders        []*iohandler.EffectItems
 	currentFolder  *iohandler.EffectItems
 	currentEffects []*iohandler.EffectItem
+	pixelMap       *glow.PixelMap
+	output         *glow.Output
+	white          *glow.White
+	wiring         glow.Wiring
+	playlists      []*glow.Playlist
 }
 
 func NewCodeHandler(path string) (*CodeHandler, error) {
@@ -28,6 +35,66 @@ func NewCodeHandler(path string) (*CodeHandler, error) {
 	return ch, nil
 }
 
+// LoadPixelMap adds the lookup table of the pixel map at path
+// to the generated code.
+func (ch *CodeHandler) LoadPixelMap(path string) (err error) {
+	ch.pixelMap, err = glow.LoadPixelMap(path)
+	return
+}
+
+// SetOutput adds the lookup tables of the output color correction
+// to the generated code.
+func (ch *CodeHandler) SetOutput(out *glow.Output) {
+	ch.output = out
+}
+
+// SetWhite adds the white channel extraction for RGBW lights
+// to the generated code.
+func (ch *CodeHandler) SetWhite(white *glow.White) {
+	ch.white = white
+}
+
+// SetWiring has the generated effects play on lights connected
+// with wiring.
+func (ch *CodeHandler) SetWiring(wiring glow.Wiring) {
+	ch.wiring = wiring
+}
+
+// ListPlaylists, ReadPlaylist, WritePlaylist and RemovePlaylist keep
+// the playlists exported as sequences of the catalog effects.
+func (ch *CodeHandler) ListPlaylists() (ls []string, err error) {
+	ls = make([]string, 0)
+	for _, playlist := range ch.playlists {
+		ls = append(ls, playlist.Title)
+	}
+	return
+}
+
+func (ch *CodeHandler) ReadPlaylist(title string) (*glow.Playlist, error) {
+	for _, playlist := range ch.playlists {
+		if playlist.Title == title {
+			return playlist, nil
+		}
+	}
+	return nil, fmt.Errorf("playlist %s not found", title)
+}
+
+func (ch *CodeHandler) WritePlaylist(playlist *glow.Playlist) error {
+	ch.RemovePlaylist(playlist.Title)
+	ch.playlists = append(ch.playlists, playlist)
+	return nil
+}
+
+func (ch *CodeHandler) RemovePlaylist(title string) error {
+	for i, playlist := range ch.playlists {
+		if playlist.Title == title {
+			ch.playlists = append(ch.playlists[:i], ch.playlists[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 func (ch *CodeHandler) Create(path string) (err error) {
 	var info fs.FileInfo
 	info, err = os.Stat(path)
@@ -77,7 +144,26 @@ func (ch *CodeHandler) process() (err error) {
 		err = generate(NewSourceGenerator(), "catalog.cpp")
 	}
 	if err == nil {
-		err = generate(NewEffectGenerator(), "catalog_effects.yml")
+		err = generate(NewEffectGenerator(ch.wiring), "catalog_effects.yml")
+	}
+	if err == nil && ch.pixelMap != nil {
+		err = generate(NewPixelMapGenerator(ch.pixelMap), "pixel_map.h")
+	}
+	if err == nil && ch.output != nil {
+		err = generate(NewOutputGenerator(ch.output), "output.h")
+	}
+	if err == nil && ch.white != nil {
+		err = generate(NewWhiteGenerator(ch.white), "white.h")
+	}
+	items := MakePlaylistItems(ch.playlists, ch.folders)
+	if err == nil && len(items) > 0 {
+		err = generate(NewPlaylistHeaderGenerator(items), "playlists.h")
+		if err == nil {
+			err = generate(NewPlaylistSourceGenerator(items), "playlists.cpp")
+		}
+		if err == nil {
+			err = generate(NewPlaylistEffectGenerator(items, ch.wiring), "playlist_effects.yml")
+		}
 	}
 	return
 }
@@ -0,0 +1,319 @@
+package codeio
+
+import (
+	"bufio"
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+	"image/color"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Conformance spins effects in the generated C++ engine, compiled
+// for the host, and compares every light of every spin with the Go
+// engine rendering in firmware parity.
+type Conformance struct {
+	// Generated is the folder of the C++ engine sources.
+	Generated string
+	Compiler  string
+	Length    uint16
+	Rows      uint16
+	Spins     int
+	// Playlists are spun after the effects, through their transitions.
+	Playlists []*glow.Playlist
+}
+
+// Divergence is the first light of an effect where the engines differ.
+type Divergence struct {
+	Effect string
+	Spin   int
+	Light  uint16
+	Go     color.NRGBA
+	Cpp    color.NRGBA
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("%s spin %d light %d: go (%d,%d,%d) c++ (%d,%d,%d)",
+		d.Effect, d.Spin, d.Light,
+		d.Go.R, d.Go.G, d.Go.B, d.Cpp.R, d.Cpp.G, d.Cpp.B)
+}
+
+func NewConformance(generated string) *Conformance {
+	return &Conformance{
+		Generated: generated,
+		Compiler:  "g++",
+		Length:    64,
+		Rows:      4,
+		Spins:     32,
+	}
+}
+
+const templDriver = `
+// CAUTION GENERATED FILE
+#include <cstdio>
+#include "catalog.h"
+{{if .Playlists}}#include "playlists.h"
+{{end}}struct Light {
+  glow::Color lights[{{.Length}}];
+  glow::Color spare;
+  glow::Color &get(uint16_t index) { return (index < {{.Length}}) ? lights[index] : spare; }
+  void update() {}
+};
+int main() {
+  for (int effect = 0; effect < glow::FRAME_COUNT; effect++) {
+    Light light;
+    glow::Frame frame(glow::from_catalog(static_cast<glow::CATALOG_INDEX>(effect)));
+    frame.setup({{.Length}}, {{.Rows}}, frame.get_interval());
+    for (int spin = 0; spin < {{.Spins}}; spin++) {
+      frame.spin(light);
+      for (uint16_t i = 0; i < {{.Length}}; i++) {
+        printf("%u %u %u ", light.lights[i].red, light.lights[i].green, light.lights[i].blue);
+      }
+      printf("\n");
+    }
+  }
+{{if .Playlists}}  for (int index = 0; index < glow::PLAYLIST_COUNT; index++) {
+    Light light;
+    glow::Playlist playlist(glow::playlists[index]);
+    playlist.setup({{.Length}}, {{.Rows}}, glow::catalog, glow::FRAME_COUNT);
+    for (int spin = 0; spin < {{.Spins}}; spin++) {
+      playlist.spin(light);
+      for (uint16_t i = 0; i < {{.Length}}; i++) {
+        printf("%u %u %u ", light.lights[i].red, light.lights[i].green, light.lights[i].blue);
+      }
+      printf("\n");
+    }
+  }
+{{end}}  return 0;
+}
+`
+
+// Run returns the first divergence of each effect that differs.
+func (c *Conformance) Run(folders []*iohandler.EffectItems) (divergences []*Divergence, err error) {
+	folder, err := os.MkdirTemp("", "conformance")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(folder)
+
+	folders, err = c.setup(folders)
+	if err != nil {
+		return
+	}
+	driver, err := c.build(folder, folders)
+	if err != nil {
+		return
+	}
+	output, err := exec.Command(driver).Output()
+	if err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for _, folder := range folders {
+		for _, item := range folder.List {
+			title := MakeTitle(folder.Title, item.Title)
+			var divergence *Divergence
+			divergence, err = c.compare(title, item.Frame, scanner)
+			if err != nil {
+				return
+			}
+			if divergence != nil {
+				divergences = append(divergences, divergence)
+			}
+		}
+	}
+	for _, playlist := range c.Playlists {
+		var divergence *Divergence
+		divergence, err = c.comparePlaylist(playlist, folders, scanner)
+		if err != nil {
+			return
+		}
+		if divergence != nil {
+			divergences = append(divergences, divergence)
+		}
+	}
+	return
+}
+
+// setup copies the effects set up for the strip, so that the code
+// carries what setup renders such as text bitmaps.
+func (c *Conformance) setup(folders []*iohandler.EffectItems) (copies []*iohandler.EffectItems, err error) {
+	for _, folder := range folders {
+		list := iohandler.NewFolderList(folder.Title, nil)
+		for _, item := range folder.List {
+			var frame *glow.Frame
+			frame, err = glow.FrameDeepCopy(item.Frame)
+			if err != nil {
+				return
+			}
+			frame.Setup(c.Length, c.Rows)
+			list.AddItem(iohandler.NewEffectItem(item.Title, frame))
+		}
+		copies = append(copies, list)
+	}
+	return
+}
+
+// build generates the catalog and driver in folder and compiles them
+// with the engine sources.
+func (c *Conformance) build(folder string, folders []*iohandler.EffectItems) (driver string, err error) {
+	ch, err := NewCodeHandler(folder)
+	if err != nil {
+		return
+	}
+	ch.folders = folders
+	for _, playlist := range c.Playlists {
+		ch.WritePlaylist(playlist)
+	}
+	err = ch.process()
+	if err != nil {
+		return
+	}
+
+	source := filepath.Join(folder, "driver.cpp")
+	file, err := os.Create(source)
+	if err != nil {
+		return
+	}
+	t := template.Must(template.New("driver").Parse(templDriver))
+	err = t.Execute(file, c)
+	file.Close()
+	if err != nil {
+		return
+	}
+
+	engine, err := filepath.Glob(filepath.Join(c.Generated, "*.cpp"))
+	if err != nil {
+		return
+	}
+	driver = filepath.Join(folder, "driver")
+	args := []string{"-std=c++17", "-O1", "-I", folder, "-I", c.Generated,
+		"-o", driver, source, filepath.Join(folder, "catalog.cpp")}
+	if len(c.Playlists) > 0 {
+		args = append(args, filepath.Join(folder, "playlists.cpp"))
+	}
+	for _, path := range engine {
+		if filepath.Base(path) != "catalog.cpp" {
+			args = append(args, path)
+		}
+	}
+	message, err := exec.Command(c.Compiler, args...).CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("%s: %v\n%s", c.Compiler, err, message)
+	}
+	return
+}
+
+// compare spins a copy of frame and checks it against the driver's
+// lines for the effect.
+func (c *Conformance) compare(title string, source *glow.Frame,
+	scanner *bufio.Scanner) (divergence *Divergence, err error) {
+	frame, err := glow.FrameDeepCopy(source)
+	if err != nil {
+		return
+	}
+	frame.Setup(c.Length, c.Rows)
+	frame.SetParity(true)
+	light := newBufferLight(c.Length)
+
+	for spin := 0; spin < c.Spins; spin++ {
+		frame.Spin(light)
+		err = c.check(title, spin, light, scanner, &divergence)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// comparePlaylist plays playlist with copies of its effects and checks
+// it against the driver's lines for the playlist.
+func (c *Conformance) comparePlaylist(playlist *glow.Playlist, folders []*iohandler.EffectItems,
+	scanner *bufio.Scanner) (divergence *Divergence, err error) {
+	lookup := func(folderTitle, title string) (*glow.Frame, error) {
+		for _, folder := range folders {
+			for _, item := range folder.List {
+				if folder.Title == folderTitle && item.Title == title {
+					return glow.FrameDeepCopy(item.Frame)
+				}
+			}
+		}
+		return nil, fmt.Errorf("not in the catalog")
+	}
+	seq, err := glow.NewSequence(playlist, lookup)
+	if err != nil {
+		return
+	}
+	err = seq.Setup(c.Length, c.Rows)
+	if err != nil {
+		return
+	}
+	seq.SetParity(true)
+	light := newBufferLight(c.Length)
+
+	title := MakeConstant("playlist", playlist.Title)
+	for spin := 0; spin < c.Spins; spin++ {
+		seq.Spin(light)
+		err = c.check(title, spin, light, scanner, &divergence)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// check reads the driver's line for a spin and keeps the first light
+// that differs from light.
+func (c *Conformance) check(title string, spin int, light *bufferLight,
+	scanner *bufio.Scanner, divergence **Divergence) error {
+	if !scanner.Scan() {
+		return fmt.Errorf("%s spin %d: driver output ended", title, spin)
+	}
+	if *divergence != nil {
+		return nil
+	}
+	fields := strings.Fields(scanner.Text())
+	if len(fields) != int(c.Length)*3 {
+		return fmt.Errorf("%s spin %d: driver wrote %d values", title, spin, len(fields))
+	}
+	for i := uint16(0); i < c.Length; i++ {
+		var cpp color.NRGBA
+		fmt.Sscan(strings.Join(fields[i*3:i*3+3], " "), &cpp.R, &cpp.G, &cpp.B)
+		got := light.Get(i)
+		if got.R != cpp.R || got.G != cpp.G || got.B != cpp.B {
+			*divergence = &Divergence{Effect: title, Spin: spin, Light: i, Go: got, Cpp: cpp}
+			break
+		}
+	}
+	return nil
+}
+
+// bufferLight keeps the lights of a spin to compare.
+type bufferLight struct {
+	lights []color.NRGBA
+}
+
+func newBufferLight(length uint16) *bufferLight {
+	return &bufferLight{lights: make([]color.NRGBA, length)}
+}
+
+func (bl *bufferLight) Get(i uint16) color.NRGBA {
+	if int(i) < len(bl.lights) {
+		return bl.lights[i]
+	}
+	return color.NRGBA{}
+}
+
+func (bl *bufferLight) Set(i uint16, c color.NRGBA) {
+	if int(i) < len(bl.lights) {
+		bl.lights[i] = c
+	}
+}
+
+func (bl *bufferLight) Refresh() {}
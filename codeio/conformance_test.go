@@ -0,0 +1,208 @@
+package codeio
+
+import (
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadCabinet(t *testing.T, root string) (folders []*iohandler.EffectItems) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	var serializer iohandler.JsonSerializer
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		paths, _ := filepath.Glob(filepath.Join(root, entry.Name(), "*.json"))
+		folder := iohandler.NewFolderList(entry.Name(), nil)
+		for _, path := range paths {
+			buffer, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			frame := &glow.Frame{}
+			err = serializer.Scan(buffer, frame)
+			if err != nil {
+				t.Fatalf("%s %v", path, err)
+			}
+			title := strings.TrimSuffix(filepath.Base(path), ".json")
+			folder.AddItem(iohandler.NewEffectItem(title, frame))
+		}
+		folders = append(folders, folder)
+	}
+	return
+}
+
+// features exercises the engine beyond the cabinet effects.
+func features() *iohandler.EffectItems {
+	hsv := func(hue, saturation, value float32) glow.HSV {
+		return glow.HSV{Hue: hue, Saturation: saturation, Value: value}
+	}
+	rainbow := []glow.HSV{hsv(0, 1, 1), hsv(120, 1, 1), hsv(240, 1, .8)}
+	grid := glow.Grid{Origin: glow.TopLeft, Orientation: glow.Diagonal}
+	layer := func(l glow.Layer) *glow.Layer {
+		if len(l.Chroma.Colors) == 0 {
+			l.Chroma.Colors = rainbow
+		}
+		return &l
+	}
+	folder := iohandler.NewFolderList("features", nil)
+	add := func(title string, layers ...*glow.Layer) {
+		folder.AddItem(iohandler.NewEffectItem(title,
+			&glow.Frame{Interval: 48, Layers: layers}))
+	}
+
+	for mode := glow.ScanForward; mode < glow.SCAN_MODE_COUNT; mode++ {
+		add(fmt.Sprintf("scan %d", mode),
+			layer(glow.Layer{HueShift: -7}),
+			layer(glow.Layer{Scan: 5, Begin: 10, End: 90, ScanMode: mode, Speed: 3,
+				Blend: glow.BlendScreen, Opacity: glow.Percent(60)}))
+	}
+	for interpolation := glow.InterpolateHSV; interpolation < glow.INTERPOLATION_COUNT; interpolation++ {
+		add(fmt.Sprintf("interpolation %d", interpolation),
+			layer(glow.Layer{HueShift: 11, Grid: grid, Chroma: glow.Chroma{
+				Colors: rainbow, Interpolation: interpolation,
+				Positions: []uint16{glow.PositionAuto, 20, glow.PositionAuto}}}))
+	}
+	for blend := glow.BlendReplace; blend < glow.BLEND_COUNT; blend++ {
+		add(fmt.Sprintf("blend %d", blend),
+			layer(glow.Layer{}),
+			layer(glow.Layer{Blend: blend, Opacity: glow.Percent(70), Brightness: glow.Percent(80), HueShift: 5,
+				Envelope: glow.Envelope{Attack: 3, Sustain: 2, Decay: 4},
+				Chroma:   glow.Chroma{Colors: []glow.HSV{hsv(300, .5, 1), hsv(60, 1, .5)}}}))
+	}
+	for kind := glow.NoiseFire; kind < glow.NOISE_COUNT; kind++ {
+		add(fmt.Sprintf("noise %d", kind),
+			layer(glow.Layer{Noise: glow.Noise{Kind: kind, Seed: 7}}))
+	}
+	for kind := glow.ParticleTwinkle; kind < glow.PARTICLE_COUNT; kind++ {
+		add(fmt.Sprintf("particles %d", kind),
+			layer(glow.Layer{Speed: 2, Blend: glow.BlendAdd, Particles: glow.Particles{
+				Kind: kind, Spawn: 150, Fade: glow.FadeQuadratic}}))
+	}
+	add("text", layer(glow.Layer{Text: glow.Text{Message: "Hi", Gradient: true}}))
+	for waveform := glow.WaveSine; waveform < glow.WAVEFORM_COUNT; waveform++ {
+		modulate := func(target glow.ModTarget, rate uint32, depth int16, phase, stop uint16) glow.Modulator {
+			return glow.Modulator{Target: target, Waveform: waveform, Rate: rate,
+				Depth: depth, Phase: phase, Stop: stop}
+		}
+		add(fmt.Sprintf("modulators %d", waveform),
+			layer(glow.Layer{HueShift: 3, Modulators: []glow.Modulator{
+				modulate(glow.ModHue, 700, 90, 0, 0),
+				modulate(glow.ModValue, 500, -40, 90, 2),
+				modulate(glow.ModSaturation, 900, 60, 45, 1),
+				modulate(glow.ModHue, 400, 30, 180, 0),
+			}}),
+			layer(glow.Layer{Scan: 4, Begin: 20, End: 80, ScanMode: glow.ScanPingPong,
+				Blend: glow.BlendScreen, Rate: 60, Modulators: []glow.Modulator{
+					modulate(glow.ModBegin, 800, 15, 0, 0),
+					modulate(glow.ModEnd, 600, -20, 30, 0),
+					modulate(glow.ModScan, 900, 3, 0, 0),
+					modulate(glow.ModOpacity, 500, 50, 270, 0),
+					modulate(glow.ModRate, 700, 40, 0, 0),
+				}}))
+	}
+	for mask := glow.MaskLuminance; mask < glow.MASK_MODE_COUNT; mask++ {
+		add(fmt.Sprintf("mask %d", mask),
+			layer(glow.Layer{Chroma: glow.Chroma{Colors: []glow.HSV{hsv(200, 1, .3)}}}),
+			layer(glow.Layer{Scan: 6, ScanMode: glow.ScanPingPong, Speed: 2, Mask: mask,
+				Opacity: glow.Percent(80), Chroma: glow.Chroma{Colors: []glow.HSV{hsv(60, .5, 1), hsv(0, 1, .5)}}}),
+			layer(glow.Layer{HueShift: 9, Grid: grid}),
+			layer(glow.Layer{Blend: glow.BlendAdd, Noise: glow.Noise{Kind: glow.NoiseFire, Seed: 3}}))
+	}
+	for orientation := glow.Centred; orientation <= glow.Concentric; orientation++ {
+		add(fmt.Sprintf("orientation %d", orientation),
+			layer(glow.Layer{HueShift: 4, Grid: glow.Grid{Origin: glow.BottomRight, Orientation: orientation}}),
+			layer(glow.Layer{Scan: 3, Begin: 10, End: 70, Blend: glow.BlendScreen,
+				Grid: glow.Grid{Orientation: orientation}}))
+	}
+	add("clear",
+		layer(glow.Layer{HueShift: 3}),
+		layer(glow.Layer{Opacity: glow.Percent(0), Chroma: glow.Chroma{Colors: []glow.HSV{hsv(0, 0, 1)}}}),
+		layer(glow.Layer{Scan: 4, Opacity: glow.Percent(0), Blend: glow.BlendScreen,
+			Modulators: []glow.Modulator{{Target: glow.ModOpacity, Waveform: glow.WaveTriangle,
+				Rate: 600, Depth: 70}}}))
+	add("dark",
+		layer(glow.Layer{HueShift: 3}),
+		layer(glow.Layer{Scan: 4, Brightness: glow.Percent(0), Chroma: glow.Chroma{Colors: []glow.HSV{hsv(0, 0, 1)}}}))
+	add("clip",
+		layer(glow.Layer{Begin: 25, End: 75, Particles: glow.Particles{
+			Kind: glow.ParticleTwinkle, Spawn: 150}}),
+		layer(glow.Layer{HueShift: -5, Clip: true}),
+		layer(glow.Layer{Scan: 3, Speed: 1, Clip: true, Blend: glow.BlendScreen}))
+	waves := "w = sin(x*0.7 + t*3) * cos(y - t)\n" +
+		"hue = w*120 + index*7 + spin*2; sat = clamp(0.6 + fract(t), 0, 1)\n" +
+		"val = mix(0.2, 1, abs(w)) * if(index % 3 == 0, 1, 0.7)"
+	add("expression",
+		layer(glow.Layer{Expression: waves}),
+		layer(glow.Layer{Scan: 4, Speed: 2, Blend: glow.BlendScreen, Grid: grid,
+			Expression: "hue = pow(sqrt(x+1), 2)*30 % 360 + x/(y-y); " +
+				"val = max(min(tan(t) / 4, 1), floor(t)/9) + ceil(-pi)/10 + (x >= 2) - (y != 0)*0.5"}),
+		layer(glow.Layer{Begin: 60, Mask: glow.MaskLuminance, Expression: waves}))
+	return folder
+}
+
+func TestConformance(t *testing.T) {
+	conformance := NewConformance("../generated")
+	if _, err := exec.LookPath(conformance.Compiler); err != nil {
+		t.Skip("no", conformance.Compiler)
+	}
+
+	folders := append(loadCabinet(t, "../cabinet/json"), features())
+	entry := func(title string, transition glow.Transition) glow.PlaylistEntry {
+		return glow.PlaylistEntry{Folder: "features", Title: title,
+			Duration: 300, Transition: transition, Fade: 250}
+	}
+	conformance.Playlists = []*glow.Playlist{
+		{Title: "transitions", Entries: []glow.PlaylistEntry{
+			entry("noise 1", glow.TransitionCut),
+			entry("scan 1", glow.TransitionCrossfade),
+			entry("blend 2", glow.TransitionWipe),
+			entry("text", glow.TransitionDissolve),
+			entry("text", glow.TransitionCrossfade),
+			entry("particles 1", glow.TransitionCrossfade),
+		}},
+	}
+	divergences, err := conformance.Run(folders)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, divergence := range divergences {
+		t.Error(divergence)
+	}
+}
+
+func TestConformanceRagged(t *testing.T) {
+	conformance := NewConformance("../generated")
+	if _, err := exec.LookPath(conformance.Compiler); err != nil {
+		t.Skip("no", conformance.Compiler)
+	}
+
+	// 66 lights on 4 rows leave two past the centred and concentric tables
+	conformance.Length = 66
+	colors := []glow.HSV{{Hue: 0, Saturation: 1, Value: 1}, {Hue: 240, Saturation: 1, Value: 1}}
+	folder := iohandler.NewFolderList("ragged", nil)
+	for orientation := glow.Centred; orientation <= glow.Concentric; orientation++ {
+		for _, origin := range []glow.Origin{glow.TopLeft, glow.BottomRight} {
+			grid := glow.Grid{Origin: origin, Orientation: orientation}
+			folder.AddItem(iohandler.NewEffectItem(fmt.Sprintf("orientation %d origin %d", orientation, origin),
+				&glow.Frame{Interval: 48, Layers: []*glow.Layer{
+					{HueShift: 4, Grid: grid, Chroma: glow.Chroma{Colors: colors}}}}))
+		}
+	}
+	divergences, err := conformance.Run([]*iohandler.EffectItems{folder})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, divergence := range divergences {
+		t.Error(divergence)
+	}
+}
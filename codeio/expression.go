@@ -0,0 +1,93 @@
+package codeio
+
+import (
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TranspileExpression writes program as a C++ ExpressionFunction
+// named for the program. Locals take a v_ prefix so they cannot meet
+// C++ names.
+func TranspileExpression(program *glow.Program) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "static void %s(const ExpressionInput &in, ExpressionOutput &out){\n",
+		program.Name())
+	for _, local := range program.Locals {
+		fmt.Fprintf(&b, "double v_%s=0;\n", local)
+	}
+	for _, statement := range program.Statements {
+		fmt.Fprintf(&b, "%s=%s;\n", transpileName(statement.Target), transpileNode(statement.Value))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func transpileName(name string) string {
+	if slices.Contains(glow.ProgramInputs, name) {
+		return "in." + name
+	}
+	if slices.Contains(glow.ProgramOutputs, name) {
+		return "out." + name
+	}
+	return "v_" + name
+}
+
+func transpileNode(node *glow.Node) string {
+	switch node.Kind {
+	case glow.NodeNumber:
+		// a literal C++ reads back as the same double
+		s := strconv.FormatFloat(node.Value, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s
+	case glow.NodeVariable:
+		return transpileName(node.Name)
+	case glow.NodeUnary:
+		return "(-" + transpileNode(node.Args[0]) + ")"
+	case glow.NodeBinary:
+		a, b := transpileNode(node.Args[0]), transpileNode(node.Args[1])
+		switch node.Name {
+		case "+", "-", "*":
+			return "(" + a + node.Name + b + ")"
+		case "/":
+			return "expr_div(" + a + "," + b + ")"
+		case "%":
+			return "expr_mod(" + a + "," + b + ")"
+		}
+		return "expr_truth(" + a + node.Name + b + ")"
+	}
+	args := make([]string, len(node.Args))
+	for i, arg := range node.Args {
+		args[i] = transpileNode(arg)
+	}
+	return "expr_" + node.Name + "(" + strings.Join(args, ",") + ")"
+}
+
+// makeExpressions transpiles each expression the listed frames use
+// once, skipping any that do not compile as their layers do.
+func makeExpressions(list []iohandler.EffectItem) []string {
+	var names []string
+	var functions []string
+	for _, item := range list {
+		if item.Frame == nil {
+			continue
+		}
+		for _, layer := range item.Frame.Layers {
+			if layer.Expression == "" {
+				continue
+			}
+			program, err := glow.CompileProgram(layer.Expression)
+			if err != nil || slices.Contains(names, program.Name()) {
+				continue
+			}
+			names = append(names, program.Name())
+			functions = append(functions, TranspileExpression(program))
+		}
+	}
+	return functions
+}
@@ -0,0 +1,34 @@
+package codeio
+
+import (
+	"gglow/glow"
+	"gglow/iohandler"
+	"strings"
+	"testing"
+)
+
+func TestTranspileExpression(t *testing.T) {
+	program, err := glow.CompileProgram("a = x / 2; hue = -a % 3 + sin(t)\nval = y < 1")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	code := TranspileExpression(program)
+	for _, want := range []string{
+		"static void " + program.Name() + "(const ExpressionInput &in, ExpressionOutput &out){",
+		"double v_a=0;",
+		"v_a=expr_div(in.x,2.0);",
+		"out.hue=(expr_mod((-v_a),3.0)+expr_sin(in.t));",
+		"out.val=expr_truth(in.y<1.0);",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("want %q in\n%s", want, code)
+		}
+	}
+
+	frame := &glow.Frame{Layers: []*glow.Layer{
+		{Expression: "hue = x"}, {Expression: "hue = x"}, {Expression: "hue = ?"}, {}}}
+	list := []iohandler.EffectItem{{Frame: frame}, {Frame: frame}}
+	if functions := makeExpressions(list); len(functions) != 1 {
+		t.Fatalf("want one function got %d", len(functions))
+	}
+}
@@ -3,6 +3,9 @@ package codeio
 import (
 	"gglow/glow"
 	"gglow/iohandler"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -107,3 +110,106 @@ func TestGenerate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPixelMapGenerate(t *testing.T) {
+	pm, err := glow.NewPixelMap("corner",
+		glow.Point{X: 0, Y: 0}, glow.Point{X: 2, Y: 0}, glow.Point{X: 0, Y: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pixel_map.h")
+	pg := NewPixelMapGenerator(pm)
+	err = pg.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pg.Write(nil)
+	pg.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "const PixelMap pixel_map{3,2,{0,2,3,}};") {
+		t.Fatalf("pixel map code missing\n%s", buf)
+	}
+}
+
+func TestOutputGenerate(t *testing.T) {
+	out := &glow.Output{Gamma: [3]float32{2, 2, 2}, Brightness: 50}
+	path := filepath.Join(t.TempDir(), "output.h")
+	og := NewOutputGenerator(out)
+	err := og.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = og.Write(nil)
+	og.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "const Output output{{0,0,0,") ||
+		strings.Count(string(buf), "128,},") != 3 {
+		t.Fatalf("output code missing\n%s", buf)
+	}
+}
+
+func TestWhiteGenerate(t *testing.T) {
+	white := &glow.White{Format: glow.FormatRGBW}
+	path := filepath.Join(t.TempDir(), "white.h")
+	wg := NewWhiteGenerator(white)
+	err := wg.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wg.Write(nil)
+	wg.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "const White white{1,0,{255,255,255},{255,255,255}};") {
+		t.Fatalf("white code missing\n%s", buf)
+	}
+}
+
+func TestWiringGenerate(t *testing.T) {
+	dir := t.TempDir()
+	ch, err := NewCodeHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetWiring(glow.Serpentine)
+	ch.CreateFolder("grid")
+	frame := &glow.Frame{Interval: 48, Layers: []*glow.Layer{glow.NewLayer()}}
+	ch.CreateEffect("grid", "rainbow", frame)
+	ch.WritePlaylist(&glow.Playlist{Title: "show",
+		Entries: []glow.PlaylistEntry{{Folder: "grid", Title: "rainbow"}}})
+	err = ch.OnExit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"catalog_effects.yml", "playlist_effects.yml"} {
+		buf, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(buf), ".set_wiring(1);") {
+			t.Fatalf("%s wiring missing\n%s", name, buf)
+		}
+	}
+}
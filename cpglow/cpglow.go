@@ -4,24 +4,48 @@ import (
 	"flag"
 	"fmt"
 	"gglow/action"
+	"gglow/glow"
+	"gglow/iohandler"
 	"os"
 )
 
 var transactionFile string
+var powerFile string
+var budget float64
+var power = glow.NewPowerAnalyzer()
 
 const (
 	transactionDefault = "transaction.yaml"
 	transactionUsage   = "transaction file"
+	powerUsage         = "accessor file of a catalog to estimate current for"
 )
 
 func init() {
 	flag.StringVar(&transactionFile, "t", transactionDefault, transactionUsage+" (short form)")
 	flag.StringVar(&transactionFile, "transaction", transactionDefault, transactionUsage)
+	flag.StringVar(&powerFile, "p", "", powerUsage+" (short form)")
+	flag.StringVar(&powerFile, "power", "", powerUsage)
+	flag.Float64Var(&budget, "budget", glow.DefaultPowerBudget, "power supply limit in mA, 0 for none")
+	flag.IntVar(&power.Columns, "columns", power.Columns, "light columns for power")
+	flag.IntVar(&power.Rows, "rows", power.Rows, "light rows for power")
+	flag.IntVar(&power.Spins, "spins", power.Spins, "spins per effect for power")
 }
 
 func main() {
 	flag.Parse()
 
+	if powerFile != "" {
+		transaction, err := powerTransaction()
+		if err != nil {
+			flag.Usage()
+			fmt.Println(powerFile, err)
+			os.Exit(1)
+		}
+		transaction.Process()
+		transaction.ShowLogs()
+		return
+	}
+
 	if transactionFile == "" {
 		flag.Usage()
 		os.Exit(1)
@@ -37,3 +61,26 @@ func main() {
 	transaction.Process()
 	transaction.ShowLogs()
 }
+
+func powerTransaction() (*action.Transaction, error) {
+	accessor, err := iohandler.LoadAccessor(powerFile)
+	if err != nil {
+		return nil, err
+	}
+	power.Budget = float32(budget)
+	power.Output = accessor.Output
+	if accessor.PixelMap != "" {
+		power.Map, err = glow.LoadPixelMap(accessor.PixelMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a := action.NewAction()
+	a.Method = "power"
+	a.Input = accessor
+	a.Power = power
+	transaction := action.NewTransaction()
+	transaction.Actions = append(transaction.Actions, a)
+	return transaction, nil
+}
@@ -27,11 +27,12 @@ type EffectIo struct {
 	layerIndex  int
 	summaryList []string
 
-	folderWatch binding.Int
-	frameWatch  binding.Int
-	layerWatch  binding.Int
-	hasChanged  binding.Bool
-	data        binding.BoolTree
+	folderWatch  binding.Int
+	frameWatch   binding.Int
+	layerWatch   binding.Int
+	paletteWatch binding.Int
+	hasChanged   binding.Bool
+	data         binding.BoolTree
 
 	isActive    bool
 	saveActions []func(*glow.Frame)
@@ -40,10 +41,11 @@ type EffectIo struct {
 func NewEffect(io iohandler.IoHandler, accessor *iohandler.Accessor, preferences fyne.Preferences) *EffectIo {
 
 	eff := &EffectIo{
-		IoHandler:   io,
-		folderWatch: binding.NewInt(),
-		frameWatch:  binding.NewInt(),
-		layerWatch:  binding.NewInt(),
+		IoHandler:    io,
+		folderWatch:  binding.NewInt(),
+		frameWatch:   binding.NewInt(),
+		layerWatch:   binding.NewInt(),
+		paletteWatch: binding.NewInt(),
 
 		hasChanged:  binding.NewBool(),
 		saveActions: make([]func(*glow.Frame), 0),
@@ -357,6 +359,9 @@ func (eff *EffectIo) AddFrameListener(listener binding.DataListener) {
 func (eff *EffectIo) AddLayerListener(listener binding.DataListener) {
 	eff.layerWatch.AddListener(listener)
 }
+func (eff *EffectIo) AddPaletteListener(listener binding.DataListener) {
+	eff.paletteWatch.AddListener(listener)
+}
 func (eff *EffectIo) AddChangeListener(listener binding.DataListener) {
 	eff.hasChanged.AddListener(listener)
 }
@@ -372,3 +377,7 @@ func (eff *EffectIo) alertFrame() {
 func (eff *EffectIo) alertLayer() {
 	Alert(eff.layerWatch)
 }
+
+func (eff *EffectIo) alertPalette() {
+	Alert(eff.paletteWatch)
+}
@@ -7,22 +7,36 @@ import (
 )
 
 type FrameFields struct {
-	Interval binding.Int
+	Interval   binding.Int
+	Brightness binding.Int
+	Opacity    binding.Int
 }
 
 func NewFrameFields() *FrameFields {
 	fld := &FrameFields{
-		Interval: binding.NewInt(),
+		Interval:   binding.NewInt(),
+		Brightness: binding.NewInt(),
+		Opacity:    binding.NewInt(),
 	}
 	return fld
 }
 
 func (fld *FrameFields) FromFrame(frame *glow.Frame) {
 	fld.Interval.Set(int(frame.Interval))
+	fld.Brightness.Set(int(frame.BrightnessPercent()))
+	fld.Opacity.Set(int(frame.OpacityPercent()))
 }
 
 func (fld *FrameFields) ToFrame(frame *glow.Frame) {
 	var i int
 	i, _ = fld.Interval.Get()
 	frame.Interval = uint32(i)
+	i, _ = fld.Brightness.Get()
+	if i != int(frame.BrightnessPercent()) {
+		frame.Brightness = glow.Percent(uint16(i))
+	}
+	i, _ = fld.Opacity.Get()
+	if i != int(frame.OpacityPercent()) {
+		frame.Opacity = glow.Percent(uint16(i))
+	}
 }
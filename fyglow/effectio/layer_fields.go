@@ -16,7 +16,36 @@ type LayerFields struct {
 	Orientation binding.Int
 	Begin       binding.Int
 	End         binding.Int
+	Blend       binding.Int
+	Opacity     binding.Int
+	Brightness  binding.Int
+	Attack      binding.Int
+	Sustain     binding.Int
+	Decay       binding.Int
+	ScanMode    binding.Int
+	Speed       binding.Int
+	Noise       binding.Int
+	NoiseSeed   binding.Int
+	NoiseScale  binding.Int
+	NoiseDrift  binding.Int
+	Particles   binding.Int
+	Spawn       binding.Int
+	Lifetime    binding.Int
+	Fade        binding.Int
+	Tail        binding.Int
+	SparkSeed   binding.Int
+	Message     binding.String
+	Direction   binding.Int
+	Gradient    binding.Bool
+	Interpolate binding.Int
+	Palette     binding.String
+	Override    binding.Bool
+	Mask        binding.Int
+	Clip        binding.Bool
+	Expression  binding.String
 	Colors      []glow.HSV
+	Positions   []uint16
+	Modulators  []glow.Modulator
 }
 
 func NewLayerFields() *LayerFields {
@@ -28,6 +57,33 @@ func NewLayerFields() *LayerFields {
 		Orientation: binding.NewInt(),
 		Begin:       binding.NewInt(),
 		End:         binding.NewInt(),
+		Blend:       binding.NewInt(),
+		Opacity:     binding.NewInt(),
+		Brightness:  binding.NewInt(),
+		Attack:      binding.NewInt(),
+		Sustain:     binding.NewInt(),
+		Decay:       binding.NewInt(),
+		ScanMode:    binding.NewInt(),
+		Speed:       binding.NewInt(),
+		Noise:       binding.NewInt(),
+		NoiseSeed:   binding.NewInt(),
+		NoiseScale:  binding.NewInt(),
+		NoiseDrift:  binding.NewInt(),
+		Particles:   binding.NewInt(),
+		Spawn:       binding.NewInt(),
+		Lifetime:    binding.NewInt(),
+		Fade:        binding.NewInt(),
+		Tail:        binding.NewInt(),
+		SparkSeed:   binding.NewInt(),
+		Message:     binding.NewString(),
+		Direction:   binding.NewInt(),
+		Gradient:    binding.NewBool(),
+		Interpolate: binding.NewInt(),
+		Palette:     binding.NewString(),
+		Override:    binding.NewBool(),
+		Mask:        binding.NewInt(),
+		Clip:        binding.NewBool(),
+		Expression:  binding.NewString(),
 	}
 	return fld
 }
@@ -40,8 +96,39 @@ func (fld *LayerFields) FromLayer(layer *glow.Layer) {
 	fld.Orientation.Set(int(layer.Grid.Orientation))
 	fld.Begin.Set(int(layer.Begin))
 	fld.End.Set(int(layer.End))
+	fld.Blend.Set(int(layer.Blend))
+	fld.Opacity.Set(int(layer.OpacityPercent()))
+	fld.Brightness.Set(int(layer.BrightnessPercent()))
+	fld.Attack.Set(int(layer.Envelope.Attack))
+	fld.Sustain.Set(int(layer.Envelope.Sustain))
+	fld.Decay.Set(int(layer.Envelope.Decay))
+	fld.ScanMode.Set(int(layer.ScanMode))
+	fld.Speed.Set(int(layer.Speed))
+	fld.Noise.Set(int(layer.Noise.Kind))
+	fld.NoiseSeed.Set(int(layer.Noise.Seed))
+	fld.NoiseScale.Set(int(layer.Noise.Scale))
+	fld.NoiseDrift.Set(int(layer.Noise.Drift))
+	fld.Particles.Set(int(layer.Particles.Kind))
+	fld.Spawn.Set(int(layer.Particles.Spawn))
+	fld.Lifetime.Set(int(layer.Particles.Lifetime))
+	fld.Fade.Set(int(layer.Particles.Fade))
+	fld.Tail.Set(int(layer.Particles.Tail))
+	fld.SparkSeed.Set(int(layer.Particles.Seed))
+	fld.Message.Set(layer.Text.Message)
+	fld.Direction.Set(int(layer.Text.Direction))
+	fld.Gradient.Set(layer.Text.Gradient)
+	fld.Interpolate.Set(int(layer.Chroma.Interpolation))
+	fld.Palette.Set(layer.Chroma.Palette)
+	fld.Override.Set(layer.Chroma.Override)
+	fld.Mask.Set(int(layer.Mask))
+	fld.Clip.Set(layer.Clip)
+	fld.Expression.Set(layer.Expression)
 	fld.Colors = make([]glow.HSV, len(layer.Chroma.Colors))
 	copy(fld.Colors, layer.Chroma.Colors)
+	fld.Positions = make([]uint16, len(layer.Chroma.Positions))
+	copy(fld.Positions, layer.Chroma.Positions)
+	fld.Modulators = make([]glow.Modulator, len(layer.Modulators))
+	copy(fld.Modulators, layer.Modulators)
 }
 
 func (fld *LayerFields) ToLayer(layer *glow.Layer) {
@@ -67,8 +154,95 @@ func (fld *LayerFields) ToLayer(layer *glow.Layer) {
 	i, _ = fld.End.Get()
 	layer.End = uint16(i)
 
+	i, _ = fld.Blend.Get()
+	layer.Blend = glow.BlendMode(i)
+
+	i, _ = fld.Opacity.Get()
+	if i != int(layer.OpacityPercent()) {
+		layer.Opacity = glow.Percent(uint16(i))
+	}
+
+	i, _ = fld.Brightness.Get()
+	if i != int(layer.BrightnessPercent()) {
+		layer.Brightness = glow.Percent(uint16(i))
+	}
+
+	i, _ = fld.Attack.Get()
+	layer.Envelope.Attack = uint16(i)
+
+	i, _ = fld.Sustain.Get()
+	layer.Envelope.Sustain = uint16(i)
+
+	i, _ = fld.Decay.Get()
+	layer.Envelope.Decay = uint16(i)
+
+	i, _ = fld.ScanMode.Get()
+	layer.ScanMode = glow.ScanMode(i)
+
+	i, _ = fld.Speed.Get()
+	layer.Speed = uint16(i)
+
+	i, _ = fld.Noise.Get()
+	layer.Noise.Kind = glow.NoiseKind(i)
+
+	i, _ = fld.NoiseSeed.Get()
+	layer.Noise.Seed = uint32(i)
+
+	i, _ = fld.NoiseScale.Get()
+	layer.Noise.Scale = uint16(i)
+
+	i, _ = fld.NoiseDrift.Get()
+	layer.Noise.Drift = uint16(i)
+
+	i, _ = fld.Particles.Get()
+	layer.Particles.Kind = glow.ParticleKind(i)
+
+	i, _ = fld.Spawn.Get()
+	layer.Particles.Spawn = uint16(i)
+
+	i, _ = fld.Lifetime.Get()
+	layer.Particles.Lifetime = uint16(i)
+
+	i, _ = fld.Fade.Get()
+	layer.Particles.Fade = glow.FadeCurve(i)
+
+	i, _ = fld.Tail.Get()
+	layer.Particles.Tail = uint16(i)
+
+	i, _ = fld.SparkSeed.Get()
+	layer.Particles.Seed = uint32(i)
+
+	layer.Text.Message, _ = fld.Message.Get()
+
+	i, _ = fld.Direction.Get()
+	layer.Text.Direction = glow.TextDirection(i)
+
+	layer.Text.Gradient, _ = fld.Gradient.Get()
+
+	i, _ = fld.Interpolate.Get()
+	layer.Chroma.Interpolation = glow.Interpolation(i)
+
+	layer.Chroma.Palette, _ = fld.Palette.Get()
+	layer.Chroma.Override, _ = fld.Override.Get()
+
+	i, _ = fld.Mask.Get()
+	layer.Mask = glow.MaskMode(i)
+
+	layer.Clip, _ = fld.Clip.Get()
+	layer.Expression, _ = fld.Expression.Get()
+
 	layer.Chroma.Colors = make([]glow.HSV, len(fld.Colors))
 	copy(layer.Chroma.Colors, fld.Colors)
+	layer.Chroma.Positions = nil
+	if len(fld.Positions) > 0 {
+		layer.Chroma.Positions = make([]uint16, len(fld.Positions))
+		copy(layer.Chroma.Positions, fld.Positions)
+	}
+	layer.Modulators = nil
+	if len(fld.Modulators) > 0 {
+		layer.Modulators = make([]glow.Modulator, len(fld.Modulators))
+		copy(layer.Modulators, fld.Modulators)
+	}
 }
 
 // func (fld *Fields) IsDirty(layer *glow.Layer) bool {
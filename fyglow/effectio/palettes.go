@@ -0,0 +1,70 @@
+package effectio
+
+import (
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+)
+
+func (eff *EffectIo) paletteHandler() (iohandler.PaletteHandler, error) {
+	palettes, ok := eff.IoHandler.(iohandler.PaletteHandler)
+	if !ok {
+		return nil, fmt.Errorf("palettes are not stored by %s", eff.Accessor.Driver)
+	}
+	return palettes, nil
+}
+
+// ListPalettes returns the titles of the shared palettes.
+func (eff *EffectIo) ListPalettes() []string {
+	palettes, err := eff.paletteHandler()
+	if err != nil {
+		return []string{}
+	}
+	titles, err := palettes.ListPalettes()
+	if err != nil {
+		iohandler.LogError("ListPalettes", err)
+	}
+	return titles
+}
+
+func (eff *EffectIo) ReadPalette(title string) (*glow.Palette, error) {
+	palettes, err := eff.paletteHandler()
+	if err != nil {
+		return nil, err
+	}
+	return palettes.ReadPalette(title)
+}
+
+func (eff *EffectIo) WritePalette(palette *glow.Palette) error {
+	palettes, err := eff.paletteHandler()
+	if err != nil {
+		return err
+	}
+	err = palettes.WritePalette(palette)
+	if err == nil {
+		eff.alertPalette()
+	}
+	return err
+}
+
+func (eff *EffectIo) RemovePalette(title string) error {
+	palettes, err := eff.paletteHandler()
+	if err != nil {
+		return err
+	}
+	err = palettes.RemovePalette(title)
+	if err == nil {
+		eff.alertPalette()
+	}
+	return err
+}
+
+// ValidatePaletteName checks a title for a new palette.
+func (eff *EffectIo) ValidatePaletteName(title string) error {
+	for _, existing := range eff.ListPalettes() {
+		if existing == title {
+			return fmt.Errorf("%s already exists", title)
+		}
+	}
+	return ValidateEffectName(title)
+}
@@ -0,0 +1,67 @@
+package effectio
+
+import (
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+)
+
+func (eff *EffectIo) playlistHandler() (iohandler.PlaylistHandler, error) {
+	playlists, ok := eff.IoHandler.(iohandler.PlaylistHandler)
+	if !ok {
+		return nil, fmt.Errorf("playlists are not stored by %s", eff.Accessor.Driver)
+	}
+	return playlists, nil
+}
+
+// ListPlaylists returns the titles of the playlists.
+func (eff *EffectIo) ListPlaylists() []string {
+	playlists, err := eff.playlistHandler()
+	if err != nil {
+		return []string{}
+	}
+	titles, err := playlists.ListPlaylists()
+	if err != nil {
+		iohandler.LogError("ListPlaylists", err)
+	}
+	return titles
+}
+
+func (eff *EffectIo) ReadPlaylist(title string) (*glow.Playlist, error) {
+	playlists, err := eff.playlistHandler()
+	if err != nil {
+		return nil, err
+	}
+	return playlists.ReadPlaylist(title)
+}
+
+func (eff *EffectIo) WritePlaylist(playlist *glow.Playlist) error {
+	playlists, err := eff.playlistHandler()
+	if err != nil {
+		return err
+	}
+	return playlists.WritePlaylist(playlist)
+}
+
+func (eff *EffectIo) RemovePlaylist(title string) error {
+	playlists, err := eff.playlistHandler()
+	if err != nil {
+		return err
+	}
+	return playlists.RemovePlaylist(title)
+}
+
+// ValidatePlaylistName checks a title for a new playlist.
+func (eff *EffectIo) ValidatePlaylistName(title string) error {
+	for _, existing := range eff.ListPlaylists() {
+		if existing == title {
+			return fmt.Errorf("%s already exists", title)
+		}
+	}
+	return ValidateEffectName(title)
+}
+
+// NewSequence reads the effects of playlist to play it.
+func (eff *EffectIo) NewSequence(playlist *glow.Playlist) (*glow.Sequence, error) {
+	return glow.NewSequence(playlist, eff.IoHandler.ReadEffect)
+}
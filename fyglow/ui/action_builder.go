@@ -3,6 +3,7 @@ package ui
 import (
 	"gglow/action"
 	"gglow/fyglow/effectio"
+	"gglow/glow"
 	"gglow/iohandler"
 	"strings"
 
@@ -12,7 +13,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-func BuildAction(data binding.BoolTree, effect *effectio.EffectIo, drivers []string, path string) (act *action.Action) {
+func BuildAction(data binding.BoolTree, effect *effectio.EffectIo, drivers []string, path, pixelMap string, output *glow.Output, white *glow.White, wiring glow.Wiring) (act *action.Action) {
 	act = action.NewAction()
 	act.Method = "clone"
 	act.Input = effect.Accessor
@@ -22,6 +23,10 @@ func BuildAction(data binding.BoolTree, effect *effectio.EffectIo, drivers []str
 			Driver:   driver,
 			Path:     path,
 			Database: path,
+			PixelMap: pixelMap,
+			Output:   output,
+			White:    white,
+			Wiring:   wiring,
 		}
 		act.Outputs = append(act.Outputs, output)
 	}
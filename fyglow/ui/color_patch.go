@@ -31,6 +31,7 @@ type ColorPatch struct {
 	rectangle  *canvas.Rectangle
 	background *canvas.Rectangle
 	colorHSV   glow.HSV
+	position   uint16
 
 	hovered, focused bool
 	unused           bool
@@ -52,6 +53,7 @@ func NewColorPatchWithColor(hsv glow.HSV, onTapped func(), onChanged func()) *Co
 		rectangle:  canvas.NewRectangle(hsv.ToRGB()),
 		onChanged:  onChanged,
 		onTapped:   onTapped,
+		position:   glow.PositionAuto,
 	}
 	cp.colorHSV = hsv
 	cp.ExtendBaseWidget(cp)
@@ -180,6 +182,7 @@ func (cp *ColorPatch) SetOnTapped(tapped func()) {
 
 func (cp *ColorPatch) SetUnused(b bool) {
 	cp.unused = b
+	cp.position = glow.PositionAuto
 	cp.setFill(theme.DisabledColor())
 	cp.setChanged()
 }
@@ -199,6 +202,7 @@ func (cp *ColorPatch) GetColor() color.Color {
 func (cp *ColorPatch) CopyPatch(source *ColorPatch) {
 	cp.unused = source.unused
 	cp.colorHSV = source.colorHSV
+	cp.position = source.position
 	if cp.unused {
 		cp.SetUnused(true)
 	} else {
@@ -206,6 +210,16 @@ func (cp *ColorPatch) CopyPatch(source *ColorPatch) {
 	}
 }
 
+// GetPosition returns the percent position of the color along the
+// gradient or glow.PositionAuto.
+func (cp *ColorPatch) GetPosition() uint16 {
+	return cp.position
+}
+
+func (cp *ColorPatch) SetPosition(position uint16) {
+	cp.position = position
+}
+
 func (cp *ColorPatch) SetHSVColor(hsv glow.HSV) {
 	cp.unused = false
 	cp.colorHSV = hsv
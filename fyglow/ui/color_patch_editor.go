@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"gglow/glow"
 	"gglow/text"
 	"image/color"
@@ -27,9 +28,14 @@ type ColorPatchEditor struct {
 	value        binding.Float
 	unused       binding.Bool
 	removeButton *widget.Button
+
+	gradientBar   *GradientBar
+	positionLabel *widget.Label
+	checkPosition *widget.Check
 }
 
-func NewColorPatchEditor(source *ColorPatch,
+// NewColorPatchEditor edits source, the color at index of chroma.
+func NewColorPatchEditor(source *ColorPatch, chroma glow.Chroma, index int,
 	window fyne.Window, onUpdate func()) *ColorPatchEditor {
 
 	pe := &ColorPatchEditor{
@@ -45,6 +51,7 @@ func NewColorPatchEditor(source *ColorPatch,
 	}
 
 	pe.patch.Editing = true
+	pe.patch.SetPosition(source.GetPosition())
 
 	hueLabel := widget.NewLabelWithData(binding.FloatToStringWithFormat(pe.hue, "%3.0f"))
 	hueSlider := NewButtonSlide(pe.hue, HueBounds)
@@ -67,6 +74,14 @@ func NewColorPatchEditor(source *ColorPatch,
 	pe.removeButton = widget.NewButtonWithIcon(text.CutLabel.String(), theme.ContentCutIcon(),
 		pe.remove)
 
+	pe.gradientBar = NewGradientBar(chroma, index, pe.setPosition)
+	pe.positionLabel = widget.NewLabel("")
+	pe.checkPosition = widget.NewCheck(text.PositionLabel.String(), pe.fixPosition)
+	pe.checkPosition.SetChecked(pe.patch.GetPosition() != glow.PositionAuto)
+	pe.setPositionLabel()
+	positionBox := container.NewBorder(nil, nil, pe.checkPosition, pe.positionLabel,
+		pe.gradientBar)
+
 	pe.hue.AddListener(binding.NewDataListener(pe.setHue))
 	pe.saturation.AddListener(binding.NewDataListener(pe.setSaturation))
 	pe.value.AddListener(binding.NewDataListener(pe.setValue))
@@ -82,6 +97,7 @@ func NewColorPatchEditor(source *ColorPatch,
 		hueBox,
 		saturationBox,
 		valueBox,
+		positionBox,
 		widget.NewSeparator(), pickerButton)
 
 	pe.CustomDialog = dialog.NewCustomWithoutButtons("", vbox, window)
@@ -117,6 +133,31 @@ func (pe *ColorPatchEditor) setValue() {
 
 func (pe *ColorPatchEditor) setColor(hsv glow.HSV) {
 	pe.patch.SetHSVColor(hsv)
+	if pe.gradientBar != nil {
+		pe.gradientBar.SetColor(hsv)
+	}
+}
+
+func (pe *ColorPatchEditor) setPosition(position uint16) {
+	pe.patch.SetPosition(position)
+	pe.checkPosition.SetChecked(true)
+	pe.setPositionLabel()
+}
+
+func (pe *ColorPatchEditor) fixPosition(fixed bool) {
+	position := glow.PositionAuto
+	if fixed {
+		position = pe.gradientBar.StopPosition()
+	}
+	if position != pe.patch.GetPosition() {
+		pe.patch.SetPosition(position)
+		pe.gradientBar.SetPosition(position)
+		pe.setPositionLabel()
+	}
+}
+
+func (pe *ColorPatchEditor) setPositionLabel() {
+	pe.positionLabel.SetText(fmt.Sprintf("%3d%%", pe.gradientBar.StopPosition()))
 }
 
 func (pe *ColorPatchEditor) remove() {
@@ -142,3 +183,53 @@ func (le *ColorPatchEditor) selectColorPicker(patch *ColorPatch) func() {
 		picker.Show()
 	}
 }
+
+// patchChroma returns the colors the patches hold with the index
+// of patch among them.
+func patchChroma(patches []*ColorPatch, patch *ColorPatch,
+	interpolation glow.Interpolation) (chroma glow.Chroma, index int) {
+	chroma.Interpolation = interpolation
+	index = -1
+	for _, p := range patches {
+		if p == patch {
+			index = len(chroma.Colors)
+		} else if p.Unused() {
+			continue
+		}
+		chroma.Colors = append(chroma.Colors, p.GetHSVColor())
+		chroma.Positions = append(chroma.Positions, p.GetPosition())
+	}
+	return
+}
+
+// patchColors returns the colors of the used patches, with
+// their positions when any is placed.
+func patchColors(patches []*ColorPatch) (colors []glow.HSV, positions []uint16) {
+	colors = make([]glow.HSV, 0)
+	positioned := false
+	for _, p := range patches {
+		if !p.Unused() {
+			colors = append(colors, p.GetHSVColor())
+			positions = append(positions, p.GetPosition())
+			positioned = positioned || p.GetPosition() != glow.PositionAuto
+		}
+	}
+	if !positioned {
+		positions = nil
+	}
+	return
+}
+
+func setPatches(patches []*ColorPatch, colors []glow.HSV, positions []uint16) {
+	for i, p := range patches {
+		if i < len(colors) {
+			p.SetHSVColor(colors[i])
+			p.SetPosition(glow.PositionAuto)
+			if i < len(positions) {
+				p.SetPosition(positions[i])
+			}
+		} else {
+			p.SetUnused(true)
+		}
+	}
+}
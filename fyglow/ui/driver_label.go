@@ -13,6 +13,7 @@ type driverKey struct {
 var driverKeys = []driverKey{
 	{text.CodeLabel.String(), iohandler.DRIVER_CODE},
 	{text.DataLabel.String(), iohandler.DRIVER_SQLLITE3},
+	{text.PreviewLabel.String(), iohandler.DRIVER_GIF},
 }
 
 func DriverLabels() []string {
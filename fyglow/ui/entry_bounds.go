@@ -24,10 +24,22 @@ func NewIntEntryBounds(min, max, on, off int) *IntEntryBounds {
 }
 
 var (
-	RateBounds       = &IntEntryBounds{MinVal: 16, MaxVal: 360, OnVal: 48, OffVal: 0}
-	HueShiftBounds   = &IntEntryBounds{MinVal: -10, MaxVal: 10, OnVal: 1, OffVal: 0}
-	ScanBounds       = &IntEntryBounds{MinVal: 1, MaxVal: 10, OnVal: 1, OffVal: 0}
-	HueBounds        = &FloatEntryBounds{MinVal: 0, MaxVal: 360, OnVal: 180, OffVal: 0}
-	SaturationBounds = &FloatEntryBounds{MinVal: 0, MaxVal: 100, OnVal: 50, OffVal: 0}
-	ValueBounds      = &FloatEntryBounds{MinVal: 0, MaxVal: 100, OnVal: 50, OffVal: 0}
+	RateBounds        = &IntEntryBounds{MinVal: 16, MaxVal: 360, OnVal: 48, OffVal: 0}
+	HueShiftBounds    = &IntEntryBounds{MinVal: -10, MaxVal: 10, OnVal: 1, OffVal: 0}
+	ScanBounds        = &IntEntryBounds{MinVal: 1, MaxVal: 10, OnVal: 1, OffVal: 0}
+	PercentBounds     = &IntEntryBounds{MinVal: 1, MaxVal: 100, OnVal: 100, OffVal: 100}
+	LevelBounds       = &IntEntryBounds{MinVal: 0, MaxVal: 100, OnVal: 100, OffVal: 100}
+	EnvelopeBounds    = &IntEntryBounds{MinVal: 0, MaxVal: 1000, OnVal: 0, OffVal: 0}
+	SpeedBounds       = &IntEntryBounds{MinVal: 0, MaxVal: 10, OnVal: 1, OffVal: 0}
+	SeedBounds        = &IntEntryBounds{MinVal: 0, MaxVal: 65535, OnVal: 0, OffVal: 0}
+	NoiseBounds       = &IntEntryBounds{MinVal: 0, MaxVal: 1024, OnVal: 0, OffVal: 0}
+	SpawnBounds       = &IntEntryBounds{MinVal: 0, MaxVal: 3200, OnVal: 50, OffVal: 0}
+	LifetimeBounds    = &IntEntryBounds{MinVal: 0, MaxVal: 1000, OnVal: 16, OffVal: 0}
+	TailBounds        = &IntEntryBounds{MinVal: 0, MaxVal: 64, OnVal: 4, OffVal: 0}
+	TemperatureBounds = &IntEntryBounds{MinVal: 0, MaxVal: 40000, OnVal: 6500, OffVal: 0}
+	BudgetBounds      = &IntEntryBounds{MinVal: 0, MaxVal: 100000, OnVal: 10000, OffVal: 0}
+	HueBounds         = &FloatEntryBounds{MinVal: 0, MaxVal: 360, OnVal: 180, OffVal: 0}
+	SaturationBounds  = &FloatEntryBounds{MinVal: 0, MaxVal: 100, OnVal: 50, OffVal: 0}
+	ValueBounds       = &FloatEntryBounds{MinVal: 0, MaxVal: 100, OnVal: 50, OffVal: 0}
+	GammaBounds       = &FloatEntryBounds{MinVal: 0, MaxVal: 4, OnVal: 2.2, OffVal: 0}
 )
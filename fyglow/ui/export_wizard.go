@@ -3,6 +3,8 @@ package ui
 import (
 	"gglow/action"
 	"gglow/fyglow/effectio"
+	"gglow/glow"
+	"gglow/settings"
 	"gglow/text"
 
 	"fyne.io/fyne/v2"
@@ -97,7 +99,13 @@ func (exz *ExportWizard) onSelected(effect *effectio.EffectIo, confirm fyne.Canv
 		switch current {
 		case STEP_CONFIRM:
 			path, _ := exz.path.Get()
-			exz.act = BuildAction(exz.data, effect, []string{exz.driver}, path)
+			preferences := fyne.CurrentApp().Preferences()
+			pixelMap := preferences.String(settings.StripMap.String())
+			output := OutputPreferences(preferences)
+			white := WhitePreferences(preferences)
+			wiring := glow.Wiring(preferences.Int(settings.StripWiring.String()))
+			exz.act = BuildAction(exz.data, effect, []string{exz.driver}, path, pixelMap,
+				output, white, wiring)
 			exz.tabList[STEP_CONFIRM].Content =
 				WrapVertical(confirm, ConfirmView(exz.act))
 			exz.nextButton.SetText(text.ProceedLabel.String())
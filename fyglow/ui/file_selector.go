@@ -4,6 +4,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -22,3 +23,21 @@ func NewFolderSelector(path binding.String, window fyne.Window) fyne.CanvasObjec
 	})
 	return btn
 }
+
+func NewFileSelector(path binding.String, extensions []string, window fyne.Window) fyne.CanvasObject {
+	btn := widget.NewButton("Select File", func() {
+		dlg := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+			if err != nil || uc == nil {
+				if err != nil {
+					fyne.LogError("ShowFileOpen", err)
+				}
+				return
+			}
+			uc.Close()
+			path.Set(uc.URI().Path())
+		}, window)
+		dlg.SetFilter(storage.NewExtensionFileFilter(extensions))
+		dlg.Show()
+	})
+	return btn
+}
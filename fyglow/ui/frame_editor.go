@@ -5,6 +5,8 @@ import (
 	"gglow/glow"
 	"gglow/text"
 	"strconv"
+	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -19,7 +21,15 @@ type FrameEditor struct {
 	fields     *effectio.FrameFields
 	rateBounds *IntEntryBounds
 	rateBox    *RangeIntBox
+	brightness *RangeIntBox
+	opacity    *RangeIntBox
+	power      *widget.Label
+	lint       *widget.Label
 	isEditing  bool
+
+	// powerRuns counts the analyses started so only the latest shows
+	powerRuns uint32
+	powerLock sync.Mutex
 }
 
 func NewFrameEditor(effect *effectio.EffectIo, window fyne.Window, menu *fyne.Menu) *FrameEditor {
@@ -33,8 +43,19 @@ func NewFrameEditor(effect *effectio.EffectIo, window fyne.Window, menu *fyne.Me
 	tools := container.NewCenter(NewFrameToolbar(effect))
 	ratelabel := widget.NewLabel(text.RateLabel.String())
 	fe.rateBox = NewRangeIntBox(fe.fields.Interval, fe.rateBounds)
+	brightnessLabel := widget.NewLabel(text.BrightnessLabel.String())
+	fe.brightness = NewRangeIntBox(fe.fields.Brightness, LevelBounds)
+	opacityLabel := widget.NewLabel(text.OpacityLabel.String())
+	fe.opacity = NewRangeIntBox(fe.fields.Opacity, LevelBounds)
+	fe.power = widget.NewLabel("")
+	fe.lint = widget.NewLabel("")
+	fe.lint.Wrapping = fyne.TextWrapWord
 	frm := container.New(layout.NewFormLayout(),
-		ratelabel, fe.rateBox.Container)
+		ratelabel, fe.rateBox.Container,
+		brightnessLabel, fe.brightness.Container,
+		opacityLabel, fe.opacity.Container,
+		widget.NewLabel(text.PowerLabel.String()), fe.power,
+		widget.NewLabel(text.DiagnosticsLabel.String()), fe.lint)
 	fe.Container = container.NewBorder(tools, nil, nil, nil, frm)
 
 	effect.OnSave(fe.apply)
@@ -47,6 +68,22 @@ func NewFrameEditor(effect *effectio.EffectIo, window fyne.Window, menu *fyne.Me
 		}
 	}))
 
+	fe.fields.Brightness.AddListener(binding.NewDataListener(func() {
+		frame := fe.effect.GetFrame()
+		brightness, _ := fe.fields.Brightness.Get()
+		if brightness != int(frame.BrightnessPercent()) {
+			fe.setChanged()
+		}
+	}))
+
+	fe.fields.Opacity.AddListener(binding.NewDataListener(func() {
+		frame := fe.effect.GetFrame()
+		opacity, _ := fe.fields.Opacity.Get()
+		if opacity != int(frame.OpacityPercent()) {
+			fe.setChanged()
+		}
+	}))
+
 	fe.effect.AddFrameListener(binding.NewDataListener(fe.setFields))
 	return fe
 }
@@ -62,9 +99,69 @@ func (fe *FrameEditor) setFields() {
 	frame := fe.effect.GetFrame()
 	fe.fields.FromFrame(frame)
 	fe.rateBox.Entry.SetText(strconv.FormatInt(int64(frame.Interval), 10))
+	fe.brightness.Entry.SetText(strconv.FormatInt(int64(frame.BrightnessPercent()), 10))
+	fe.opacity.Entry.SetText(strconv.FormatInt(int64(frame.OpacityPercent()), 10))
+	fe.setPower(frame)
+	fe.setLint(frame)
 	fe.isEditing = true
 }
 
+// setPower analyzes a copy of the frame in the background, showing
+// the report once the latest analysis is done.
+func (fe *FrameEditor) setPower(frame *glow.Frame) {
+	pa := PowerPreferences(fyne.CurrentApp().Preferences())
+	source, err := glow.FrameDeepCopy(frame)
+	fe.powerLock.Lock()
+	fe.powerRuns++
+	run := fe.powerRuns
+	fe.powerLock.Unlock()
+	if err != nil {
+		fe.showPower(run, pa, glow.PowerReport{}, err)
+		return
+	}
+	go func() {
+		report, err := pa.Analyze(source)
+		fe.showPower(run, pa, report, err)
+	}()
+}
+
+func (fe *FrameEditor) showPower(run uint32, pa *glow.PowerAnalyzer, report glow.PowerReport, err error) {
+	fe.powerLock.Lock()
+	defer fe.powerLock.Unlock()
+	if run != fe.powerRuns {
+		return
+	}
+	if err != nil {
+		fe.power.Importance = widget.WarningImportance
+		fe.power.SetText(err.Error())
+		return
+	}
+	fe.power.Importance = widget.MediumImportance
+	if report.Exceeds(pa.Budget) {
+		fe.power.Importance = widget.DangerImportance
+	}
+	fe.power.SetText(report.String())
+}
+
+// setLint lists the frame's diagnostics, one to a line.
+func (fe *FrameEditor) setLint(frame *glow.Frame) {
+	diagnostics := frame.Lint()
+	fe.lint.Importance = widget.MediumImportance
+	if len(diagnostics) == 0 {
+		fe.lint.SetText(text.NoDiagnosticsLabel.String())
+		return
+	}
+	lines := make([]string, len(diagnostics))
+	fe.lint.Importance = widget.WarningImportance
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Severity == glow.SeverityError {
+			fe.lint.Importance = widget.DangerImportance
+		}
+		lines[i] = diagnostic.String()
+	}
+	fe.lint.SetText(strings.Join(lines, "\n"))
+}
+
 func (fe *FrameEditor) apply(frame *glow.Frame) {
 	fe.fields.ToFrame(frame)
 }
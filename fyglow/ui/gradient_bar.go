@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"gglow/glow"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var _ fyne.Draggable = (*GradientBar)(nil)
+var _ fyne.Tappable = (*GradientBar)(nil)
+var _ fyne.Widget = (*GradientBar)(nil)
+
+// GradientBar shows a layer's colors along a bar with a marker at
+// each stop. The marker of the color being edited can be dragged.
+type GradientBar struct {
+	widget.BaseWidget
+	chroma    glow.Chroma
+	index     int
+	onChanged func(position uint16)
+
+	raster  *canvas.Raster
+	markers []*canvas.Rectangle
+}
+
+func NewGradientBar(chroma glow.Chroma, index int, onChanged func(uint16)) *GradientBar {
+	gb := &GradientBar{
+		chroma:    chroma,
+		index:     index,
+		onChanged: onChanged,
+	}
+	gb.chroma.Colors = append([]glow.HSV{}, chroma.Colors...)
+	gb.chroma.Positions = append([]uint16{}, chroma.Positions...)
+	for len(gb.chroma.Positions) < len(gb.chroma.Colors) {
+		gb.chroma.Positions = append(gb.chroma.Positions, glow.PositionAuto)
+	}
+	gb.chroma.HueShift = 0
+	gb.raster = canvas.NewRasterWithPixels(gb.pixel)
+	gb.markers = make([]*canvas.Rectangle, len(gb.chroma.Colors))
+	for i := range gb.markers {
+		gb.markers[i] = canvas.NewRectangle(theme.ForegroundColor())
+	}
+	gb.ExtendBaseWidget(gb)
+	return gb
+}
+
+func (gb *GradientBar) pixel(x, y, w, h int) color.Color {
+	if gb.chroma.Length != uint16(w) {
+		gb.chroma.SetupLength(uint16(w), 0)
+	}
+	return gb.chroma.Map(uint16(x))
+}
+
+// SetColor changes the color being edited.
+func (gb *GradientBar) SetColor(hsv glow.HSV) {
+	gb.chroma.Colors[gb.index] = hsv
+	gb.update()
+}
+
+// SetPosition places the color being edited, PositionAuto spacing
+// it evenly between its neighbours.
+func (gb *GradientBar) SetPosition(position uint16) {
+	gb.chroma.Positions[gb.index] = position
+	gb.update()
+}
+
+func (gb *GradientBar) StopPosition() uint16 {
+	return gb.chroma.StopPositions()[gb.index]
+}
+
+func (gb *GradientBar) update() {
+	gb.chroma.Length = 0
+	gb.Refresh()
+}
+
+func (gb *GradientBar) moveTo(x float32) {
+	width := gb.Size().Width
+	if width <= 0 {
+		return
+	}
+	positions := gb.chroma.StopPositions()
+	low, high := uint16(0), uint16(100)
+	if gb.index > 0 {
+		low = positions[gb.index-1]
+	}
+	if gb.index < len(positions)-1 {
+		high = positions[gb.index+1]
+	}
+	position := uint16(max(min(x/width*100+0.5, 100), 0))
+	position = max(min(position, high), low)
+	gb.SetPosition(position)
+	if gb.onChanged != nil {
+		gb.onChanged(position)
+	}
+}
+
+// Draggable
+func (gb *GradientBar) Dragged(d *fyne.DragEvent) {
+	gb.moveTo(d.Position.X)
+}
+
+func (gb *GradientBar) DragEnd() {
+} // Draggable
+
+func (gb *GradientBar) Tapped(ev *fyne.PointEvent) {
+	gb.moveTo(ev.Position.X)
+}
+
+type gradientBarRenderer struct {
+	bar *GradientBar
+}
+
+func (gb *GradientBar) CreateRenderer() fyne.WidgetRenderer {
+	gb.ExtendBaseWidget(gb)
+	return &gradientBarRenderer{bar: gb}
+}
+
+func (gr *gradientBarRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{gr.bar.raster}
+	for _, marker := range gr.bar.markers {
+		objects = append(objects, marker)
+	}
+	return objects
+}
+
+func (gr *gradientBarRenderer) Destroy() {}
+
+func (gr *gradientBarRenderer) Refresh() {
+	gr.Layout(gr.bar.Size())
+	gr.bar.raster.Refresh()
+}
+
+func (gr *gradientBarRenderer) Layout(size fyne.Size) {
+	markerHeight := theme.Padding() * 2
+	gr.bar.raster.Move(fyne.NewPos(0, markerHeight))
+	gr.bar.raster.Resize(fyne.NewSize(size.Width, size.Height-markerHeight))
+
+	positions := gr.bar.chroma.StopPositions()
+	for i, marker := range gr.bar.markers {
+		markerWidth := theme.Padding()
+		marker.FillColor = theme.DisabledColor()
+		if i == gr.bar.index {
+			markerWidth *= 2
+			marker.FillColor = theme.PrimaryColor()
+		}
+		x := size.Width*float32(positions[i])/100 - markerWidth/2
+		marker.Move(fyne.NewPos(x, 0))
+		marker.Resize(fyne.NewSize(markerWidth, markerHeight))
+		marker.Refresh()
+	}
+}
+
+func (gr *gradientBarRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(theme.IconInlineSize()*8, theme.IconInlineSize()+theme.Padding()*2)
+}
@@ -7,6 +7,8 @@ import (
 	"image"
 	"image/color"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -29,8 +31,12 @@ type ImageLoader struct {
 	window        fyne.Window
 	effect        *effectio.EffectIo
 	layer         *glow.Layer
-	picture       *image.NRGBA
+	animation     *glow.Animation
+	stop          chan struct{}
+	playing       sync.Mutex
 	view          *canvas.Image
+	filterSelect  *widget.Select
+	sequenceCheck *widget.Check
 	pickLabel     *widget.Label
 	pickDialog    *dialog.FileDialog
 	path          string
@@ -56,16 +62,25 @@ func NewImageLoader(effect *effectio.EffectIo, window fyne.Window) *ImageLoader
 	ld.view.Resize(DesiredSize(float32(ld.width), float32(ld.height),
 		window.Canvas().Size()))
 
-	sel := widget.NewSelect(glow.ResampleList, nil)
-	sel.SetSelectedIndex(int(ld.filter))
-	sel.OnChanged = func(string) { ld.filter = glow.ResampleItem(sel.SelectedIndex()) }
+	ld.filterSelect = widget.NewSelect(glow.ResampleList, nil)
+	ld.filterSelect.SetSelectedIndex(int(ld.filter))
+	ld.filterSelect.OnChanged = func(string) {
+		filter := glow.ResampleItem(ld.filterSelect.SelectedIndex())
+		if filter != ld.filter {
+			ld.filter = filter
+			ld.updatePath(ld.path, true)
+		}
+	}
+
+	ld.sequenceCheck = widget.NewCheck(text.SequenceLabel.String(), ld.setSequence)
 
 	content := container.NewBorder(container.NewCenter(ld.pickLabel), nil,
-		sel, nil, ld.view)
+		container.NewVBox(ld.filterSelect, ld.sequenceCheck), nil, ld.view)
 	ld.CustomDialog = dialog.NewCustom(text.ImageLoad.String(), "", content, window)
 
 	applyButton := widget.NewButton(text.ApplyLabel.String(), ld.apply)
 	cancelButton := widget.NewButton(text.CancelLabel.String(), func() {
+		ld.stopPlaying()
 		ld.CustomDialog.Hide()
 	})
 	ld.SetButtons([]fyne.CanvasObject{cancelButton, pickButton, applyButton})
@@ -93,24 +108,99 @@ func (ld *ImageLoader) pickClick() {
 
 }
 
+// setSequence names the numbered sequence the picked file belongs to,
+// or its first file when unchecked.
+func (ld *ImageLoader) setSequence(on bool) {
+	path := ld.path
+	if on {
+		path = glow.SequenceName(path)
+	} else if glow.IsSequence(path) {
+		paths, err := glow.SequencePaths(path)
+		if err != nil {
+			fyne.LogError("Image Loader", err)
+		}
+		if len(paths) == 0 {
+			return
+		}
+		path = paths[0]
+	}
+	if path != ld.path {
+		ld.updatePath(path, true)
+	} else if on {
+		// the file has no number to make a sequence from
+		ld.sequenceCheck.SetChecked(false)
+	}
+}
+
 func (ld *ImageLoader) updatePath(path string, refresh bool) {
 	var err error
+	ld.stopPlaying()
 	ld.path = path
 	ld.pickLabel.SetText(path)
+	ld.sequenceCheck.SetChecked(glow.IsSequence(path))
+	ld.animation = nil
 	if len(path) > 1 {
-		ld.picture, err = glow.ResamplePath(path, ld.height, ld.width, ld.filter.Filter())
+		ld.animation, err = glow.LoadAnimation(path, ld.height, ld.width, ld.filter.Filter())
 		if err != nil {
 			fyne.LogError("Image Loader", err)
 		}
 	}
-	if ld.picture == nil {
-		ld.picture = imaging.New(ld.width, ld.height, color.Black)
+	if ld.animation == nil {
+		ld.animation = &glow.Animation{
+			Frames: []*image.NRGBA{imaging.New(ld.width, ld.height, color.Black)},
+			Delays: []uint32{0},
+		}
 	}
 
-	ld.view.Image = ld.picture
+	ld.view.Image = ld.animation.Frames[0]
 	if refresh {
 		ld.view.Refresh()
 	}
+	ld.play()
+}
+
+// play cycles the preview through the frames of an animation,
+// showing an image sequence at the default frame delay. A stopped
+// animation shows no more frames.
+func (ld *ImageLoader) play() {
+	anim := ld.animation
+	if anim.Len() < 2 {
+		return
+	}
+	stop := make(chan struct{})
+	ld.stop = stop
+	go func() {
+		for i := 0; ; i = (i + 1) % anim.Len() {
+			delay := anim.Delays[i]
+			if delay == 0 {
+				delay = glow.DefaultFrameDelay
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Duration(delay) * time.Millisecond):
+			}
+			ld.playing.Lock()
+			select {
+			case <-stop:
+				ld.playing.Unlock()
+				return
+			default:
+			}
+			ld.view.Image = anim.Frames[(i+1)%anim.Len()]
+			ld.playing.Unlock()
+			ld.view.Refresh()
+		}
+	}()
+}
+
+func (ld *ImageLoader) stopPlaying() {
+	ld.playing.Lock()
+	defer ld.playing.Unlock()
+	if ld.stop != nil {
+		close(ld.stop)
+		ld.stop = nil
+	}
 }
 
 func DesiredSize(w, h float32, max fyne.Size) fyne.Size {
@@ -126,8 +216,9 @@ func DesiredSize(w, h float32, max fyne.Size) fyne.Size {
 
 func (ld *ImageLoader) Start() {
 	ld.layer = ld.effect.GetCurrentLayer()
+	ld.filter = ld.layer.ImageFilter
+	ld.filterSelect.SetSelectedIndex(int(ld.filter))
 	ld.updatePath(ld.layer.ImageName, false)
-	ld.view.Image = ld.picture
 	dz := DesiredSize(DesiredWidth, DesiredHeight, ld.window.Canvas().Size())
 	ld.Resize(dz)
 	ld.Show()
@@ -146,8 +237,10 @@ func imagePicker(update func(string, bool), window fyne.Window) *dialog.FileDial
 }
 
 func (ld *ImageLoader) apply() {
+	ld.stopPlaying()
 	ld.CustomDialog.Hide()
 	layer := ld.effect.GetCurrentLayer()
 	layer.ImageName = ld.path
+	layer.ImageFilter = ld.filter
 	ld.effect.SetChanged()
 }
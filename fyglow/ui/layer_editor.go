@@ -30,15 +30,46 @@ type LayerEditor struct {
 
 	selectOrigin      *widget.Select
 	selectOrientation *widget.Select
+	selectBlend       *widget.Select
+	selectScanMode    *widget.Select
+	selectNoise       *widget.Select
+	selectParticles   *widget.Select
+	selectFade        *widget.Select
+	selectDirection   *widget.Select
+	selectInterpolate *widget.Select
+	selectPalette     *widget.Select
+	selectMask        *widget.Select
 
 	checkScan *widget.Check
 	checkHue  *widget.Check
 	checkRate *widget.Check
 
+	messageEntry  *widget.Entry
+	checkGradient *widget.Check
+	checkOverride *widget.Check
+	checkClip     *widget.Check
+
+	expressionEntry *widget.Entry
+	expressionError *widget.Label
+
 	scanBox *RangeIntBox
 	hueBox  *RangeIntBox
 	rateBox *RangeIntBox
 
+	opacityBox    *RangeIntBox
+	brightnessBox *RangeIntBox
+	attackBox     *RangeIntBox
+	sustainBox    *RangeIntBox
+	decayBox      *RangeIntBox
+	speedBox      *RangeIntBox
+	seedBox       *RangeIntBox
+	scaleBox      *RangeIntBox
+	driftBox      *RangeIntBox
+	spawnBox      *RangeIntBox
+	lifetimeBox   *RangeIntBox
+	tailBox       *RangeIntBox
+	sparkSeedBox  *RangeIntBox
+
 	rateBounds *IntEntryBounds
 	hueBounds  *IntEntryBounds
 	scanBounds *IntEntryBounds
@@ -46,6 +77,9 @@ type LayerEditor struct {
 	imageLabel  *widget.Label
 	imageButton *widget.Button
 
+	modulatorLabel  *widget.Label
+	modulatorButton *widget.Button
+
 	isEditing bool
 }
 
@@ -61,6 +95,15 @@ func NewLayerEditor(effect *effectio.EffectIo, window fyne.Window, menu *fyne.Me
 		scanBounds:        ScanBounds,
 		selectOrigin:      widget.NewSelect(text.OriginLabels, func(s string) {}),
 		selectOrientation: widget.NewSelect(text.OrientationLabels, func(s string) {}),
+		selectBlend:       widget.NewSelect(text.BlendLabels, func(s string) {}),
+		selectScanMode:    widget.NewSelect(text.ScanModeLabels, func(s string) {}),
+		selectNoise:       widget.NewSelect(text.NoiseLabels, func(s string) {}),
+		selectParticles:   widget.NewSelect(text.ParticleLabels, func(s string) {}),
+		selectFade:        widget.NewSelect(text.FadeLabels, func(s string) {}),
+		selectDirection:   widget.NewSelect(text.TextDirectionLabels, func(s string) {}),
+		selectInterpolate: widget.NewSelect(text.InterpolationLabels, func(s string) {}),
+		selectPalette:     widget.NewSelect([]string{}, func(s string) {}),
+		selectMask:        widget.NewSelect(text.MaskModeLabels, func(s string) {}),
 	}
 
 	le.createPatches()
@@ -75,6 +118,7 @@ func NewLayerEditor(effect *effectio.EffectIo, window fyne.Window, menu *fyne.Me
 	listener := binding.NewDataListener(le.setFields)
 	effect.AddFrameListener(listener)
 	effect.AddLayerListener(listener)
+	effect.AddPaletteListener(binding.NewDataListener(le.setPaletteOptions))
 	effect.OnSave(le.apply)
 	return le
 }
@@ -97,7 +141,9 @@ func (le *LayerEditor) createPatches() {
 
 func (le *LayerEditor) selectColor(patch *ColorPatch) func() {
 	return func() {
-		ce := NewColorPatchEditor(patch, le.window, le.setChanged)
+		interpolation, _ := le.fields.Interpolate.Get()
+		chroma, index := patchChroma(le.patches, patch, glow.Interpolation(interpolation))
+		ce := NewColorPatchEditor(patch, chroma, index, le.window, le.setChanged)
 		ce.Show()
 	}
 }
@@ -123,6 +169,155 @@ func (le *LayerEditor) createForm() *fyne.Container {
 		}
 	}
 
+	labelBlend := widget.NewLabel(text.BlendLabel.String())
+	le.selectBlend.OnChanged = func(s string) {
+		current := le.layer.Blend
+		selected := le.selectBlend.SelectedIndex()
+		if glow.BlendMode(selected) != current {
+			le.fields.Blend.Set(selected)
+			le.setChanged()
+		}
+	}
+
+	labelMask := widget.NewLabel(text.MaskLabel.String())
+	le.selectMask.OnChanged = func(s string) {
+		current := le.layer.Mask
+		selected := le.selectMask.SelectedIndex()
+		if glow.MaskMode(selected) != current {
+			le.fields.Mask.Set(selected)
+			le.setChanged()
+		}
+	}
+	clipLabel := widget.NewLabel(text.ClipLabel.String())
+	le.checkClip = widget.NewCheckWithData("", le.fields.Clip)
+	le.fields.Clip.AddListener(binding.NewDataListener(func() {
+		clip, _ := le.fields.Clip.Get()
+		if clip != le.layer.Clip {
+			le.setChanged()
+		}
+	}))
+
+	opacityLabel := widget.NewLabel(text.OpacityLabel.String())
+	le.opacityBox = le.newLevelBox(le.fields.Opacity, LevelBounds,
+		func() int { return int(le.layer.OpacityPercent()) })
+	brightnessLabel := widget.NewLabel(text.BrightnessLabel.String())
+	le.brightnessBox = le.newLevelBox(le.fields.Brightness, LevelBounds,
+		func() int { return int(le.layer.BrightnessPercent()) })
+
+	attackLabel := widget.NewLabel(text.AttackLabel.String())
+	le.attackBox = le.newLevelBox(le.fields.Attack, EnvelopeBounds,
+		func() int { return int(le.layer.Envelope.Attack) })
+	sustainLabel := widget.NewLabel(text.SustainLabel.String())
+	le.sustainBox = le.newLevelBox(le.fields.Sustain, EnvelopeBounds,
+		func() int { return int(le.layer.Envelope.Sustain) })
+	decayLabel := widget.NewLabel(text.DecayLabel.String())
+	le.decayBox = le.newLevelBox(le.fields.Decay, EnvelopeBounds,
+		func() int { return int(le.layer.Envelope.Decay) })
+
+	labelScanMode := widget.NewLabel(text.ScanModeLabel.String())
+	le.selectScanMode.OnChanged = func(s string) {
+		current := le.layer.ScanMode
+		selected := le.selectScanMode.SelectedIndex()
+		if glow.ScanMode(selected) != current {
+			le.fields.ScanMode.Set(selected)
+			le.setChanged()
+		}
+	}
+	speedLabel := widget.NewLabel(text.SpeedLabel.String())
+	le.speedBox = le.newLevelBox(le.fields.Speed, SpeedBounds,
+		func() int { return int(le.layer.Speed) })
+
+	labelNoise := widget.NewLabel(text.NoiseLabel.String())
+	le.selectNoise.OnChanged = func(s string) {
+		current := le.layer.Noise.Kind
+		selected := le.selectNoise.SelectedIndex()
+		if glow.NoiseKind(selected) != current {
+			le.fields.Noise.Set(selected)
+			le.setChanged()
+		}
+	}
+	seedLabel := widget.NewLabel(text.SeedLabel.String())
+	le.seedBox = le.newLevelBox(le.fields.NoiseSeed, SeedBounds,
+		func() int { return int(le.layer.Noise.Seed) })
+	scaleLabel := widget.NewLabel(text.ScaleLabel.String())
+	le.scaleBox = le.newLevelBox(le.fields.NoiseScale, NoiseBounds,
+		func() int { return int(le.layer.Noise.Scale) })
+	driftLabel := widget.NewLabel(text.DriftLabel.String())
+	le.driftBox = le.newLevelBox(le.fields.NoiseDrift, NoiseBounds,
+		func() int { return int(le.layer.Noise.Drift) })
+
+	labelParticles := widget.NewLabel(text.ParticlesLabel.String())
+	le.selectParticles.OnChanged = func(s string) {
+		current := le.layer.Particles.Kind
+		selected := le.selectParticles.SelectedIndex()
+		if glow.ParticleKind(selected) != current {
+			le.fields.Particles.Set(selected)
+			le.setChanged()
+		}
+	}
+	spawnLabel := widget.NewLabel(text.SpawnLabel.String())
+	le.spawnBox = le.newLevelBox(le.fields.Spawn, SpawnBounds,
+		func() int { return int(le.layer.Particles.Spawn) })
+	lifetimeLabel := widget.NewLabel(text.LifetimeLabel.String())
+	le.lifetimeBox = le.newLevelBox(le.fields.Lifetime, LifetimeBounds,
+		func() int { return int(le.layer.Particles.Lifetime) })
+	labelFade := widget.NewLabel(text.FadeLabel.String())
+	le.selectFade.OnChanged = func(s string) {
+		current := le.layer.Particles.Fade
+		selected := le.selectFade.SelectedIndex()
+		if glow.FadeCurve(selected) != current {
+			le.fields.Fade.Set(selected)
+			le.setChanged()
+		}
+	}
+	tailLabel := widget.NewLabel(text.TailLabel.String())
+	le.tailBox = le.newLevelBox(le.fields.Tail, TailBounds,
+		func() int { return int(le.layer.Particles.Tail) })
+	sparkSeedLabel := widget.NewLabel(text.SeedLabel.String())
+	le.sparkSeedBox = le.newLevelBox(le.fields.SparkSeed, SeedBounds,
+		func() int { return int(le.layer.Particles.Seed) })
+
+	messageLabel := widget.NewLabel(text.MessageLabel.String())
+	le.messageEntry = widget.NewEntryWithData(le.fields.Message)
+	le.messageEntry.SetPlaceHolder(text.MessageLabel.PlaceHolder())
+	le.fields.Message.AddListener(binding.NewDataListener(func() {
+		message, _ := le.fields.Message.Get()
+		if message != le.layer.Text.Message {
+			le.setChanged()
+		}
+	}))
+	labelDirection := widget.NewLabel(text.DirectionLabel.String())
+	le.selectDirection.OnChanged = func(s string) {
+		current := le.layer.Text.Direction
+		selected := le.selectDirection.SelectedIndex()
+		if glow.TextDirection(selected) != current {
+			le.fields.Direction.Set(selected)
+			le.setChanged()
+		}
+	}
+	gradientLabel := widget.NewLabel(text.GradientLabel.String())
+	le.checkGradient = widget.NewCheckWithData("", le.fields.Gradient)
+	le.fields.Gradient.AddListener(binding.NewDataListener(func() {
+		gradient, _ := le.fields.Gradient.Get()
+		if gradient != le.layer.Text.Gradient {
+			le.setChanged()
+		}
+	}))
+
+	expressionLabel := widget.NewLabel(text.ExpressionLabel.String())
+	le.expressionEntry = widget.NewEntryWithData(le.fields.Expression)
+	le.expressionEntry.MultiLine = true
+	le.expressionEntry.SetPlaceHolder("hue = x*10 + t*30")
+	le.expressionError = widget.NewLabel("")
+	le.expressionError.Importance = widget.DangerImportance
+	le.fields.Expression.AddListener(binding.NewDataListener(func() {
+		expression, _ := le.fields.Expression.Get()
+		le.checkExpression(expression)
+		if expression != le.layer.Expression {
+			le.setChanged()
+		}
+	}))
+
 	scanLabel := widget.NewLabel(text.LengthLabel.String())
 	scanCheckLabel := widget.NewLabel(text.ScanLabel.String())
 	le.scanBox = NewRangeIntBox(le.fields.Scan, le.scanBounds)
@@ -135,6 +330,38 @@ func (le *LayerEditor) createForm() *fyne.Container {
 	le.checkScan = widget.NewCheck("", checkRangeBox(le.scanBox, le.fields.Scan))
 
 	colorsLabel := widget.NewLabel(text.ColorsLabel.String())
+	labelPalette := widget.NewLabel(text.PaletteLabel.String())
+	le.selectPalette.OnChanged = func(s string) {
+		selected := s
+		if selected == text.NoPaletteLabel.String() {
+			selected = ""
+		}
+		current, _ := le.fields.Palette.Get()
+		if selected != current {
+			le.fields.Palette.Set(selected)
+			le.loadPalette()
+			le.setChanged()
+		}
+	}
+	overrideLabel := widget.NewLabel(text.OverrideLabel.String())
+	le.checkOverride = widget.NewCheckWithData("", le.fields.Override)
+	le.fields.Override.AddListener(binding.NewDataListener(func() {
+		override, _ := le.fields.Override.Get()
+		if override != le.layer.Chroma.Override {
+			le.loadPalette()
+			le.setChanged()
+		}
+	}))
+
+	labelInterpolate := widget.NewLabel(text.InterpolationLabel.String())
+	le.selectInterpolate.OnChanged = func(s string) {
+		current := le.layer.Chroma.Interpolation
+		selected := le.selectInterpolate.SelectedIndex()
+		if glow.Interpolation(selected) != current {
+			le.fields.Interpolate.Set(selected)
+			le.setChanged()
+		}
+	}
 	// gradientLabel := widget.NewLabel(resources.GradientLabel.String())
 
 	huelabel := widget.NewLabel(text.HueShiftLabel.String())
@@ -170,25 +397,90 @@ func (le *LayerEditor) createForm() *fyne.Container {
 		imageLoad.Start()
 	})
 
+	modulators := NewModulatorDialog(le.fields, le.window, func() {
+		le.modulatorLabel.SetText(strconv.Itoa(len(le.fields.Modulators)))
+		le.setChanged()
+	})
+	le.modulatorLabel = widget.NewLabel(strconv.Itoa(len(le.layer.Modulators)))
+	le.modulatorButton = widget.NewButton(text.ModulatorsLabel.String()+"...", modulators.Start)
+
 	sep := widget.NewSeparator()
 	frm := container.New(layout.NewFormLayout(),
 		sep, sep,
 		labelOrigin, le.selectOrigin,
 		labelOrientation, le.selectOrientation,
+		labelBlend, le.selectBlend,
+		labelMask, le.selectMask,
+		clipLabel, le.checkClip,
 		scanCheckLabel, le.checkScan,
 		scanLabel, le.scanBox.Container,
+		labelScanMode, le.selectScanMode,
+		speedLabel, le.speedBox.Container,
 		sep, sep,
 		colorsLabel, patchBox,
+		labelPalette, le.selectPalette,
+		overrideLabel, le.checkOverride,
+		labelInterpolate, le.selectInterpolate,
 		hueCheckLabel, le.checkHue,
 		huelabel, le.hueBox.Container,
 		sep, sep,
+		labelNoise, le.selectNoise,
+		seedLabel, le.seedBox.Container,
+		scaleLabel, le.scaleBox.Container,
+		driftLabel, le.driftBox.Container,
+		sep, sep,
+		labelParticles, le.selectParticles,
+		spawnLabel, le.spawnBox.Container,
+		lifetimeLabel, le.lifetimeBox.Container,
+		labelFade, le.selectFade,
+		tailLabel, le.tailBox.Container,
+		sparkSeedLabel, le.sparkSeedBox.Container,
+		sep, sep,
+		messageLabel, le.messageEntry,
+		labelDirection, le.selectDirection,
+		gradientLabel, le.checkGradient,
+		sep, sep,
+		expressionLabel, le.expressionEntry,
+		layout.NewSpacer(), le.expressionError,
+		sep, sep,
 		rateCheckLabel, le.checkRate,
 		ratelabel, le.rateBox.Container,
+		sep, sep,
+		opacityLabel, le.opacityBox.Container,
+		brightnessLabel, le.brightnessBox.Container,
+		attackLabel, le.attackBox.Container,
+		sustainLabel, le.sustainBox.Container,
+		decayLabel, le.decayBox.Container,
+		sep, sep,
+		le.modulatorButton, le.modulatorLabel,
 		le.imageButton, le.imageLabel,
 	)
 	return frm
 }
 
+// checkExpression shows where an expression fails to compile.
+func (le *LayerEditor) checkExpression(expression string) {
+	var message string
+	if expression != "" {
+		if _, err := glow.CompileProgram(expression); err != nil {
+			message = err.Error()
+		}
+	}
+	le.expressionError.SetText(message)
+}
+
+func (le *LayerEditor) newLevelBox(field binding.Int, bounds *IntEntryBounds,
+	current func() int) *RangeIntBox {
+	box := NewRangeIntBox(field, bounds)
+	field.AddListener(binding.NewDataListener(func() {
+		i, _ := field.Get()
+		if i != current() {
+			le.setChanged()
+		}
+	}))
+	return box
+}
+
 func (le *LayerEditor) setFields() {
 	le.isEditing = false
 	le.layer = le.effect.GetCurrentLayer()
@@ -196,6 +488,15 @@ func (le *LayerEditor) setFields() {
 
 	le.selectOrigin.SetSelectedIndex(int(le.layer.Grid.Origin))
 	le.selectOrientation.SetSelectedIndex(int(le.layer.Grid.Orientation))
+	le.selectBlend.SetSelectedIndex(int(le.layer.Blend))
+	le.selectScanMode.SetSelectedIndex(int(le.layer.ScanMode))
+	le.selectNoise.SetSelectedIndex(int(le.layer.Noise.Kind))
+	le.selectParticles.SetSelectedIndex(int(le.layer.Particles.Kind))
+	le.selectFade.SetSelectedIndex(int(le.layer.Particles.Fade))
+	le.selectDirection.SetSelectedIndex(int(le.layer.Text.Direction))
+	le.selectInterpolate.SetSelectedIndex(int(le.layer.Chroma.Interpolation))
+	le.selectMask.SetSelectedIndex(int(le.layer.Mask))
+	le.setPaletteOptions()
 
 	le.bDynamic = (le.layer.HueShift != int16(le.hueBounds.OffVal))
 	le.hueBox.Entry.SetText(strconv.FormatInt(int64(le.layer.HueShift), 10))
@@ -212,15 +513,24 @@ func (le *LayerEditor) setFields() {
 	le.rateBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Rate), 10))
 	le.rateBox.Enable(le.bOverride)
 
+	le.opacityBox.Entry.SetText(strconv.FormatInt(int64(le.layer.OpacityPercent()), 10))
+	le.brightnessBox.Entry.SetText(strconv.FormatInt(int64(le.layer.BrightnessPercent()), 10))
+	le.attackBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Envelope.Attack), 10))
+	le.sustainBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Envelope.Sustain), 10))
+	le.decayBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Envelope.Decay), 10))
+	le.speedBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Speed), 10))
+	le.seedBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Noise.Seed), 10))
+	le.scaleBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Noise.Scale), 10))
+	le.driftBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Noise.Drift), 10))
+	le.spawnBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Particles.Spawn), 10))
+	le.lifetimeBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Particles.Lifetime), 10))
+	le.tailBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Particles.Tail), 10))
+	le.sparkSeedBox.Entry.SetText(strconv.FormatInt(int64(le.layer.Particles.Seed), 10))
+
 	le.imageLabel.SetText(imageName(le.layer.ImageName))
+	le.modulatorLabel.SetText(strconv.Itoa(len(le.fields.Modulators)))
 
-	for i, p := range le.patches {
-		if i < len(le.fields.Colors) {
-			p.SetHSVColor(le.fields.Colors[i])
-		} else {
-			p.SetUnused(true)
-		}
-	}
+	setPatches(le.patches, le.fields.Colors, le.fields.Positions)
 	le.isEditing = true
 }
 
@@ -232,13 +542,33 @@ func (le *LayerEditor) apply(frame *glow.Frame) {
 }
 
 func (le *LayerEditor) setColors() {
-	var colors []glow.HSV = make([]glow.HSV, 0)
-	for _, p := range le.patches {
-		if !p.Unused() {
-			colors = append(colors, p.GetHSVColor())
-		}
+	le.fields.Colors, le.fields.Positions = patchColors(le.patches)
+}
+
+func (le *LayerEditor) setPaletteOptions() {
+	options := append([]string{text.NoPaletteLabel.String()}, le.effect.ListPalettes()...)
+	le.selectPalette.Options = options
+	current, _ := le.fields.Palette.Get()
+	if current == "" {
+		current = text.NoPaletteLabel.String()
+	}
+	le.selectPalette.SetSelected(current)
+}
+
+// loadPalette shows the colors of the layer's palette
+// unless the layer overrides them.
+func (le *LayerEditor) loadPalette() {
+	title, _ := le.fields.Palette.Get()
+	override, _ := le.fields.Override.Get()
+	if title == "" || override {
+		return
+	}
+	palette, err := le.effect.ReadPalette(title)
+	if err != nil {
+		fyne.LogError(title, err)
+		return
 	}
-	le.fields.Colors = colors
+	setPatches(le.patches, palette.Colors, palette.Positions)
 }
 
 func imageName(path string) (name string) {
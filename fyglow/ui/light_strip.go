@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"gglow/glow"
 	"image"
 	"image/color"
 
@@ -12,8 +13,11 @@ import (
 const (
 	minStripWidth  float32 = 240
 	minStripHeight float32 = 240
+	whiteScale             = 3
 )
 
+var _ glow.RGBWLight = (*LightStrip)(nil)
+
 type LightStrip struct {
 	widget.BaseWidget
 	background *canvas.Rectangle
@@ -23,19 +27,52 @@ type LightStrip struct {
 	length     int
 	rows       int
 	cols       int
+	wiring     glow.Wiring
+	wires      []*canvas.Line
+	pixelMap   *glow.PixelMap
+	occupied   []bool
+	white      *glow.White
+	whites     *image.NRGBA
+	whiteImage *canvas.Image
+	colors     []color.NRGBA
 }
 
-func NewLightStrip(length, rows int, background color.Color) *LightStrip {
+func NewLightStrip(length, rows int, background color.Color,
+	wiring glow.Wiring) *LightStrip {
 	strip := &LightStrip{
 		background: canvas.NewRectangle(color.Black),
 		length:     length,
 		rows:       rows,
 		cols:       length / rows,
+		wiring:     wiring,
 	}
 
 	strip.colorOff = color.NRGBA{48, 24, 16, 255}
 	strip.buildLights()
+	strip.buildWires()
 	strip.image = canvas.NewImageFromImage(strip.lights)
+	strip.buildWhites()
+	strip.ExtendBaseWidget(strip)
+	return strip
+}
+
+// NewMappedLightStrip draws only the lights of the pixel map
+// at their positions on its virtual grid.
+func NewMappedLightStrip(pm *glow.PixelMap, background color.Color) *LightStrip {
+	strip := &LightStrip{
+		background: canvas.NewRectangle(color.Black),
+		length:     int(pm.Length()),
+		rows:       int(pm.Rows()),
+		cols:       int(pm.Columns()),
+		pixelMap:   pm,
+		occupied:   pm.Occupied(),
+	}
+
+	strip.colorOff = color.NRGBA{48, 24, 16, 255}
+	strip.buildLights()
+	strip.buildWires()
+	strip.image = canvas.NewImageFromImage(strip.lights)
+	strip.buildWhites()
 	strip.ExtendBaseWidget(strip)
 	return strip
 }
@@ -46,27 +83,79 @@ func (strip *LightStrip) Length() uint16 {
 func (strip *LightStrip) Rows() uint16 {
 	return uint16(strip.rows)
 }
+func (strip *LightStrip) Wiring() glow.Wiring {
+	return strip.wiring
+}
+
+// SetWhite shows the white channels extracted for RGBW lights,
+// or only red, green and blue when white is nil.
+func (strip *LightStrip) SetWhite(white *glow.White) {
+	strip.white = white
+	strip.colors = nil
+	if white != nil {
+		white.Setup()
+		if white.Format != glow.FormatRGB {
+			strip.colors = make([]color.NRGBA, strip.cols*strip.rows)
+		}
+	}
+	strip.TurnOff()
+}
 
 // glow.Light interface
 func (strip *LightStrip) Get(i uint16) color.NRGBA {
-	var x, y int = int(i) % strip.cols, int(i) / strip.cols
+	if strip.colors != nil && int(i) < len(strip.colors) {
+		return strip.colors[i]
+	}
+	x, y := strip.wiring.Locate(i, uint16(strip.cols), uint16(strip.rows))
 	n := strip.lights.NRGBAAt(x, y)
 	return n
 }
 
 // glow.Light interface
 func (strip *LightStrip) Set(i uint16, c color.NRGBA) {
-	var x, y int = int(i) % strip.cols, int(i) / strip.cols
+	if strip.occupied != nil && !strip.occupied[i] {
+		return
+	}
+	if strip.colors != nil && int(i) < len(strip.colors) {
+		strip.colors[i] = c
+		strip.SetRGBW(i, strip.white.Extract(c))
+		return
+	}
+	x, y := strip.wiring.Locate(i, uint16(strip.cols), uint16(strip.rows))
 	strip.lights.SetNRGBA(x, y, c)
 }
 
+// glow.RGBWLight interface. The white channels show as dots
+// in the tint of each white LED over the red, green and blue.
+func (strip *LightStrip) SetRGBW(i uint16, w glow.RGBW) {
+	if strip.occupied != nil && !strip.occupied[i] {
+		return
+	}
+	x, y := strip.wiring.Locate(i, uint16(strip.cols), uint16(strip.rows))
+	strip.lights.SetNRGBA(x, y, color.NRGBA{w.R, w.G, w.B, 255})
+	if strip.white == nil {
+		return
+	}
+	cool, warm := strip.white.Tint(false), strip.white.Tint(true)
+	cool.A, warm.A = w.W, w.WW
+	strip.whites.SetNRGBA(x*whiteScale+1, y*whiteScale+1, cool)
+	strip.whites.SetNRGBA(x*whiteScale+1, y*whiteScale+2, warm)
+}
+
 func (strip *LightStrip) TurnOff() {
-	c := strip.colorOff
 	for x := 0; x < strip.cols; x++ {
 		for y := 0; y < strip.rows; y++ {
+			c := strip.colorOff
+			if strip.occupied != nil && !strip.occupied[y*strip.cols+x] {
+				c = color.NRGBA{}
+			}
 			strip.lights.SetNRGBA(x, y, c)
 		}
 	}
+	if strip.whites != nil {
+		clear(strip.whites.Pix)
+	}
+	clear(strip.colors)
 }
 
 func (strip *LightStrip) buildLights() {
@@ -75,6 +164,52 @@ func (strip *LightStrip) buildLights() {
 	strip.TurnOff()
 }
 
+// buildWhites makes the layer of white dots, a cell of
+// whiteScale pixels square over each light.
+func (strip *LightStrip) buildWhites() {
+	rect := image.Rect(0, 0, strip.cols*whiteScale, strip.rows*whiteScale)
+	strip.whites = image.NewNRGBA(rect)
+	strip.whiteImage = canvas.NewImageFromImage(strip.whites)
+	strip.whiteImage.ScaleMode = canvas.ImageScalePixels
+}
+
+// buildWires traces the physical wiring between lights
+// unless it simply follows the rows.
+func (strip *LightStrip) buildWires() {
+	strip.wires = nil
+	count := strip.cols * strip.rows
+	if strip.pixelMap != nil {
+		count = int(strip.pixelMap.Count())
+	} else if strip.wiring == glow.Progressive {
+		return
+	}
+	wireColor := color.NRGBA{255, 255, 255, 48}
+	for i := 1; i < count; i++ {
+		line := canvas.NewLine(wireColor)
+		line.StrokeWidth = 1
+		strip.wires = append(strip.wires, line)
+	}
+}
+
+func (strip *LightStrip) layoutWires(size fyne.Size) {
+	w := size.Width / float32(strip.cols)
+	h := size.Height / float32(strip.rows)
+	centre := func(i int) fyne.Position {
+		x, y := strip.wiring.Locate(uint16(i),
+			uint16(strip.cols), uint16(strip.rows))
+		if strip.pixelMap != nil {
+			offset := int(strip.pixelMap.Offset(uint16(i)))
+			x, y = offset%strip.cols, offset/strip.cols
+		}
+		return fyne.NewPos((float32(x)+0.5)*w, (float32(y)+0.5)*h)
+	}
+	for i, line := range strip.wires {
+		line.Position1 = centre(i)
+		line.Position2 = centre(i + 1)
+		line.Refresh()
+	}
+}
+
 type lightStripRenderer struct {
 	objects []fyne.CanvasObject
 	strip   *LightStrip
@@ -82,9 +217,12 @@ type lightStripRenderer struct {
 
 func (strip *LightStrip) CreateRenderer() fyne.WidgetRenderer {
 	lsr := lightStripRenderer{
-		objects: []fyne.CanvasObject{strip.background, strip.image},
+		objects: []fyne.CanvasObject{strip.background, strip.image, strip.whiteImage},
 		strip:   strip,
 	}
+	for _, line := range strip.wires {
+		lsr.objects = append(lsr.objects, line)
+	}
 
 	return &lsr
 }
@@ -94,6 +232,9 @@ func (lsr *lightStripRenderer) Layout(size fyne.Size) {
 	lsr.strip.background.Refresh()
 	lsr.strip.image.Resize(size)
 	lsr.strip.image.Refresh()
+	lsr.strip.whiteImage.Resize(size)
+	lsr.strip.whiteImage.Refresh()
+	lsr.strip.layoutWires(size)
 }
 
 func (lsr *lightStripRenderer) MinSize() (size fyne.Size) {
@@ -3,6 +3,7 @@ package ui
 import (
 	"gglow/fyglow/effectio"
 	"gglow/glow"
+	"gglow/settings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -23,6 +24,7 @@ type LightStripPlayer struct {
 	resetButton     *widget.ToolbarAction
 	stopButton      *widget.ToolbarAction
 	layoutButton    *widget.ToolbarAction
+	parityButton    *ButtonItem
 
 	stopChan     chan int
 	stepChan     chan int
@@ -32,9 +34,13 @@ type LightStripPlayer struct {
 	stripChan    chan int
 	intervalChan chan int
 	frameChan    chan *glow.Frame
+	parityChan   chan bool
+	sequenceChan chan *glow.Sequence
 
 	isPlaying bool
 	isActive  bool
+	// parity renders with the firmware's integer math
+	parity bool
 }
 
 func NewLightStripPlayer(sourceStrip binding.Untyped, effect *effectio.EffectIo,
@@ -51,6 +57,8 @@ func NewLightStripPlayer(sourceStrip binding.Untyped, effect *effectio.EffectIo,
 		stripChan:    make(chan int),
 		intervalChan: make(chan int),
 		frameChan:    make(chan *glow.Frame),
+		parityChan:   make(chan bool),
+		sequenceChan: make(chan *glow.Sequence),
 	}
 
 	sb.playPauseButton = NewButtonItem(
@@ -64,12 +72,18 @@ func NewLightStripPlayer(sourceStrip binding.Untyped, effect *effectio.EffectIo,
 		lightStripLayout.CustomDialog.Show()
 	})
 
+	sb.parityButton = NewButtonItem(
+		widget.NewButtonWithIcon("", theme.ComputerIcon(), sb.ToggleParity))
+	sb.parity = fyne.CurrentApp().Preferences().Bool(settings.StripParity.String())
+	sb.showParity()
+
 	sb.Toolbar = widget.NewToolbar(
 		sb.playPauseButton,
 		sb.stepButton,
 		sb.resetButton,
 		sb.stopButton,
 		sb.layoutButton,
+		sb.parityButton,
 	)
 
 	sb.strip = sb.getStrip()
@@ -125,6 +139,38 @@ func (sb *LightStripPlayer) Reset() {
 	sb.resetChan <- 0
 }
 
+// ToggleParity switches between the editor's colors and the exact
+// integer colors the device shows.
+func (sb *LightStripPlayer) ToggleParity() {
+	sb.parity = !sb.parity
+	fyne.CurrentApp().Preferences().SetBool(settings.StripParity.String(), sb.parity)
+	sb.showParity()
+	sb.run()
+	sb.parityChan <- sb.parity
+}
+
+func (sb *LightStripPlayer) showParity() {
+	sb.parityButton.Importance = widget.LowImportance
+	if sb.parity {
+		sb.parityButton.Importance = widget.HighImportance
+	}
+	sb.parityButton.Refresh()
+}
+
+// PlayPlaylist previews playlist on the strip until the effect changes.
+func (sb *LightStripPlayer) PlayPlaylist(playlist *glow.Playlist) error {
+	seq, err := sb.effect.NewSequence(playlist)
+	if err != nil {
+		return err
+	}
+	sb.run()
+	sb.sequenceChan <- seq
+	if !sb.isPlaying {
+		sb.play()
+	}
+	return nil
+}
+
 func (sb *LightStripPlayer) pause() {
 	sb.playPauseButton.SetIcon(theme.MediaPlayIcon())
 	sb.isPlaying = false
@@ -160,8 +206,11 @@ func (sb *LightStripPlayer) startSpinner() {
 func (sb *LightStripPlayer) spin() {
 	var (
 		isSpinning bool
+		parity     = sb.parity
 		frame      *glow.Frame
+		seq        *glow.Sequence
 		err        error
+		last       time.Time
 	)
 
 	copyFrame := func(source *glow.Frame) {
@@ -172,11 +221,37 @@ func (sb *LightStripPlayer) spin() {
 			reason += " " + err.Error()
 			panic(reason)
 		}
+		frame.Wiring = sb.strip.Wiring()
 		frame.Setup(sb.strip.Length(), sb.strip.Rows())
 		if frame.Interval == 0 {
 			frame.Interval = glow.DefaultInterval
 		}
-		frame.LoadImages()
+		if err = frame.LoadImages(); err != nil {
+			fyne.LogError("copyFrame LoadImages", err)
+		}
+		frame.SetParity(parity)
+	}
+
+	setupSequence := func() {
+		seq.SetWiring(sb.strip.Wiring())
+		err = seq.Setup(sb.strip.Length(), sb.strip.Rows())
+		if err != nil {
+			fyne.LogError("setupSequence", err)
+			seq = nil
+			return
+		}
+		if err = seq.LoadImages(); err != nil {
+			fyne.LogError("setupSequence LoadImages", err)
+		}
+		seq.SetParity(parity)
+	}
+
+	spinOnce := func() {
+		if seq != nil {
+			seq.Spin(sb.strip)
+			return
+		}
+		frame.Spin(sb.strip)
 	}
 
 	copyFrame(sb.effect.GetFrame())
@@ -189,32 +264,60 @@ func (sb *LightStripPlayer) spin() {
 
 		case <-sb.startChan:
 			isSpinning = true
+			last = time.Now()
 
 		case <-sb.pauseChan:
 			isSpinning = false
 
 		case <-sb.stepChan:
 			isSpinning = false
-			frame.Spin(sb.strip)
+			spinOnce()
 
 		case f := <-sb.frameChan:
+			seq = nil
 			copyFrame(f)
 			frame.Spin(sb.strip)
 
+		case seq = <-sb.sequenceChan:
+			setupSequence()
+			spinOnce()
+
+		case parity = <-sb.parityChan:
+			frame.SetParity(parity)
+			if seq != nil {
+				seq.SetParity(parity)
+			}
+			spinOnce()
+
 		case <-sb.stripChan:
 			sb.strip = sb.getStrip()
 			copyFrame(sb.effect.GetFrame())
-			frame.Spin(sb.strip)
+			if seq != nil {
+				setupSequence()
+			}
+			spinOnce()
 
 		case <-sb.resetChan:
+			seq = nil
 			copyFrame(sb.effect.GetFrame())
 			frame.Spin(sb.strip)
 
 		default:
+			tick := frame.Tick()
 			if isSpinning {
-				frame.Spin(sb.strip)
+				now := time.Now()
+				elapsed := uint32(now.Sub(last).Milliseconds())
+				if seq != nil {
+					seq.SpinFor(sb.strip, elapsed)
+				} else {
+					frame.SpinFor(sb.strip, elapsed)
+				}
+				last = now
+			}
+			if seq != nil {
+				tick = seq.Tick()
 			}
-			time.Sleep(time.Duration(frame.Interval) * time.Millisecond)
+			time.Sleep(time.Duration(tick) * time.Millisecond)
 		}
 	}
 
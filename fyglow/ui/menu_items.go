@@ -3,6 +3,7 @@ package ui
 import (
 	"gglow/fyglow/effectio"
 	"gglow/fyglow/resource"
+	"gglow/glow"
 	"gglow/text"
 	"os"
 
@@ -24,6 +25,8 @@ const (
 	MenuLayerAdd
 	MenuLayerInsert
 	MenuLayerRemove
+	MenuPalettes
+	MenuPlaylists
 	MenuFileshare
 	MenuQuit
 	MENU_ITEM_COUNT
@@ -36,6 +39,10 @@ func BuildMenu(effect *effectio.EffectIo, ui *Ui) (menu *fyne.Menu) {
 	addFolder := NewFolderDialog(effect, ui.window)
 	addEffect := NewEffectDialog(effect, ui.window)
 	expWizard := NewExportWizard(effect, ui.window)
+	palettes := NewPaletteDialog(effect, ui.window)
+	playlists := NewPlaylistDialog(effect, ui.window, func(playlist *glow.Playlist) error {
+		return ui.stripPlayer.PlayPlaylist(playlist)
+	})
 
 	MenuItems = [MENU_ITEM_COUNT]*fyne.MenuItem{
 		{
@@ -99,6 +106,16 @@ func BuildMenu(effect *effectio.EffectIo, ui *Ui) (menu *fyne.Menu) {
 			Icon:   theme.ContentRemoveIcon(),
 			Action: effect.RemoveLayer,
 		},
+		{
+			Label:  text.PalettesLabel.String(),
+			Icon:   theme.ColorPaletteIcon(),
+			Action: palettes.Start,
+		},
+		{
+			Label:  text.PlaylistsLabel.String(),
+			Icon:   theme.MediaMusicIcon(),
+			Action: playlists.Start,
+		},
 		{
 			Label:    text.ExportLabel.String(),
 			Icon:     resource.IconFileShare(),
@@ -140,6 +157,9 @@ func BuildMenu(effect *effectio.EffectIo, ui *Ui) (menu *fyne.Menu) {
 		&fyne.MenuItem{IsSeparator: true},
 		MenuItems[MenuLayers],
 		&fyne.MenuItem{IsSeparator: true},
+		MenuItems[MenuPalettes],
+		MenuItems[MenuPlaylists],
+		&fyne.MenuItem{IsSeparator: true},
 		MenuItems[MenuFileshare],
 		&fyne.MenuItem{IsSeparator: true},
 		MenuItems[MenuQuit],
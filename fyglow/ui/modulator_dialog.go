@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"gglow/fyglow/effectio"
+	"gglow/glow"
+	"gglow/text"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ModulatorDialog edits the modulators of the layer in fields.
+// Changes apply to the fields as they are made.
+type ModulatorDialog struct {
+	*dialog.CustomDialog
+	fields  *effectio.LayerFields
+	changed func()
+
+	entry int
+	list  *widget.List
+
+	selectTarget   *widget.Select
+	selectWaveform *widget.Select
+	rateEntry      *widget.Entry
+	depthEntry     *widget.Entry
+	phaseEntry     *widget.Entry
+	stopEntry      *widget.Entry
+
+	dropButton *widget.Button
+}
+
+func NewModulatorDialog(fields *effectio.LayerFields, window fyne.Window,
+	changed func()) *ModulatorDialog {
+	md := &ModulatorDialog{
+		fields:  fields,
+		changed: changed,
+		entry:   -1,
+	}
+
+	md.list = widget.NewList(
+		func() int { return len(md.fields.Modulators) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(modulatorName(&md.fields.Modulators[id]))
+		})
+	md.list.OnSelected = md.selectEntry
+
+	md.selectTarget = widget.NewSelect(text.ModTargetLabels, func(s string) {
+		md.update(func(mod *glow.Modulator) {
+			mod.Target = glow.ModTarget(md.selectTarget.SelectedIndex())
+		})
+	})
+	md.selectWaveform = widget.NewSelect(text.WaveformLabels, func(s string) {
+		md.update(func(mod *glow.Modulator) {
+			mod.Waveform = glow.Waveform(md.selectWaveform.SelectedIndex())
+		})
+	})
+	md.rateEntry = md.newEntry(`^[0-9]+$`, func(mod *glow.Modulator, s string) {
+		if value, err := strconv.ParseUint(s, 10, 32); err == nil {
+			mod.Rate = uint32(value)
+		}
+	})
+	md.depthEntry = md.newEntry(`^-?[0-9]+$`, func(mod *glow.Modulator, s string) {
+		if value, err := strconv.ParseInt(s, 10, 16); err == nil {
+			mod.Depth = int16(value)
+		}
+	})
+	md.phaseEntry = md.newEntry(`^[0-9]+$`, func(mod *glow.Modulator, s string) {
+		if value, err := strconv.ParseUint(s, 10, 16); err == nil {
+			mod.Phase = uint16(value) % 360
+		}
+	})
+	md.stopEntry = md.newEntry(`^[0-9]+$`, func(mod *glow.Modulator, s string) {
+		if value, err := strconv.ParseUint(s, 10, 16); err == nil {
+			mod.Stop = uint16(value)
+		}
+	})
+
+	addButton := widget.NewButtonWithIcon("", theme.ContentAddIcon(), md.addEntry)
+	md.dropButton = widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), md.dropEntry)
+	closeButton := widget.NewButtonWithIcon(text.CloseLabel.String(),
+		theme.CancelIcon(), func() { md.CustomDialog.Hide() })
+
+	form := container.New(layout.NewFormLayout(),
+		widget.NewLabel(text.TargetLabel.String()), md.selectTarget,
+		widget.NewLabel(text.WaveformLabel.String()), md.selectWaveform,
+		widget.NewLabel(text.RateLabel.String()), md.rateEntry,
+		widget.NewLabel(text.DepthLabel.String()), md.depthEntry,
+		widget.NewLabel(text.PhaseLabel.String()), md.phaseEntry,
+		widget.NewLabel(text.StopLabel.String()), md.stopEntry)
+	tools := container.NewHBox(addButton, md.dropButton)
+	content := container.NewBorder(tools, form, nil, nil, md.list)
+
+	md.CustomDialog = dialog.NewCustomWithoutButtons(text.ModulatorsLabel.String(), content, window)
+	md.CustomDialog.SetButtons([]fyne.CanvasObject{closeButton})
+	md.CustomDialog.Resize(fyne.NewSize(theme.IconInlineSize()*20, theme.IconInlineSize()*24))
+	return md
+}
+
+func (md *ModulatorDialog) Start() {
+	md.list.UnselectAll()
+	md.list.Refresh()
+	md.selectEntry(-1)
+	md.CustomDialog.Show()
+}
+
+func (md *ModulatorDialog) newEntry(pattern string,
+	set func(*glow.Modulator, string)) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.Validator = validation.NewRegexp(pattern, "")
+	entry.OnChanged = func(s string) {
+		md.update(func(mod *glow.Modulator) { set(mod, s) })
+	}
+	return entry
+}
+
+// update changes the selected modulator unless the form is being filled.
+func (md *ModulatorDialog) update(change func(*glow.Modulator)) {
+	if md.entry < 0 {
+		return
+	}
+	mod := &md.fields.Modulators[md.entry]
+	before := *mod
+	change(mod)
+	if *mod != before {
+		md.list.RefreshItem(md.entry)
+		md.changed()
+	}
+}
+
+func (md *ModulatorDialog) selectEntry(id widget.ListItemID) {
+	md.entry = -1
+	entries := []fyne.Disableable{md.selectTarget, md.selectWaveform, md.rateEntry,
+		md.depthEntry, md.phaseEntry, md.stopEntry, md.dropButton}
+	if id < 0 {
+		md.selectTarget.ClearSelected()
+		md.selectWaveform.ClearSelected()
+		for _, entry := range []*widget.Entry{md.rateEntry, md.depthEntry,
+			md.phaseEntry, md.stopEntry} {
+			entry.SetText("")
+		}
+		for _, entry := range entries {
+			entry.Disable()
+		}
+		return
+	}
+	mod := md.fields.Modulators[id]
+	md.selectTarget.SetSelectedIndex(int(mod.Target))
+	md.selectWaveform.SetSelectedIndex(int(mod.Waveform))
+	md.rateEntry.SetText(strconv.FormatUint(uint64(mod.Rate), 10))
+	md.depthEntry.SetText(strconv.FormatInt(int64(mod.Depth), 10))
+	md.phaseEntry.SetText(strconv.FormatUint(uint64(mod.Phase), 10))
+	md.stopEntry.SetText(strconv.FormatUint(uint64(mod.Stop), 10))
+	for _, entry := range entries {
+		entry.Enable()
+	}
+	md.entry = id
+}
+
+func (md *ModulatorDialog) addEntry() {
+	md.fields.Modulators = append(md.fields.Modulators, glow.Modulator{
+		Target: glow.ModHue, Rate: 2000, Depth: 30})
+	md.list.Refresh()
+	md.list.Select(len(md.fields.Modulators) - 1)
+	md.changed()
+}
+
+func (md *ModulatorDialog) dropEntry() {
+	id := md.entry
+	if id < 0 {
+		return
+	}
+	md.fields.Modulators = append(md.fields.Modulators[:id], md.fields.Modulators[id+1:]...)
+	md.list.UnselectAll()
+	md.list.Refresh()
+	md.selectEntry(-1)
+	md.changed()
+}
+
+func modulatorName(mod *glow.Modulator) string {
+	name := fmt.Sprintf("%s %s", text.WaveformID(mod.Waveform), text.ModTargetID(mod.Target))
+	if mod.IsColor() {
+		name += fmt.Sprintf(" %d", mod.Stop)
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"gglow/fyglow/effectio"
+	"gglow/glow"
+	"gglow/text"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PaletteDialog browses the shared palettes and edits their colors.
+// New palettes start with the colors of the current layer.
+type PaletteDialog struct {
+	*dialog.CustomDialog
+	effect *effectio.EffectIo
+	window fyne.Window
+
+	titles   []string
+	selected string
+	list     *widget.List
+	patches  []*ColorPatch
+
+	addDialog    *SimpleDialog
+	saveButton   *widget.Button
+	removeButton *widget.Button
+}
+
+func NewPaletteDialog(effect *effectio.EffectIo, window fyne.Window) *PaletteDialog {
+	pd := &PaletteDialog{
+		effect: effect,
+		window: window,
+		titles: []string{},
+	}
+
+	pd.list = widget.NewList(
+		func() int { return len(pd.titles) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(pd.titles[id])
+		})
+	pd.list.OnSelected = func(id widget.ListItemID) {
+		pd.selectPalette(pd.titles[id])
+	}
+
+	pd.patches = make([]*ColorPatch, effectio.MaxLayerColors)
+	patchBox := container.NewHBox()
+	for i := range pd.patches {
+		patch := NewColorPatch()
+		patch.SetOnTapped(pd.editColor(patch))
+		pd.patches[i] = patch
+		patchBox.Add(patch)
+	}
+
+	pd.addDialog = NewSimpleDialog(effect, window,
+		text.PaletteLabel.String(), text.PaletteLabel.String())
+	pd.addDialog.NameEntry.Validator = validation.NewAllStrings(pd.validateTitle)
+	pd.addDialog.Apply = pd.add
+
+	addButton := widget.NewButtonWithIcon(text.NewLabel.String(),
+		theme.ContentAddIcon(), pd.addDialog.Start)
+	pd.saveButton = widget.NewButtonWithIcon(text.SaveLabel.String(),
+		theme.DocumentSaveIcon(), pd.save)
+	pd.removeButton = widget.NewButtonWithIcon(text.RemoveLabel.String(),
+		theme.DeleteIcon(), pd.remove)
+	closeButton := widget.NewButtonWithIcon(text.CloseLabel.String(),
+		theme.CancelIcon(), func() { pd.CustomDialog.Hide() })
+
+	content := container.NewBorder(nil, patchBox, nil, nil, pd.list)
+	pd.CustomDialog = dialog.NewCustomWithoutButtons(text.PalettesLabel.String(), content, window)
+	pd.CustomDialog.SetButtons([]fyne.CanvasObject{closeButton, addButton,
+		pd.saveButton, pd.removeButton})
+	pd.CustomDialog.Resize(fyne.NewSize(theme.IconInlineSize()*16, theme.IconInlineSize()*16))
+	return pd
+}
+
+func (pd *PaletteDialog) Start() {
+	pd.refresh()
+	pd.CustomDialog.Show()
+}
+
+func (pd *PaletteDialog) refresh() {
+	pd.titles = pd.effect.ListPalettes()
+	pd.list.UnselectAll()
+	pd.list.Refresh()
+	pd.selectPalette("")
+}
+
+func (pd *PaletteDialog) selectPalette(title string) {
+	pd.selected = title
+	if title == "" {
+		setPatches(pd.patches, nil, nil)
+		pd.saveButton.Disable()
+		pd.removeButton.Disable()
+		return
+	}
+
+	palette, err := pd.effect.ReadPalette(title)
+	if err != nil {
+		fyne.LogError(title, err)
+		return
+	}
+	setPatches(pd.patches, palette.Colors, palette.Positions)
+	pd.saveButton.Enable()
+	pd.removeButton.Enable()
+}
+
+func (pd *PaletteDialog) editColor(patch *ColorPatch) func() {
+	return func() {
+		if pd.selected == "" {
+			return
+		}
+		chroma, index := patchChroma(pd.patches, patch, glow.InterpolateHSV)
+		ce := NewColorPatchEditor(patch, chroma, index, pd.window, func() {})
+		ce.Show()
+	}
+}
+
+func (pd *PaletteDialog) validateTitle(s string) error {
+	err := pd.effect.ValidatePaletteName(s)
+	if err != nil {
+		pd.addDialog.ApplyButton.Disable()
+		return err
+	}
+	pd.addDialog.ApplyButton.Enable()
+	return nil
+}
+
+func (pd *PaletteDialog) add() {
+	title, _ := pd.addDialog.Name.Get()
+	chroma := pd.effect.GetCurrentLayer().Chroma
+	palette := &glow.Palette{
+		Title:     title,
+		Colors:    append([]glow.HSV{}, chroma.Colors...),
+		Positions: append([]uint16{}, chroma.Positions...),
+	}
+	err := pd.effect.WritePalette(palette)
+	if err != nil {
+		fyne.LogError(title, err)
+		return
+	}
+	pd.refresh()
+	for i, t := range pd.titles {
+		if t == title {
+			pd.list.Select(i)
+		}
+	}
+}
+
+func (pd *PaletteDialog) save() {
+	colors, positions := patchColors(pd.patches)
+	palette := &glow.Palette{
+		Title:     pd.selected,
+		Colors:    colors,
+		Positions: positions,
+	}
+	err := pd.effect.WritePalette(palette)
+	if err != nil {
+		fyne.LogError(pd.selected, err)
+	}
+}
+
+func (pd *PaletteDialog) remove() {
+	title := pd.selected
+	dialog.ShowConfirm(text.RemoveLabel.String(), title, func(ok bool) {
+		if !ok {
+			return
+		}
+		err := pd.effect.RemovePalette(title)
+		if err != nil {
+			fyne.LogError(title, err)
+		}
+		pd.refresh()
+	}, pd.window)
+}
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"fmt"
+	"gglow/fyglow/effectio"
+	"gglow/glow"
+	"gglow/text"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PlaylistDialog builds playlists from the effects in turn and plays
+// them on the strip. Add appends the current effect.
+type PlaylistDialog struct {
+	*dialog.CustomDialog
+	effect *effectio.EffectIo
+	window fyne.Window
+	play   func(*glow.Playlist) error
+
+	titles   []string
+	playlist *glow.Playlist
+	entry    int
+	list     *widget.List
+	entries  *widget.List
+
+	durationEntry    *widget.Entry
+	selectTransition *widget.Select
+	fadeEntry        *widget.Entry
+
+	addDialog    *SimpleDialog
+	addButton    *widget.Button
+	dropButton   *widget.Button
+	saveButton   *widget.Button
+	removeButton *widget.Button
+	playButton   *widget.Button
+}
+
+func NewPlaylistDialog(effect *effectio.EffectIo, window fyne.Window,
+	play func(*glow.Playlist) error) *PlaylistDialog {
+	pd := &PlaylistDialog{
+		effect: effect,
+		window: window,
+		play:   play,
+		titles: []string{},
+		entry:  -1,
+	}
+
+	pd.list = widget.NewList(
+		func() int { return len(pd.titles) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(pd.titles[id])
+		})
+	pd.list.OnSelected = func(id widget.ListItemID) {
+		pd.selectPlaylist(pd.titles[id])
+	}
+
+	pd.entries = widget.NewList(
+		func() int {
+			if pd.playlist == nil {
+				return 0
+			}
+			return len(pd.playlist.Entries)
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			entry := pd.playlist.Entries[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("%s/%s %s",
+				entry.Folder, entry.Title, text.TransitionID(entry.Transition)))
+		})
+	pd.entries.OnSelected = pd.selectEntry
+
+	pd.durationEntry = widget.NewEntry()
+	pd.durationEntry.Validator = validation.NewRegexp(`^[0-9]+$`, "")
+	pd.durationEntry.OnChanged = func(s string) {
+		if value, err := strconv.ParseUint(s, 10, 32); err == nil && pd.entry >= 0 {
+			pd.playlist.Entries[pd.entry].Duration = uint32(value)
+		}
+	}
+	pd.selectTransition = widget.NewSelect(text.TransitionLabels, func(s string) {
+		if pd.entry >= 0 {
+			transition := glow.Transition(pd.selectTransition.SelectedIndex())
+			pd.playlist.Entries[pd.entry].Transition = transition
+			pd.entries.RefreshItem(pd.entry)
+		}
+	})
+	pd.fadeEntry = widget.NewEntry()
+	pd.fadeEntry.Validator = validation.NewRegexp(`^[0-9]+$`, "")
+	pd.fadeEntry.OnChanged = func(s string) {
+		if value, err := strconv.ParseUint(s, 10, 32); err == nil && pd.entry >= 0 {
+			pd.playlist.Entries[pd.entry].Fade = uint32(value)
+		}
+	}
+
+	pd.addDialog = NewSimpleDialog(effect, window,
+		text.PlaylistLabel.String(), text.PlaylistLabel.String())
+	pd.addDialog.NameEntry.Validator = validation.NewAllStrings(pd.validateTitle)
+	pd.addDialog.Apply = pd.add
+
+	pd.addButton = widget.NewButtonWithIcon("", theme.ContentAddIcon(), pd.addEntry)
+	pd.dropButton = widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), pd.dropEntry)
+
+	newButton := widget.NewButtonWithIcon(text.NewLabel.String(),
+		theme.ContentAddIcon(), pd.addDialog.Start)
+	pd.saveButton = widget.NewButtonWithIcon(text.SaveLabel.String(),
+		theme.DocumentSaveIcon(), pd.save)
+	pd.removeButton = widget.NewButtonWithIcon(text.RemoveLabel.String(),
+		theme.DeleteIcon(), pd.remove)
+	pd.playButton = widget.NewButtonWithIcon(text.PlayLabel.String(),
+		theme.MediaPlayIcon(), pd.playPlaylist)
+	closeButton := widget.NewButtonWithIcon(text.CloseLabel.String(),
+		theme.CancelIcon(), func() { pd.CustomDialog.Hide() })
+
+	form := container.New(layout.NewFormLayout(),
+		widget.NewLabel(text.DurationLabel.String()), pd.durationEntry,
+		widget.NewLabel(text.TransitionLabel.String()), pd.selectTransition,
+		widget.NewLabel(text.FadeLabel.String()), pd.fadeEntry)
+	tools := container.NewHBox(pd.addButton, pd.dropButton)
+	entries := container.NewBorder(tools, form, nil, nil, pd.entries)
+
+	content := container.NewHSplit(pd.list, entries)
+	content.Offset = .35
+	pd.CustomDialog = dialog.NewCustomWithoutButtons(text.PlaylistsLabel.String(), content, window)
+	pd.CustomDialog.SetButtons([]fyne.CanvasObject{closeButton, newButton,
+		pd.saveButton, pd.removeButton, pd.playButton})
+	pd.CustomDialog.Resize(fyne.NewSize(theme.IconInlineSize()*32, theme.IconInlineSize()*20))
+	return pd
+}
+
+func (pd *PlaylistDialog) Start() {
+	pd.refresh()
+	pd.CustomDialog.Show()
+}
+
+func (pd *PlaylistDialog) refresh() {
+	pd.titles = pd.effect.ListPlaylists()
+	pd.list.UnselectAll()
+	pd.list.Refresh()
+	pd.selectPlaylist("")
+}
+
+func (pd *PlaylistDialog) selectPlaylist(title string) {
+	pd.playlist = nil
+	if title != "" {
+		playlist, err := pd.effect.ReadPlaylist(title)
+		if err != nil {
+			fyne.LogError(title, err)
+		} else {
+			pd.playlist = playlist
+		}
+	}
+	pd.entries.UnselectAll()
+	pd.entries.Refresh()
+	pd.selectEntry(-1)
+
+	if pd.playlist == nil {
+		pd.addButton.Disable()
+		pd.saveButton.Disable()
+		pd.removeButton.Disable()
+		pd.playButton.Disable()
+		return
+	}
+	pd.addButton.Enable()
+	pd.saveButton.Enable()
+	pd.removeButton.Enable()
+	pd.playButton.Enable()
+}
+
+func (pd *PlaylistDialog) selectEntry(id widget.ListItemID) {
+	pd.entry = -1
+	if id < 0 {
+		pd.durationEntry.SetText("")
+		pd.selectTransition.ClearSelected()
+		pd.fadeEntry.SetText("")
+		pd.durationEntry.Disable()
+		pd.selectTransition.Disable()
+		pd.fadeEntry.Disable()
+		pd.dropButton.Disable()
+		return
+	}
+	entry := pd.playlist.Entries[id]
+	pd.durationEntry.SetText(strconv.FormatUint(uint64(entry.Duration), 10))
+	pd.selectTransition.SetSelectedIndex(int(entry.Transition))
+	pd.fadeEntry.SetText(strconv.FormatUint(uint64(entry.Fade), 10))
+	pd.durationEntry.Enable()
+	pd.selectTransition.Enable()
+	pd.fadeEntry.Enable()
+	pd.dropButton.Enable()
+	pd.entry = id
+}
+
+func (pd *PlaylistDialog) validateTitle(s string) error {
+	err := pd.effect.ValidatePlaylistName(s)
+	if err != nil {
+		pd.addDialog.ApplyButton.Disable()
+		return err
+	}
+	pd.addDialog.ApplyButton.Enable()
+	return nil
+}
+
+func (pd *PlaylistDialog) add() {
+	title, _ := pd.addDialog.Name.Get()
+	playlist := &glow.Playlist{Title: title, Entries: []glow.PlaylistEntry{}}
+	err := pd.effect.WritePlaylist(playlist)
+	if err != nil {
+		fyne.LogError(title, err)
+		return
+	}
+	pd.refresh()
+	for i, t := range pd.titles {
+		if t == title {
+			pd.list.Select(i)
+		}
+	}
+}
+
+func (pd *PlaylistDialog) addEntry() {
+	folder, title := pd.effect.FolderName(), pd.effect.EffectName()
+	if pd.playlist == nil || title == "" {
+		return
+	}
+	pd.playlist.Entries = append(pd.playlist.Entries, glow.PlaylistEntry{
+		Folder:   folder,
+		Title:    title,
+		Duration: glow.DefaultDuration,
+	})
+	pd.entries.Refresh()
+	pd.entries.Select(len(pd.playlist.Entries) - 1)
+}
+
+func (pd *PlaylistDialog) dropEntry() {
+	id := pd.entry
+	if id < 0 {
+		return
+	}
+	pd.playlist.Entries = append(pd.playlist.Entries[:id], pd.playlist.Entries[id+1:]...)
+	pd.entries.UnselectAll()
+	pd.entries.Refresh()
+	pd.selectEntry(-1)
+}
+
+func (pd *PlaylistDialog) save() {
+	pd.playlist.Validate()
+	err := pd.effect.WritePlaylist(pd.playlist)
+	if err != nil {
+		fyne.LogError(pd.playlist.Title, err)
+	}
+	pd.entries.Refresh()
+	if pd.entry >= 0 {
+		pd.selectEntry(pd.entry)
+	}
+}
+
+func (pd *PlaylistDialog) remove() {
+	title := pd.playlist.Title
+	dialog.ShowConfirm(text.RemoveLabel.String(), title, func(ok bool) {
+		if !ok {
+			return
+		}
+		err := pd.effect.RemovePlaylist(title)
+		if err != nil {
+			fyne.LogError(title, err)
+		}
+		pd.refresh()
+	}, pd.window)
+}
+
+// playPlaylist plays a copy so the entries can be edited meanwhile.
+func (pd *PlaylistDialog) playPlaylist() {
+	playlist := &glow.Playlist{
+		Title:   pd.playlist.Title,
+		Entries: append([]glow.PlaylistEntry{}, pd.playlist.Entries...),
+	}
+	err := pd.play(playlist)
+	if err != nil {
+		dialog.ShowError(err, pd.window)
+	}
+}
@@ -1,11 +1,14 @@
 package ui
 
 import (
+	"gglow/fyglow/resource"
+	"gglow/glow"
 	"gglow/settings"
 	"gglow/text"
 	"image/color"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
@@ -21,7 +24,17 @@ type ProfileDialog struct {
 	sourceStrip binding.Untyped
 	columns     binding.Int
 	rows        binding.Int
+	wiring      *widget.Select
+	mapPath     binding.String
+	gamma       binding.Float
+	temperature binding.Int
+	brightness  binding.Int
+	budget      binding.Int
+	format      *widget.Select
+	whiteMode   *widget.Select
+	whiteTemp   binding.Int
 	background  color.Color
+	parent      fyne.Window
 
 	boundsRow *IntEntryBounds
 	boundsCol *IntEntryBounds
@@ -34,7 +47,14 @@ func NewProfileDialog(parent fyne.Window, p fyne.Preferences,
 		sourceStrip: sourceStrip,
 		columns:     binding.NewInt(),
 		rows:        binding.NewInt(),
+		mapPath:     binding.NewString(),
+		gamma:       binding.NewFloat(),
+		temperature: binding.NewInt(),
+		brightness:  binding.NewInt(),
+		budget:      binding.NewInt(),
+		whiteTemp:   binding.NewInt(),
 		background:  background,
+		parent:      parent,
 
 		boundsRow: &IntEntryBounds{1, maxRows, 0, 0},
 		boundsCol: &IntEntryBounds{1, maxCols, 0, 0},
@@ -48,7 +68,44 @@ func NewProfileDialog(parent fyne.Window, p fyne.Preferences,
 	rowsEntry := NewRangeIntBox(ll.rows, ll.boundsRow)
 	rowsItem := widget.NewFormItem(text.RowsLabel.String(), rowsEntry.Container)
 
-	frm := widget.NewForm(colItem, rowsItem)
+	ll.wiring = widget.NewSelect(text.WiringLabels, func(s string) {})
+	ll.wiring.SetSelectedIndex(p.Int(settings.StripWiring.String()))
+	wiringItem := widget.NewFormItem(text.WiringLabel.String(), ll.wiring)
+
+	ll.mapPath.Set(p.String(settings.StripMap.String()))
+	mapEntry := widget.NewEntryWithData(ll.mapPath)
+	mapEntry.SetPlaceHolder(text.PixelMapLabel.PlaceHolder())
+	mapSelector := NewFileSelector(ll.mapPath,
+		[]string{".csv", ".json", ".xmodel"}, parent)
+	mapItem := widget.NewFormItem(text.PixelMapLabel.String(),
+		container.NewBorder(nil, nil, nil, mapSelector, mapEntry))
+
+	gammaEntry := NewRangeFloatBox(ll.gamma, GammaBounds)
+	gammaItem := widget.NewFormItem(text.GammaLabel.String(), gammaEntry.Container)
+	temperatureEntry := NewRangeIntBox(ll.temperature, TemperatureBounds)
+	temperatureItem := widget.NewFormItem(text.TemperatureLabel.String(),
+		temperatureEntry.Container)
+	brightnessEntry := NewRangeIntBox(ll.brightness, PercentBounds)
+	brightnessItem := widget.NewFormItem(text.BrightnessLabel.String(),
+		brightnessEntry.Container)
+	ll.setOutput(OutputPreferences(p))
+
+	ll.budget.Set(budgetPreference(p))
+	budgetEntry := NewRangeIntBox(ll.budget, BudgetBounds)
+	budgetItem := widget.NewFormItem(text.SupplyLabel.String(), budgetEntry.Container)
+
+	ll.format = widget.NewSelect(text.ColorFormatLabels, func(s string) {})
+	formatItem := widget.NewFormItem(text.FormatLabel.String(), ll.format)
+	ll.whiteMode = widget.NewSelect(text.WhiteModeLabels, func(s string) {})
+	whiteItem := widget.NewFormItem(text.WhiteLabel.String(), ll.whiteMode)
+	whiteTempEntry := NewRangeIntBox(ll.whiteTemp, TemperatureBounds)
+	whiteTempItem := widget.NewFormItem(text.WhiteTemperatureLabel.String(),
+		whiteTempEntry.Container)
+	ll.setWhite(WhitePreferences(p))
+
+	frm := widget.NewForm(colItem, rowsItem, wiringItem, mapItem,
+		gammaItem, temperatureItem, brightnessItem, budgetItem,
+		formatItem, whiteItem, whiteTempItem)
 	ll.CustomDialog = dialog.NewCustomWithoutButtons(text.GridLayoutLabel.String(),
 		frm, parent)
 	confirm := widget.NewButton(text.ApplyLabel.String(), ll.confirm)
@@ -67,12 +124,47 @@ func NewProfileDialog(parent fyne.Window, p fyne.Preferences,
 }
 
 func (ll *ProfileDialog) confirm() {
+	path, _ := ll.mapPath.Get()
+	var pm *glow.PixelMap
+	if path != "" {
+		var err error
+		pm, err = glow.LoadPixelMap(path)
+		if err != nil {
+			dialog.ShowError(err, ll.parent)
+			return
+		}
+	}
+
 	ll.Hide()
+	ll.preferences.SetString(settings.StripMap.String(), path)
+	gamma, _ := ll.gamma.Get()
+	ll.preferences.SetFloat(settings.StripGamma.String(), gamma)
+	temperature, _ := ll.temperature.Get()
+	ll.preferences.SetInt(settings.StripTemperature.String(), temperature)
+	brightness, _ := ll.brightness.Get()
+	ll.preferences.SetInt(settings.StripBrightness.String(), brightness)
+	budget, _ := ll.budget.Get()
+	ll.preferences.SetInt(settings.StripBudget.String(), budget)
+	ll.preferences.SetInt(settings.StripFormat.String(), ll.format.SelectedIndex())
+	ll.preferences.SetInt(settings.StripWhiteMode.String(), ll.whiteMode.SelectedIndex())
+	whiteTemp, _ := ll.whiteTemp.Get()
+	ll.preferences.SetInt(settings.StripWhiteTemperature.String(), whiteTemp)
+	if pm != nil {
+		strip := NewMappedLightStrip(pm, ll.background)
+		strip.SetWhite(WhitePreferences(ll.preferences))
+		ll.sourceStrip.Set(strip)
+		return
+	}
+
 	columns, _ := ll.columns.Get()
 	rows, _ := ll.rows.Get()
 	ll.preferences.SetInt(settings.StripColumns.String(), columns)
 	ll.preferences.SetInt(settings.StripRows.String(), rows)
-	ll.sourceStrip.Set(NewLightStrip(columns*rows, rows, ll.background))
+	wiring := ll.wiring.SelectedIndex()
+	ll.preferences.SetInt(settings.StripWiring.String(), wiring)
+	strip := NewLightStrip(columns*rows, rows, ll.background, glow.Wiring(wiring))
+	strip.SetWhite(WhitePreferences(ll.preferences))
+	ll.sourceStrip.Set(strip)
 }
 
 func (ll *ProfileDialog) revert() {
@@ -81,4 +173,84 @@ func (ll *ProfileDialog) revert() {
 	rows := ll.preferences.Int(settings.StripRows.String())
 	ll.columns.Set(columns)
 	ll.rows.Set(rows)
+	ll.wiring.SetSelectedIndex(ll.preferences.Int(settings.StripWiring.String()))
+	ll.mapPath.Set(ll.preferences.String(settings.StripMap.String()))
+	ll.setOutput(OutputPreferences(ll.preferences))
+	ll.budget.Set(budgetPreference(ll.preferences))
+	ll.setWhite(WhitePreferences(ll.preferences))
+}
+
+func (ll *ProfileDialog) setWhite(white *glow.White) {
+	if white == nil {
+		white = &glow.White{}
+	}
+	ll.format.SetSelectedIndex(int(white.Format))
+	ll.whiteMode.SetSelectedIndex(int(white.Mode))
+	ll.whiteTemp.Set(int(white.Temperature))
+}
+
+func (ll *ProfileDialog) setOutput(out *glow.Output) {
+	if out == nil {
+		out = &glow.Output{Brightness: glow.MaximumPercent}
+	}
+	ll.gamma.Set(float64(out.Gamma[0]))
+	ll.temperature.Set(int(out.Temperature))
+	ll.brightness.Set(int(out.Brightness))
+}
+
+// OutputPreferences returns the color correction for exported code,
+// or nil when the lights need none.
+func OutputPreferences(p fyne.Preferences) *glow.Output {
+	gamma := float32(p.Float(settings.StripGamma.String()))
+	temperature := p.Int(settings.StripTemperature.String())
+	brightness := p.Int(settings.StripBrightness.String())
+	if gamma == 0 && temperature == 0 && (brightness == 0 || brightness == glow.MaximumPercent) {
+		return nil
+	}
+	out := &glow.Output{
+		Gamma:       [3]float32{gamma, gamma, gamma},
+		Temperature: uint16(temperature),
+		Brightness:  uint16(brightness),
+	}
+	out.Setup()
+	return out
+}
+
+// WhitePreferences returns the white channel extraction for RGBW
+// lights, or nil when the lights are RGB.
+func WhitePreferences(p fyne.Preferences) *glow.White {
+	format := glow.ColorFormat(p.Int(settings.StripFormat.String()))
+	if format == glow.FormatRGB || format >= glow.COLOR_FORMAT_COUNT {
+		return nil
+	}
+	white := &glow.White{
+		Format:      format,
+		Mode:        glow.WhiteMode(p.Int(settings.StripWhiteMode.String())),
+		Temperature: uint16(p.Int(settings.StripWhiteTemperature.String())),
+	}
+	white.Setup()
+	return white
+}
+
+func budgetPreference(p fyne.Preferences) int {
+	return p.IntWithFallback(settings.StripBudget.String(), glow.DefaultPowerBudget)
+}
+
+// PowerPreferences returns an analyzer for the lights in the profile.
+func PowerPreferences(p fyne.Preferences) *glow.PowerAnalyzer {
+	pa := glow.NewPowerAnalyzer()
+	pa.Columns = p.IntWithFallback(settings.StripColumns.String(),
+		resource.StripColumnsDefault)
+	pa.Rows = p.IntWithFallback(settings.StripRows.String(),
+		resource.StripRowsDefault)
+	pa.Budget = float32(budgetPreference(p))
+	pa.Output = OutputPreferences(p)
+	path := p.String(settings.StripMap.String())
+	if path != "" {
+		pm, err := glow.LoadPixelMap(path)
+		if err == nil {
+			pa.Map = pm
+		}
+	}
+	return pa
 }
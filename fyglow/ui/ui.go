@@ -3,6 +3,7 @@ package ui
 import (
 	"gglow/fyglow/effectio"
 	"gglow/fyglow/resource"
+	"gglow/glow"
 	"gglow/settings"
 	"gglow/text"
 
@@ -85,7 +86,16 @@ func (ui *Ui) BuildContent() *fyne.Container {
 	ui.Cols, ui.Rows = ui.getLightPreferences()
 	cols, rows := ui.Cols, ui.Rows
 	color := ui.theme.Color(resource.LightStripBackground, ui.theme.GetVariant())
-	ui.strip = NewLightStrip(cols*rows, rows, color)
+	wiring := glow.Wiring(ui.preferences.Int(settings.StripWiring.String()))
+	ui.strip = NewLightStrip(cols*rows, rows, color, wiring)
+	if path := ui.preferences.String(settings.StripMap.String()); path != "" {
+		if pm, err := glow.LoadPixelMap(path); err == nil {
+			ui.strip = NewMappedLightStrip(pm, color)
+		} else {
+			fyne.LogError("LoadPixelMap", err)
+		}
+	}
+	ui.strip.SetWhite(WhitePreferences(ui.preferences))
 	ui.sourceStrip.Set(ui.strip)
 
 	ui.stripProfile = NewProfileDialog(ui.window, ui.app.Preferences(), ui.sourceStrip, color)
@@ -0,0 +1,58 @@
+#pragma once
+
+#include <stdint.h>
+#include <algorithm>
+
+#include "base.h"
+#include "RGBColor.h"
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    BlendReplace,
+    BlendAdd,
+    BlendMultiply,
+    BlendScreen,
+    BlendMax,
+    BlendMin,
+    BlendAlphaOver,
+    BlendDifference,
+    BLEND_COUNT,
+  };
+
+  inline uint8_t blend_channel(uint16_t mode, uint8_t dst, uint8_t src, uint8_t alpha)
+  {
+    const int d = dst;
+    const int s = src;
+    switch (mode)
+    {
+    case BlendAdd:
+      return static_cast<uint8_t>(std::min(d + s, 255));
+    case BlendMultiply:
+      return static_cast<uint8_t>(d * s / 255);
+    case BlendScreen:
+      return static_cast<uint8_t>(255 - (255 - d) * (255 - s) / 255);
+    case BlendMax:
+      return static_cast<uint8_t>(std::max(d, s));
+    case BlendMin:
+      return static_cast<uint8_t>(std::min(d, s));
+    case BlendAlphaOver:
+      return static_cast<uint8_t>((s * alpha + d * (255 - alpha)) / 255);
+    case BlendDifference:
+      return static_cast<uint8_t>((d > s) ? d - s : s - d);
+    }
+    return src;
+  }
+
+  inline Color blend_colors(uint16_t mode, Color dst, Color src, uint8_t alpha = 255)
+  {
+    if (mode == BlendReplace)
+    {
+      return src;
+    }
+    return Color{blend_channel(mode, dst.red, src.red, alpha),
+                 blend_channel(mode, dst.green, src.green, alpha),
+                 blend_channel(mode, dst.blue, src.blue, alpha)};
+  }
+} // namespace glow
@@ -15,7 +15,17 @@ namespace glow
       s << color.make_code() << "," << '\n';
     }
     s << "}," << '\n';
-    s << hue_shift << "}";
+    s << hue_shift << "," << interpolation;
+    if (positions.size() > 0)
+    {
+      s << ",{";
+      for (auto position : stop_positions())
+      {
+        s << position << ",";
+      }
+      s << "}";
+    }
+    s << "}";
     return s.str();
   }
 
@@ -25,6 +35,8 @@ namespace glow
       "target",
       "hue_shift",
       "colors",
+      "interpolation",
+      "positions",
   };
   Palette Chroma::palette{};
 #endif
@@ -74,29 +86,128 @@ namespace glow
       return false;
     }
 
+    unmodulate();
     if (colors.size() < 1)
     {
       colors.push_back(color_default);
     }
 
+    if (interpolation >= INTERPOLATION_COUNT)
+    {
+      interpolation = InterpolateHSV;
+    }
+
     quick_color = colors[0].to_rgb();
     uint16_t size = colors.size() - 1;
     segment_size = (size < 2) ? length : length / size;
+    if (segment_size == 0)
+    {
+      segment_size = 1;
+    }
+    stops.clear();
+    if (positions.size() > 0 && colors.size() > 1)
+    {
+      stops = stop_positions();
+      for (auto &stop : stops)
+      {
+        stop = static_cast<uint32_t>(stop) * length / 100;
+      }
+    }
+    prepare();
     return true;
   }
 
+  // fills in automatic positions evenly and keeps them in order
+  std::vector<uint16_t> Chroma::stop_positions() const
+  {
+    const int32_t count = colors.size();
+    std::vector<int32_t> found(count, -1);
+    int32_t last = 0;
+    for (int32_t i = 0; i < count; i++)
+    {
+      if (i < static_cast<int32_t>(positions.size()) && positions[i] <= 100)
+      {
+        found[i] = std::max(static_cast<int32_t>(positions[i]), last);
+        last = found[i];
+      }
+    }
+    std::vector<uint16_t> result(count);
+    if (count == 0)
+    {
+      return result;
+    }
+    if (found[0] < 0)
+    {
+      found[0] = 0;
+    }
+    if (found[count - 1] < 0)
+    {
+      found[count - 1] = 100;
+    }
+
+    for (int32_t i = 1; i < count; i++)
+    {
+      if (found[i] >= 0)
+        continue;
+      int32_t j = i;
+      while (found[j] < 0)
+        j++;
+      int32_t from = found[i - 1], to = found[j], steps = j - i + 1;
+      for (int32_t k = i; k < j; k++)
+      {
+        found[k] = from + (to - from) * (k - i + 1) / steps;
+      }
+      i = j;
+    }
+
+    for (int32_t i = 0; i < count; i++)
+    {
+      result[i] = found[i];
+    }
+    return result;
+  }
+
+  void Chroma::prepare()
+  {
+    rgbs.clear();
+    labs.clear();
+    if (interpolation == InterpolateRGB)
+    {
+      for (auto &hsv : colors)
+      {
+        rgbs.push_back(hsv.to_rgb());
+      }
+    }
+    else if (interpolation == InterpolateOKLab || interpolation == InterpolateOKLCh)
+    {
+      for (auto &hsv : colors)
+      {
+        labs.push_back(to_oklab(hsv.to_rgb()));
+      }
+    }
+  }
+
   bool Chroma::setup(uint16_t p_length,
                      std::initializer_list<HSVColor> p_colors,
-                     int16_t p_hue_shift)
+                     int16_t p_hue_shift,
+                     uint16_t p_interpolation,
+                     std::initializer_list<uint16_t> p_positions)
   {
     length = p_length;
     colors = p_colors;
     hue_shift = p_hue_shift;
+    interpolation = p_interpolation;
+    positions = p_positions;
     return setup();
   }
 
   Color Chroma::map(uint16_t index)
   {
+    if (!stops.empty())
+    {
+      return map_stops(index);
+    }
+
     uint16_t size = colors.size();
     if (size < 2 || index == 0)
     {
@@ -106,9 +217,46 @@ namespace glow
     // d.quot == segment
     // d.rem == index within segment
     div_t d = div(index, segment_size);
-    HSVColor first = colors[d.quot];
-    HSVColor last = colors[d.quot + 1];
-    return first.to_gradient(last, d.rem, segment_size).to_rgb();
+    // lengths that do not divide evenly end on the last color
+    if (d.quot >= size - 1)
+    {
+      return colors[size - 1].to_rgb();
+    }
+    return mix(d.quot, d.rem, segment_size);
+  }
+
+  Color Chroma::mix(uint16_t first, uint16_t offset, uint16_t size)
+  {
+    switch (interpolation)
+    {
+    case InterpolateRGB:
+      return mix_linear(rgbs[first], rgbs[first + 1], offset, size);
+    case InterpolateOKLab:
+      return mix_oklab(labs[first], labs[first + 1], offset, size);
+    case InterpolateOKLCh:
+      return mix_oklch(labs[first], labs[first + 1], offset, size);
+    }
+    return colors[first].to_gradient(colors[first + 1], offset, size, interpolation).to_rgb();
+  }
+
+  // stops at the same position make a hard edge
+  Color Chroma::map_stops(uint16_t index)
+  {
+    const uint16_t last = stops.size() - 1;
+    if (index <= stops[0])
+    {
+      return quick_color;
+    }
+    if (index >= stops[last])
+    {
+      return colors[last].to_rgb();
+    }
+    uint16_t segment = 0;
+    while (segment + 1 < last && stops[segment + 1] <= index)
+    {
+      segment++;
+    }
+    return mix(segment, index - stops[segment], stops[segment + 1] - stops[segment]);
   }
 
   void Chroma::update()
@@ -125,5 +273,57 @@ namespace glow
       }
     }
     quick_color = colors.begin()->to_rgb();
+    prepare();
+  }
+
+  void Chroma::modulate(uint16_t stop, int32_t hue, int32_t saturation, int32_t value)
+  {
+    if (stop >= colors.size())
+    {
+      return;
+    }
+    if (modulated.size() != colors.size())
+    {
+      modulated.assign(colors.size(), Offset());
+    }
+    hue = hue * hue_limit / 360;
+    saturation = saturation * 255 / 100;
+    value = value * 255 / 100;
+
+    HSVColor &hsv = colors[stop];
+    Offset &applied = modulated[stop];
+    const int32_t limit = hue_limit;
+    const int32_t base_hue = ((static_cast<int32_t>(hsv.hue) - applied.hue) % limit + limit) % limit;
+    const int32_t base_saturation = static_cast<int32_t>(hsv.saturation) - applied.saturation;
+    const int32_t base_value = static_cast<int32_t>(hsv.value) - applied.value;
+
+    const int32_t next_saturation = std::min(std::max(base_saturation + saturation, 0), 255);
+    const int32_t next_value = std::min(std::max(base_value + value, 0), 255);
+    applied.hue = hue;
+    applied.saturation = next_saturation - base_saturation;
+    applied.value = next_value - base_value;
+    hsv.hue = static_cast<uint16_t>(((base_hue + hue) % limit + limit) % limit);
+    hsv.saturation = static_cast<uint8_t>(next_saturation);
+    hsv.value = static_cast<uint8_t>(next_value);
+
+    if (stop == 0)
+    {
+      quick_color = colors[0].to_rgb();
+    }
+    prepare();
+  }
+
+  // take the modulator offsets back out of the colors
+  void Chroma::unmodulate()
+  {
+    const int32_t limit = hue_limit;
+    for (size_t i = 0; i < modulated.size() && i < colors.size(); i++)
+    {
+      HSVColor &hsv = colors[i];
+      hsv.hue = static_cast<uint16_t>(((static_cast<int32_t>(hsv.hue) - modulated[i].hue) % limit + limit) % limit);
+      hsv.saturation = static_cast<uint8_t>(hsv.saturation - modulated[i].saturation);
+      hsv.value = static_cast<uint8_t>(hsv.value - modulated[i].value);
+    }
+    modulated.clear();
   }
-}
\ No newline at end of file
+}
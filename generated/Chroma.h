@@ -3,6 +3,7 @@
 #include <stdint.h>
 #include <string>
 #include <vector>
+#include <algorithm>
 
 #include "base.h"
 #ifndef MICRO_CONTROLLER
@@ -16,14 +17,38 @@
 namespace glow
 {
   const HSVColor color_default = {0, 0, 255};
+  const uint16_t position_auto = 0xffff;
 
   class Chroma
   {
   private:
     uint16_t length = 0;
     int16_t hue_shift = 0;
+    uint16_t interpolation = InterpolateHSV;
+    // colors ready to mix outside of HSV
+    std::vector<Color> rgbs;
+    std::vector<Lab> labs;
+    // positioned stops as indexes along the length
+    std::vector<uint16_t> stops;
+    // modulator offsets applied to each color
+    struct Offset
+    {
+      int32_t hue = 0;
+      int32_t saturation = 0;
+      int32_t value = 0;
+    };
+    std::vector<Offset> modulated;
+
+    void unmodulate();
+
+    void prepare();
+    Color mix(uint16_t first, uint16_t offset, uint16_t size);
+    Color map_stops(uint16_t index);
+
   public:
     std::vector<HSVColor> colors;
+    // optional percent position of each color
+    std::vector<uint16_t> positions;
     uint16_t segment_size{0};
     Color quick_color{0, 0, 0};
 
@@ -39,9 +64,11 @@ namespace glow
 
     Chroma(uint16_t p_length,
            std::initializer_list<HSVColor> p_colors,
-           int16_t p_hue_shift = 0)
+           int16_t p_hue_shift = 0,
+           uint16_t p_interpolation = InterpolateHSV,
+           std::initializer_list<uint16_t> p_positions = {})
     {
-      setup(p_length, p_colors, p_hue_shift);
+      setup(p_length, p_colors, p_hue_shift, p_interpolation, p_positions);
     }
 
     bool setup(uint16_t p_length,
@@ -56,7 +83,9 @@ namespace glow
 
     bool setup(uint16_t p_length,
                std::initializer_list<HSVColor> p_colors,
-               int16_t p_hue_shift = 0);
+               int16_t p_hue_shift = 0,
+               uint16_t p_interpolation = InterpolateHSV,
+               std::initializer_list<uint16_t> p_positions = {});
 
     bool setup();
 
@@ -69,6 +98,7 @@ namespace glow
 
     uint16_t get_length() const ALWAYS_INLINE { return length; }
     int16_t get_hue_shift() const ALWAYS_INLINE { return hue_shift; }
+    uint16_t get_interpolation() const ALWAYS_INLINE { return interpolation; }
     HSVColor get_hsv_source() const ALWAYS_INLINE { return colors[0]; }
     HSVColor get_hsv_target() const ALWAYS_INLINE
     {
@@ -84,10 +114,16 @@ namespace glow
       hue_shift = a_hue_shift;
     }
 
+    std::vector<uint16_t> stop_positions() const;
+
     Color map(uint16_t index);
 
     void update();
 
+    // move the color at stop from its own by hue degrees and saturation
+    // and value percent, replacing the offsets of the last call
+    void modulate(uint16_t stop, int32_t hue, int32_t saturation, int32_t value);
+
 #ifndef MICRO_CONTROLLER
     enum : uint8_t
     {
@@ -96,6 +132,8 @@ namespace glow
       TARGET,
       HUE_SHIFT,
       COLORS,
+      INTERPOLATION,
+      POSITIONS,
       KEY_COUNT,
     };
 
@@ -162,6 +200,11 @@ namespace YAML
         list.push_back(color);
       }
       node[Chroma::keys[Chroma::COLORS]] = list;
+      node[Chroma::keys[Chroma::INTERPOLATION]] = chroma.interpolation;
+      if (chroma.positions.size() > 0)
+      {
+        node[Chroma::keys[Chroma::POSITIONS]] = chroma.positions;
+      }
       return node;
     }
 
@@ -201,6 +244,12 @@ namespace YAML
         case Chroma::COLORS:
           lookup_list(item, chroma.colors);
           break;
+        case Chroma::INTERPOLATION:
+          chroma.interpolation = item.as<uint16_t>();
+          break;
+        case Chroma::POSITIONS:
+          chroma.positions = item.as<std::vector<uint16_t>>();
+          break;
         }
       }
       chroma.setup();
@@ -0,0 +1,21 @@
+#include "Envelope.h"
+
+namespace glow
+{
+#ifndef MICRO_CONTROLLER
+  std::string Envelope::make_code()
+  {
+    std::stringstream s;
+    s << "{" << attack << ","
+      << sustain << ","
+      << decay << "}";
+    return s.str();
+  }
+
+  std::string Envelope::keys[Envelope::KEY_COUNT] = {
+      "attack",
+      "sustain",
+      "decay",
+  };
+#endif
+}
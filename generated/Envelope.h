@@ -0,0 +1,142 @@
+#pragma once
+
+#include <stdint.h>
+#include <string>
+
+#include "base.h"
+#ifndef MICRO_CONTROLLER
+#include <yaml-cpp/yaml.h>
+#include <sstream>
+#endif
+
+namespace glow
+{
+  const uint8_t MAXIMUM_LEVEL = 255;
+  const uint16_t MAXIMUM_PERCENT = 100;
+
+  // a percent of zero is off rather than full
+  inline uint32_t percent_level(uint16_t percent)
+  {
+    if (percent > MAXIMUM_PERCENT)
+    {
+      percent = MAXIMUM_PERCENT;
+    }
+    return static_cast<uint32_t>(percent) * MAXIMUM_LEVEL / MAXIMUM_PERCENT;
+  }
+
+  inline uint8_t scale_level(uint8_t value, uint8_t level)
+  {
+    return static_cast<uint8_t>(static_cast<uint16_t>(value) * level / MAXIMUM_LEVEL);
+  }
+
+  class Envelope
+  {
+  private:
+    uint16_t attack{0};
+    uint16_t sustain{0};
+    uint16_t decay{0};
+
+  public:
+    Envelope() = default;
+
+    Envelope(uint16_t p_attack, uint16_t p_sustain, uint16_t p_decay)
+        : attack(p_attack), sustain(p_sustain), decay(p_decay) {}
+
+    uint16_t get_attack() const ALWAYS_INLINE { return attack; }
+    uint16_t get_sustain() const ALWAYS_INLINE { return sustain; }
+    uint16_t get_decay() const ALWAYS_INLINE { return decay; }
+
+    uint32_t length() const ALWAYS_INLINE
+    {
+      return static_cast<uint32_t>(attack) + sustain + decay;
+    }
+
+    uint8_t level(uint32_t spin) const
+    {
+      const uint32_t total = length();
+      if (total == 0)
+      {
+        return MAXIMUM_LEVEL;
+      }
+
+      uint32_t at = spin % total;
+      if (at < attack)
+      {
+        return static_cast<uint8_t>(MAXIMUM_LEVEL * at / attack);
+      }
+
+      at -= attack;
+      if (at < sustain)
+      {
+        return MAXIMUM_LEVEL;
+      }
+
+      at -= sustain;
+      return static_cast<uint8_t>(MAXIMUM_LEVEL * (decay - at) / decay);
+    }
+
+#ifndef MICRO_CONTROLLER
+    enum : uint8_t
+    {
+      ATTACK,
+      SUSTAIN,
+      DECAY,
+      KEY_COUNT,
+    };
+    static std::string keys[KEY_COUNT];
+    friend YAML::convert<Envelope>;
+    std::string make_code();
+#endif
+  };
+} // namespace glow
+
+#ifndef MICRO_CONTROLLER
+namespace YAML
+{
+  using glow::Envelope;
+
+  template <>
+  struct convert<Envelope>
+  {
+    static Node encode(const Envelope &envelope)
+    {
+      Node node;
+      node[Envelope::keys[Envelope::ATTACK]] = envelope.attack;
+      node[Envelope::keys[Envelope::SUSTAIN]] = envelope.sustain;
+      node[Envelope::keys[Envelope::DECAY]] = envelope.decay;
+      return node;
+    }
+
+    static bool decode(const Node &node, Envelope &envelope)
+    {
+      if (!node.IsMap())
+      {
+        return false;
+      }
+
+      for (auto key = 0; key < Envelope::KEY_COUNT; ++key)
+      {
+        Node item = node[Envelope::keys[key]];
+        if (!item.IsDefined())
+        {
+          continue;
+        }
+
+        switch (key)
+        {
+        case Envelope::ATTACK:
+          envelope.attack = item.as<uint16_t>();
+          break;
+        case Envelope::SUSTAIN:
+          envelope.sustain = item.as<uint16_t>();
+          break;
+        case Envelope::DECAY:
+          envelope.decay = item.as<uint16_t>();
+          break;
+        }
+      }
+      return true;
+    }
+  };
+}
+#endif // MICRO_CONTROLLER
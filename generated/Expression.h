@@ -0,0 +1,94 @@
+#pragma once
+
+#include <math.h>
+#include <stdint.h>
+
+#include "base.h"
+#include "HSVColor.h"
+
+namespace glow
+{
+  // what an expression reads for each light
+  struct ExpressionInput
+  {
+    double x;
+    double y;
+    double index;
+    double t;
+    double spin;
+  };
+
+  struct ExpressionOutput
+  {
+    double hue = 0;
+    double sat = 1;
+    double val = 1;
+  };
+
+  // an expression compiled to a function by the code generator
+  typedef void (*ExpressionFunction)(const ExpressionInput &in, ExpressionOutput &out);
+
+  // the helpers generated expressions call, keeping to the
+  // arithmetic the preview uses
+  inline double expr_div(double a, double b)
+  {
+    return b == 0 ? 0 : a / b;
+  }
+
+  inline double expr_mod(double a, double b)
+  {
+    return b == 0 ? 0 : fmod(a, b);
+  }
+
+  inline double expr_truth(bool ok) { return ok ? 1 : 0; }
+  inline double expr_sin(double a) { return sin(a); }
+  inline double expr_cos(double a) { return cos(a); }
+  inline double expr_tan(double a) { return tan(a); }
+  inline double expr_abs(double a) { return fabs(a); }
+  inline double expr_floor(double a) { return floor(a); }
+  inline double expr_ceil(double a) { return ceil(a); }
+  inline double expr_fract(double a) { return a - floor(a); }
+  inline double expr_sqrt(double a) { return sqrt(a); }
+  inline double expr_pow(double a, double b) { return pow(a, b); }
+  inline double expr_min(double a, double b) { return a < b ? a : b; }
+  inline double expr_max(double a, double b) { return a > b ? a : b; }
+
+  inline double expr_clamp(double a, double low, double high)
+  {
+    return expr_min(expr_max(a, low), high);
+  }
+
+  inline double expr_mix(double a, double b, double t)
+  {
+    double step = (b - a) * t;
+    return a + step;
+  }
+
+  inline double expr_if(double c, double a, double b)
+  {
+    return c != 0 ? a : b;
+  }
+
+  inline double expr_unit(double v)
+  {
+    if (isnan(v))
+      return 0;
+    return fmin(fmax(v, 0), 1);
+  }
+
+  // the color of an expression's results, hue wrapped into a turn
+  inline HSVColor expression_color(const ExpressionOutput &out)
+  {
+    double hue = out.hue;
+    if (isnan(hue) || isinf(hue))
+      hue = 0;
+    hue = fmod(hue, 360);
+    if (hue < 0)
+      hue += 360;
+    if (hue >= 360)
+      hue = 0;
+    return HSVColor(static_cast<uint16_t>(hue * hue_limit / 360),
+                    static_cast<uint8_t>(expr_unit(out.sat) * 255),
+                    static_cast<uint8_t>(expr_unit(out.val) * 255));
+  }
+}
@@ -15,7 +15,8 @@ namespace glow
       s << layer.make_code() << ",\n";
     }
 
-    s << "}}";
+    s << "}," << brightness << ","
+      << opacity << "}";
 
     return s.str();
   }
@@ -25,6 +26,8 @@ namespace glow
       "rows",
       "interval",
       "layers",
+      "brightness",
+      "opacity",
   };
 #endif
 
@@ -33,6 +36,9 @@ namespace glow
     length = frame.length;
     rows = frame.rows;
     interval = frame.interval;
+    brightness = frame.brightness;
+    opacity = frame.opacity;
+    wiring = frame.wiring;
     for (auto lay : frame.layers)
     {
       layers.push_back(lay);
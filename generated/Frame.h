@@ -20,7 +20,13 @@ namespace glow
     uint16_t length = 0;
     uint16_t rows = 0;
     uint32_t interval = 16;
+    uint16_t brightness = MAXIMUM_PERCENT;
+    uint16_t opacity = MAXIMUM_PERCENT;
+    uint16_t wiring = Progressive;
     uint32_t next = 0;
+    uint32_t last = 0;
+    uint32_t elapsed = 0;
+    MaskBuffer buffer;
 
   public:
     std::list<Layer> layers;
@@ -31,12 +37,18 @@ namespace glow
     Frame(uint16_t p_length,
           uint16_t p_rows,
           uint32_t p_interval,
-          std::initializer_list<Layer> p_layers)
+          std::initializer_list<Layer> p_layers,
+          uint16_t p_brightness = MAXIMUM_PERCENT,
+          uint16_t p_opacity = MAXIMUM_PERCENT,
+          uint16_t p_wiring = Progressive)
     {
       length = p_length;
       rows = p_rows;
       interval = p_interval;
       layers = p_layers;
+      brightness = p_brightness;
+      opacity = p_opacity;
+      wiring = p_wiring;
     }
 
     Frame(const Frame &frame)
@@ -55,8 +67,24 @@ namespace glow
         return false;
       }
 
+      if (brightness > MAXIMUM_PERCENT)
+      {
+        brightness = MAXIMUM_PERCENT;
+      }
+
+      if (opacity > MAXIMUM_PERCENT)
+      {
+        opacity = MAXIMUM_PERCENT;
+      }
+
+      if (wiring >= WIRING_COUNT)
+      {
+        wiring = Progressive;
+      }
+
       for (auto &layer : layers)
       {
+        layer.set_wiring(wiring);
         layer.setup_length(length, rows);
       }
 
@@ -76,18 +104,89 @@ namespace glow
     template <typename LIGHT>
     void spin(LIGHT &light)
     {
-      for (auto &layer : layers)
-      {
-        layer.spin(light);
-      }
+      spin(light, (elapsed == 0) ? interval : elapsed);
+    }
+
+    // advance each layer on its own clock by elapsed milliseconds
+    template <typename LIGHT>
+    void spin(LIGHT &light, uint32_t elapsed)
+    {
+      spin_layers(light, elapsed);
 #ifndef ESPHOME_CONTROLLER
       light.update();
 #endif
     }
 
+    // draw the layers without updating the light. A mask layer draws
+    // off the strip, and the layers above it, like a layer clipped to
+    // the one below, are drawn on a copy of the lights and mixed back
+    // by how much they are covered
+    template <typename LIGHT>
+    void spin_layers(LIGHT &light, uint32_t elapsed)
+    {
+      const std::vector<uint8_t> *mask = nullptr;
+      const Layer *below = nullptr;
+      for (auto it = layers.begin(); it != layers.end(); ++it)
+      {
+        Layer &layer = *it;
+        auto next = std::next(it);
+        const bool clip = layer.get_clip() && below != nullptr && layer.get_mask() == MaskNone;
+        layer.cover(layer.get_mask() != MaskNone || (next != layers.end() && next->get_clip()));
+        if (layer.get_mask() == MaskNone && mask == nullptr && !clip)
+        {
+          layer.spin(light, elapsed, interval, brightness, opacity);
+          below = &layer;
+          continue;
+        }
+
+        buffer.lights.resize(length);
+        for (uint16_t i = 0; i < length; i++)
+        {
+          buffer.lights[i] = Color(light.get(i).get());
+        }
+        layer.spin(buffer, elapsed, interval, brightness, opacity);
+        if (layer.get_mask() != MaskNone)
+        {
+          mask = &layer.get_coverage();
+          below = &layer;
+          continue;
+        }
+
+        for (uint16_t i = 0; i < length; i++)
+        {
+          uint8_t level = (mask == nullptr) ? MAXIMUM_LEVEL : (*mask)[i];
+          if (clip)
+          {
+            level = static_cast<uint8_t>(static_cast<uint32_t>(level) * below->get_coverage()[i] / MAXIMUM_LEVEL);
+          }
+          Color color(light.get(i).get());
+          light.get(i) = mix_colors(color, buffer.get(i), level).get();
+        }
+        below = &layer;
+      }
+    }
+
     uint16_t get_length() const ALWAYS_INLINE { return length; }
     uint16_t get_rows() const ALWAYS_INLINE { return rows; }
     uint32_t get_interval() const ALWAYS_INLINE { return interval; }
+    uint16_t get_brightness() const ALWAYS_INLINE { return brightness; }
+    uint16_t get_opacity() const ALWAYS_INLINE { return opacity; }
+    uint16_t get_wiring() const ALWAYS_INLINE { return wiring; }
+    void set_wiring(uint16_t p_wiring) ALWAYS_INLINE { wiring = p_wiring; }
+
+    // shortest interval at which the frame or a layer advances
+    uint32_t get_tick() const
+    {
+      uint32_t tick = interval;
+      for (auto &layer : layers)
+      {
+        if (layer.get_rate() > 0 && layer.get_rate() < tick)
+        {
+          tick = layer.get_rate();
+        }
+      }
+      return tick;
+    }
 
     size_t get_size() const ALWAYS_INLINE { return layers.size(); }
     std::list<Layer>::const_iterator begin() const ALWAYS_INLINE { return layers.begin(); }
@@ -99,10 +198,13 @@ namespace glow
     {
 #define millis() esphome::millis()
       const uint32_t now = millis();
+      const uint32_t tick = get_tick();
 
-      if (next - now > interval)
+      if (next - now > tick)
       {
-        next = now + interval;
+        next = now + tick;
+        elapsed = (last == 0) ? interval : now - last;
+        last = now;
         return true;
       }
       return false;
@@ -116,6 +218,8 @@ namespace glow
       ROWS,
       INTERVAL,
       LAYERS,
+      BRIGHTNESS,
+      OPACITY,
       KEY_COUNT,
     };
     static std::string keys[KEY_COUNT];
@@ -161,6 +265,8 @@ namespace YAML
         list.push_back(layer);
       }
       node[Frame::keys[Frame::LAYERS]] = list;
+      node[Frame::keys[Frame::BRIGHTNESS]] = frame.brightness;
+      node[Frame::keys[Frame::OPACITY]] = frame.opacity;
       return node;
     }
 
@@ -202,6 +308,12 @@ namespace YAML
             }
           }
           break;
+        case Frame::BRIGHTNESS:
+          frame.brightness = item.as<uint16_t>();
+          break;
+        case Frame::OPACITY:
+          frame.opacity = item.as<uint16_t>();
+          break;
         }
       }
 
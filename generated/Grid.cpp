@@ -1,5 +1,6 @@
 #include "Grid.h"
 #include <math.h>
+#include <algorithm>
 
 namespace glow
 {
@@ -41,6 +42,7 @@ namespace glow
       "vertical",
       "diagonal",
       "centred",
+      "concentric",
   };
 
   std::unordered_map<std::string, uint16_t> Grid::orientation_map = {
@@ -48,6 +50,7 @@ namespace glow
       {orientation_keys[Vertical], Vertical},
       {orientation_keys[Diagonal], Diagonal},
       {orientation_keys[Centred], Centred},
+      {orientation_keys[Concentric], Concentric},
   };
 
 #endif
@@ -60,6 +63,11 @@ namespace glow
       return (scaled / columns) * columns;
     }
 
+    if (orientation == Centred || orientation == Concentric)
+    {
+      return scaled;
+    }
+
     return (scaled / rows) * rows;
   }
 
@@ -75,6 +83,11 @@ namespace glow
       rows = 1;
     }
 
+    if (wiring >= WIRING_COUNT)
+    {
+      wiring = Progressive;
+    }
+
     columns = length / rows;
 
     if (orientation == Centred)
@@ -86,15 +99,23 @@ namespace glow
       setup_diagonal(rows, columns);
     }
 
+    table.clear();
+    if (orientation == Centred || orientation == Concentric)
+    {
+      setup_table();
+    }
+
     return true;
   }
 
-  bool Grid::setup(uint16_t p_length, uint16_t p_rows, uint8_t p_origin, uint8_t p_orientation)
+  bool Grid::setup(uint16_t p_length, uint16_t p_rows, uint8_t p_origin, uint8_t p_orientation,
+                   uint8_t p_wiring)
   {
     length = p_length;
     rows = p_rows;
     origin = p_origin;
     orientation = p_orientation;
+    wiring = p_wiring;
     return setup();
   }
 
@@ -109,11 +130,68 @@ namespace glow
     {
       offset = map_columns(index);
     }
-    else if (orientation == Centred)
+    else if (orientation == Centred || orientation == Concentric)
     {
-      offset = map_centred(index);
+      offset = map_table(index);
+    }
+    return map_wiring(map_to_origin(offset));
+  }
+
+  // physical index of a row major offset
+  uint16_t Grid::map_wiring(uint16_t offset)
+  {
+    if (wiring == Progressive || offset >= columns * rows)
+    {
+      return offset;
+    }
+
+    uint16_t x = offset % columns;
+    uint16_t y = offset / columns;
+    if (wiring == Serpentine)
+    {
+      if (y & 1)
+      {
+        x = columns - x - 1;
+      }
+      return y * columns + x;
+    }
+
+    if (wiring == ColumnSerpentine && (x & 1))
+    {
+      y = rows - y - 1;
+    }
+    return x * rows + y;
+  }
+
+  // column and row of the physical light at index
+  void Grid::locate(uint16_t index, uint16_t &x, uint16_t &y) const
+  {
+    switch (wiring)
+    {
+    case Serpentine:
+      x = index % columns;
+      y = index / columns;
+      if (y & 1)
+      {
+        x = columns - x - 1;
+      }
+      break;
+    case ColumnProgressive:
+      x = index / rows;
+      y = index % rows;
+      break;
+    case ColumnSerpentine:
+      x = index / rows;
+      y = index % rows;
+      if (x & 1)
+      {
+        y = rows - y - 1;
+      }
+      break;
+    default:
+      x = index % columns;
+      y = index / columns;
     }
-    return map_to_origin(offset);
   }
 
   uint16_t Grid::map_diagonal_top(uint16_t index)
@@ -350,4 +428,55 @@ namespace glow
     }
     return 0;
   }
+
+  // centred: squared distance from the centre in half steps
+  // concentric: ring from the outer edge inward, then clockwise
+  uint64_t Grid::table_key(uint16_t x, uint16_t y) const
+  {
+    if (orientation == Centred)
+    {
+      int32_t dx = 2 * x - (columns - 1);
+      int32_t dy = 2 * y - (rows - 1);
+      return static_cast<uint64_t>(dx * dx + dy * dy);
+    }
+
+    int32_t ring = std::min(std::min<int32_t>(x, y),
+                            std::min<int32_t>(columns - 1 - x, rows - 1 - y));
+    int32_t width = columns - 2 * ring;
+    int32_t height = rows - 2 * ring;
+    int32_t along;
+    if (y == ring)
+    {
+      along = x - ring;
+    }
+    else if (x == columns - 1 - ring)
+    {
+      along = width - 1 + y - ring;
+    }
+    else if (y == rows - 1 - ring)
+    {
+      along = width - 1 + height - 1 + columns - 1 - ring - x;
+    }
+    else
+    {
+      along = 2 * (width - 1) + height - 1 + rows - 1 - ring - y;
+    }
+    return static_cast<uint64_t>(ring) * 4 * columns * rows + along;
+  }
+
+  // order offsets by key, breaking ties by offset
+  void Grid::setup_table()
+  {
+    const uint32_t count = static_cast<uint32_t>(columns) * rows;
+    std::vector<uint64_t> keys(count);
+    table.resize(count);
+    for (uint32_t i = 0; i < count; i++)
+    {
+      table[i] = static_cast<uint16_t>(i);
+      keys[i] = table_key(i % columns, i / columns) * count + i;
+    }
+    std::sort(table.begin(), table.end(),
+              [&keys](uint16_t a, uint16_t b)
+              { return keys[a] < keys[b]; });
+  }
 } // namespace glow
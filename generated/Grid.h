@@ -3,6 +3,7 @@
 #include <stdint.h>
 #include <string>
 #include <unordered_map>
+#include <vector>
 
 #include "base.h"
 #ifndef MICRO_CONTROLLER
@@ -27,9 +28,19 @@ namespace glow
     Vertical,
     Diagonal,
     Centred,
+    Concentric,
     ORIENTATION_COUNT,
   };
 
+  enum : uint16_t
+  {
+    Progressive,
+    Serpentine,
+    ColumnProgressive,
+    ColumnSerpentine,
+    WIRING_COUNT,
+  };
+
   enum : uint8_t
   {
     PIVOT_SQUARE = 0,
@@ -45,6 +56,7 @@ namespace glow
     uint16_t rows{1};
     uint16_t origin{TopLeft};
     uint16_t orientation{Horizontal};
+    uint16_t wiring{Progressive};
 
 // derived
     uint16_t columns{0};
@@ -58,9 +70,14 @@ namespace glow
 
     uint8_t ring_status{0}; // =0x01,horz=0x01,uneven=0x02
 
+    // centred and concentric offsets in the order they are lit
+    std::vector<uint16_t> table;
+
     uint16_t map_centred_edge(uint16_t index);
     void setup_diagonal(uint16_t rows, uint16_t columns);
     void setup_centred(uint16_t rows, uint16_t columns);
+    void setup_table();
+    uint64_t table_key(uint16_t x, uint16_t y) const;
 
   public:
     Grid() = default;
@@ -68,15 +85,17 @@ namespace glow
     Grid(uint16_t p_length,
          uint16_t p_rows = 1,
          uint8_t p_origin = TopLeft,
-         uint8_t p_orientation = Horizontal)
+         uint8_t p_orientation = Horizontal,
+         uint8_t p_wiring = Progressive)
     {
-      setup(p_length, p_rows, p_origin, p_orientation);
+      setup(p_length, p_rows, p_origin, p_orientation, p_wiring);
     }
 
     bool setup(uint16_t p_length,
                uint16_t p_rows = 1,
                uint8_t p_origin = TopLeft,
-               uint8_t p_orientation = Horizontal);
+               uint8_t p_orientation = Horizontal,
+               uint8_t p_wiring = Progressive);
 
     bool setup();
 
@@ -92,6 +111,8 @@ namespace glow
     uint16_t get_rows() const ALWAYS_INLINE { return rows; }
     uint16_t get_origin() const ALWAYS_INLINE { return origin; }
     uint16_t get_orientation() const ALWAYS_INLINE { return orientation; }
+    uint16_t get_wiring() const ALWAYS_INLINE { return wiring; }
+    void set_wiring(uint16_t p_wiring) ALWAYS_INLINE { wiring = p_wiring; }
 
     uint16_t get_columns() const ALWAYS_INLINE { return columns; }
 
@@ -119,9 +140,17 @@ namespace glow
     uint16_t map_diagonal_top(uint16_t index);
     uint16_t map_diagonal_bottom(uint16_t index);
     uint16_t map_to_origin(uint16_t offset);
+    uint16_t map_wiring(uint16_t offset);
+    void locate(uint16_t index, uint16_t &x, uint16_t &y) const;
 
     uint16_t map_centred(uint16_t index);
 
+    // indexes past the table map as themselves, as in the preview
+    uint16_t map_table(uint16_t index) ALWAYS_INLINE
+    {
+      return (index < table.size()) ? table[index] : index;
+    }
+
     uint16_t map_columns(uint16_t index) ALWAYS_INLINE
     {
       div_t point = div(index, rows);
@@ -98,19 +98,21 @@ namespace glow
     const uint8_t color_range = primary -
                                 std::min({red, green, blue});
     hue = 0;
+    // the segment start is added before dividing so that hues
+    // round down as they do from the color wheel
     if (color_range != 0)
     {
       if (primary == red)
       {
-        hue = (hue_segment * (green - blue) / color_range) + hue_limit;
+        hue = (hue_segment * (green - blue) + hue_limit * color_range) / color_range;
       }
       else if (primary == green)
       {
-        hue = (hue_segment * (blue - red) / color_range) + hue_green;
+        hue = (hue_segment * (blue - red) + hue_green * color_range) / color_range;
       }
       else // if (primary == blue)
       {
-        hue = (hue_segment * (red - green) / color_range) + hue_blue;
+        hue = (hue_segment * (red - green) + hue_blue * color_range) / color_range;
       }
       hue %= hue_limit;
     }
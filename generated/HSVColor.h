@@ -10,6 +10,7 @@
 #endif
 
 #include "RGBColor.h"
+#include "Interpolate.h"
 
 namespace glow
 {
@@ -53,14 +54,32 @@ namespace glow
 
     void from_rgb(Color color);
 
-    HSVColor to_gradient(HSVColor target, uint16_t index, uint16_t length) ALWAYS_INLINE
+    // turns hue forward, the shorter way or in reverse, staying
+    // at the color when the length is zero
+    HSVColor to_gradient(HSVColor target, uint16_t index, uint16_t length,
+                         uint16_t interpolation = InterpolateHSV) ALWAYS_INLINE
     {
-      if (hue > target.hue)
+      if (length == 0)
+        return *this;
+      int32_t target_hue = target.hue;
+      switch (interpolation)
       {
-        target.hue += hue_limit;
+      case InterpolateHSVShortest:
+        if (target_hue - hue > hue_limit / 2)
+          target_hue -= hue_limit;
+        else if (hue - target_hue > hue_limit / 2)
+          target_hue += hue_limit;
+        break;
+      case InterpolateHSVReverse:
+        if (hue < target_hue)
+          target_hue -= hue_limit;
+        break;
+      default:
+        if (hue > target_hue)
+          target_hue += hue_limit;
       }
-      uint16_t gradient_hue = hue + ((target.hue - hue) * index) / length;
-      gradient_hue %= hue_limit;
+      int32_t gradient_hue = hue + ((target_hue - hue) * index) / length;
+      gradient_hue = (gradient_hue + hue_limit) % hue_limit;
       int16_t gradient_saturation = saturation + ((target.saturation - saturation) * index) / length;
       int16_t gradient_value = value + ((target.value - value) * index) / length;
       return HSVColor{static_cast<uint16_t>(gradient_hue),
@@ -0,0 +1,208 @@
+#include "Interpolate.h"
+
+namespace glow
+{
+  const uint16_t srgb_linear[256] = {
+      0, 20, 40, 60, 80, 99, 119, 139, 159, 179, 199, 219,
+      241, 264, 288, 313, 340, 367, 396, 427, 458, 491, 526, 562,
+      599, 637, 677, 718, 761, 805, 851, 898, 947, 997, 1048, 1101,
+      1156, 1212, 1270, 1330, 1391, 1453, 1517, 1583, 1651, 1720, 1790, 1863,
+      1937, 2013, 2090, 2170, 2250, 2333, 2418, 2504, 2592, 2681, 2773, 2866,
+      2961, 3058, 3157, 3258, 3360, 3464, 3570, 3678, 3788, 3900, 4014, 4129,
+      4247, 4366, 4488, 4611, 4736, 4864, 4993, 5124, 5257, 5392, 5530, 5669,
+      5810, 5953, 6099, 6246, 6395, 6547, 6700, 6856, 7014, 7174, 7335, 7500,
+      7666, 7834, 8004, 8177, 8352, 8528, 8708, 8889, 9072, 9258, 9445, 9635,
+      9828, 10022, 10219, 10417, 10619, 10822, 11028, 11235, 11446, 11658, 11873, 12090,
+      12309, 12530, 12754, 12980, 13209, 13440, 13673, 13909, 14146, 14387, 14629, 14874,
+      15122, 15371, 15623, 15878, 16135, 16394, 16656, 16920, 17187, 17456, 17727, 18001,
+      18277, 18556, 18837, 19121, 19407, 19696, 19987, 20281, 20577, 20876, 21177, 21481,
+      21787, 22096, 22407, 22721, 23038, 23357, 23678, 24002, 24329, 24658, 24990, 25325,
+      25662, 26001, 26344, 26688, 27036, 27386, 27739, 28094, 28452, 28813, 29176, 29542,
+      29911, 30282, 30656, 31033, 31412, 31794, 32179, 32567, 32957, 33350, 33745, 34143,
+      34544, 34948, 35355, 35764, 36176, 36591, 37008, 37429, 37852, 38278, 38706, 39138,
+      39572, 40009, 40449, 40891, 41337, 41785, 42236, 42690, 43147, 43606, 44069, 44534,
+      45002, 45473, 45947, 46423, 46903, 47385, 47871, 48359, 48850, 49344, 49841, 50341,
+      50844, 51349, 51858, 52369, 52884, 53401, 53921, 54445, 54971, 55500, 56032, 56567,
+      57105, 57646, 58190, 58737, 59287, 59840, 60396, 60955, 61517, 62082, 62650, 63221,
+      63795, 64372, 64952, 65535,
+  };
+
+  // a quarter turn of sine in 14 bit fixed point
+  static const int16_t quarter_sine[257] = {
+      0, 101, 201, 302, 402, 503, 603, 704, 804, 904, 1005, 1105, 1205, 1306, 1406, 1506,
+      1606, 1706, 1806, 1906, 2006, 2105, 2205, 2305, 2404, 2503, 2603, 2702, 2801, 2900, 2999, 3098,
+      3196, 3295, 3393, 3492, 3590, 3688, 3786, 3883, 3981, 4078, 4176, 4273, 4370, 4467, 4563, 4660,
+      4756, 4852, 4948, 5044, 5139, 5235, 5330, 5425, 5520, 5614, 5708, 5803, 5897, 5990, 6084, 6177,
+      6270, 6363, 6455, 6547, 6639, 6731, 6823, 6914, 7005, 7096, 7186, 7276, 7366, 7456, 7545, 7635,
+      7723, 7812, 7900, 7988, 8076, 8163, 8250, 8337, 8423, 8509, 8595, 8680, 8765, 8850, 8935, 9019,
+      9102, 9186, 9269, 9352, 9434, 9516, 9598, 9679, 9760, 9841, 9921, 10001, 10080, 10159, 10238, 10316,
+      10394, 10471, 10549, 10625, 10702, 10778, 10853, 10928, 11003, 11077, 11151, 11224, 11297, 11370, 11442, 11514,
+      11585, 11656, 11727, 11797, 11866, 11935, 12004, 12072, 12140, 12207, 12274, 12340, 12406, 12472, 12537, 12601,
+      12665, 12729, 12792, 12854, 12916, 12978, 13039, 13100, 13160, 13219, 13279, 13337, 13395, 13453, 13510, 13567,
+      13623, 13678, 13733, 13788, 13842, 13896, 13949, 14001, 14053, 14104, 14155, 14206, 14256, 14305, 14354, 14402,
+      14449, 14497, 14543, 14589, 14635, 14680, 14724, 14768, 14811, 14854, 14896, 14937, 14978, 15019, 15059, 15098,
+      15137, 15175, 15213, 15250, 15286, 15322, 15357, 15392, 15426, 15460, 15493, 15525, 15557, 15588, 15619, 15649,
+      15679, 15707, 15736, 15763, 15791, 15817, 15843, 15868, 15893, 15917, 15941, 15964, 15986, 16008, 16029, 16049,
+      16069, 16088, 16107, 16125, 16143, 16160, 16176, 16192, 16207, 16221, 16235, 16248, 16261, 16273, 16284, 16295,
+      16305, 16315, 16324, 16332, 16340, 16347, 16353, 16359, 16364, 16369, 16373, 16376, 16379, 16381, 16383, 16384,
+      16384,
+  };
+
+  uint8_t from_linear(int32_t linear)
+  {
+    if (linear <= 0)
+      return 0;
+    if (linear >= linear_one)
+      return 255;
+    uint16_t low = 0, high = 255;
+    while (low < high)
+    {
+      uint16_t middle = (low + high + 1) >> 1;
+      if (srgb_linear[middle] <= linear)
+        low = middle;
+      else
+        high = middle - 1;
+    }
+    if (low < 255 && srgb_linear[low + 1] - linear < linear - srgb_linear[low])
+      low++;
+    return low;
+  }
+
+  // cube root of 16 bit linear light in 14 bit fixed point
+  static int32_t cube_root(int32_t linear)
+  {
+    if (linear <= 0)
+      return 0;
+    uint64_t scaled = static_cast<uint64_t>(linear) << 26;
+    uint32_t root = 0;
+    for (int8_t bit = 15; bit >= 0; bit--)
+    {
+      uint64_t trial = root | (1u << bit);
+      if (trial * trial * trial <= scaled)
+        root = trial;
+    }
+    return root;
+  }
+
+  // cube of 14 bit fixed point in 16 bit linear light
+  static int64_t cube(int32_t q14)
+  {
+    int64_t value = q14;
+    return (value * value * value) >> 26;
+  }
+
+  static uint32_t square_root(uint32_t value)
+  {
+    uint32_t root = 0;
+    for (int8_t bit = 15; bit >= 0; bit--)
+    {
+      uint32_t trial = root | (1u << bit);
+      if (trial * trial <= value)
+        root = trial;
+    }
+    return root;
+  }
+
+  // angle of y over x in 1/65536 turns
+  static uint16_t angle(int32_t y, int32_t x)
+  {
+    if (x == 0 && y == 0)
+      return 0;
+    int32_t ax = x < 0 ? -x : x;
+    int32_t ay = y < 0 ? -y : y;
+    bool steep = ay > ax;
+    int64_t ratio = (static_cast<int64_t>(steep ? ax : ay) << 15) / (steep ? ay : ax);
+    // atan(r) ~ r/8 + r (1 - r) (0.03895 + 0.01055 r) turns
+    int64_t bend = ratio * (32768 - ratio) >> 15;
+    int32_t turn = (8192 * ratio + bend * (2552 + (692 * ratio >> 15))) >> 15;
+    if (steep)
+      turn = 16384 - turn;
+    if (x < 0)
+      turn = 32768 - turn;
+    if (y < 0)
+      turn = 65536 - turn;
+    return static_cast<uint16_t>(turn);
+  }
+
+  int32_t sine(uint16_t turn)
+  {
+    uint16_t quarter = turn >> 14;
+    uint16_t within = turn & 0x3fff;
+    if (quarter & 1)
+      within = 16384 - within;
+    uint16_t index = within >> 6;
+    int32_t level = quarter_sine[index];
+    if (index < 256)
+      level += ((quarter_sine[index + 1] - level) * (within & 63)) >> 6;
+    return (quarter & 2) ? -level : level;
+  }
+
+  Lab to_oklab(const Color &color)
+  {
+    int32_t r = to_linear(color.red);
+    int32_t g = to_linear(color.green);
+    int32_t b = to_linear(color.blue);
+    int32_t l = cube_root((6754 * r + 8787 * g + 843 * b) >> 14);
+    int32_t m = cube_root((3472 * r + 11153 * g + 1760 * b) >> 14);
+    int32_t s = cube_root((1447 * r + 4616 * g + 10322 * b) >> 14);
+    Lab lab;
+    lab.l = (3448 * l + 13003 * m - 67 * s) >> 14;
+    lab.a = (32408 * l - 39790 * m + 7383 * s) >> 14;
+    lab.b = (424 * l + 12825 * m - 13249 * s) >> 14;
+    return lab;
+  }
+
+  Color from_oklab(const Lab &lab)
+  {
+    int64_t l = cube((16384 * lab.l + 6494 * lab.a + 3536 * lab.b) >> 14);
+    int64_t m = cube((16384 * lab.l - 1730 * lab.a - 1046 * lab.b) >> 14);
+    int64_t s = cube((16384 * lab.l - 1466 * lab.a - 21160 * lab.b) >> 14);
+    return Color(from_linear((66793 * l - 54194 * m + 3784 * s) >> 14),
+                 from_linear((-20782 * l + 42758 * m - 5592 * s) >> 14),
+                 from_linear((-69 * l - 11525 * m + 27978 * s) >> 14));
+  }
+
+  static int32_t lerp(int32_t from, int32_t to, int32_t index, int32_t length)
+  {
+    return from + static_cast<int64_t>(to - from) * index / length;
+  }
+
+  Color mix_linear(const Color &from, const Color &to, int32_t index, int32_t length)
+  {
+    return Color(from_linear(lerp(to_linear(from.red), to_linear(to.red), index, length)),
+                 from_linear(lerp(to_linear(from.green), to_linear(to.green), index, length)),
+                 from_linear(lerp(to_linear(from.blue), to_linear(to.blue), index, length)));
+  }
+
+  Color mix_oklab(const Lab &from, const Lab &to, int32_t index, int32_t length)
+  {
+    Lab lab;
+    lab.l = lerp(from.l, to.l, index, length);
+    lab.a = lerp(from.a, to.a, index, length);
+    lab.b = lerp(from.b, to.b, index, length);
+    return from_oklab(lab);
+  }
+
+  Color mix_oklch(const Lab &from, const Lab &to, int32_t index, int32_t length)
+  {
+    // chroma below which hue is meaningless
+    const int32_t achromatic = 33;
+    int32_t from_chroma = square_root(from.a * from.a + from.b * from.b);
+    int32_t to_chroma = square_root(to.a * to.a + to.b * to.b);
+    uint16_t from_hue = angle(from.b, from.a);
+    uint16_t to_hue = angle(to.b, to.a);
+    if (from_chroma < achromatic)
+      from_hue = to_hue;
+    else if (to_chroma < achromatic)
+      to_hue = from_hue;
+
+    int16_t turn = static_cast<int16_t>(to_hue - from_hue);
+    uint16_t hue = from_hue + static_cast<int64_t>(turn) * index / length;
+    int32_t chroma = lerp(from_chroma, to_chroma, index, length);
+    Lab lab;
+    lab.l = lerp(from.l, to.l, index, length);
+    lab.a = (chroma * sine(hue + 16384)) >> 14;
+    lab.b = (chroma * sine(hue)) >> 14;
+    return from_oklab(lab);
+  }
+} // namespace glow
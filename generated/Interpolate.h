@@ -0,0 +1,53 @@
+#pragma once
+
+#include <stdint.h>
+
+#include "base.h"
+#include "RGBColor.h"
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    InterpolateHSV,
+    InterpolateHSVShortest,
+    InterpolateHSVReverse,
+    InterpolateRGB,
+    InterpolateOKLab,
+    InterpolateOKLCh,
+    INTERPOLATION_COUNT,
+  };
+
+  // full linear light
+  constexpr int32_t linear_one = 0xffff;
+
+  // OKLab lightness and axes in 14 bit fixed point
+  struct Lab
+  {
+    int16_t l = 0;
+    int16_t a = 0;
+    int16_t b = 0;
+  };
+
+  // linear light of each sRGB level
+  extern const uint16_t srgb_linear[256];
+
+  inline int32_t to_linear(uint8_t level)
+  {
+    return srgb_linear[level];
+  }
+
+  uint8_t from_linear(int32_t linear);
+
+  // sine of 1/65536 turns in 14 bit fixed point
+  int32_t sine(uint16_t turn);
+
+  Lab to_oklab(const Color &color);
+  Color from_oklab(const Lab &lab);
+
+  // colors index steps of length from one to the other
+  Color mix_linear(const Color &from, const Color &to, int32_t index, int32_t length);
+  Color mix_oklab(const Lab &from, const Lab &to, int32_t index, int32_t length);
+  // turns hue the shorter way keeping the hue of a grey end
+  Color mix_oklch(const Lab &from, const Lab &to, int32_t index, int32_t length);
+} // namespace glow
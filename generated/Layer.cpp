@@ -13,7 +13,33 @@ namespace glow
       << hue_shift << ","
       << scan << ","
       << begin << ","
-      << end << "}";
+      << end << ","
+      << blend << ","
+      << opacity << ","
+      << brightness << ","
+      << envelope.make_code() << ","
+      << rate << ","
+      << scan_mode << ","
+      << speed << ","
+      << noise.make_code() << ","
+      << particles.make_code() << ","
+      << text.make_code();
+    // an expression is a compiled function with no source to write
+    const bool masked = mask != MaskNone || clip;
+    if (modulators.size() > 0 || masked)
+    {
+      s << ",{";
+      for (auto &modulator : modulators)
+      {
+        s << modulator.make_code() << ",";
+      }
+      s << "}";
+    }
+    if (masked)
+    {
+      s << "," << mask << "," << (clip ? "true" : "false");
+    }
+    s << "}";
     return s.str();
   }
 
@@ -26,25 +52,114 @@ namespace glow
       "scan",
       "begin",
       "end",
+      "blend",
+      "opacity",
+      "brightness",
+      "envelope",
+      "rate",
+      "scan_mode",
+      "speed",
+      "noise",
+      "particles",
+      "text",
+      "modulators",
+      "mask",
+      "clip",
   };
 #endif
 
   void Layer::set_bounds()
   {
-    auto ratio = [](uint16_t offset, uint16_t length)
+    bounds(0, 0, first, last);
+    position = first;
+  }
+
+  void Layer::bounds(int32_t begin_offset, int32_t end_offset, uint16_t &p_first, uint16_t &p_last)
+  {
+    auto ratio = [](uint16_t offset, int32_t change, uint16_t length)
     {
       if (offset > 100)
         offset %= 100;
+      offset = static_cast<uint16_t>(clamp_offset(offset, change, 0, 100));
       return static_cast<float>(offset) / 100.0f *
              static_cast<float>(length);
     };
 
-    first = grid.adjust_bounds(ratio(begin, length));
-    last = grid.adjust_bounds(ratio(end, length));
+    p_first = grid.adjust_bounds(ratio(begin, begin_offset, length));
+    p_last = grid.adjust_bounds(ratio(end, end_offset, length));
+
+    if (p_last < p_first)
+    {
+      std::swap(p_first, p_last);
+    }
+  }
+
+  void Layer::modulate()
+  {
+    if (modulators.size() == 0)
+    {
+      return;
+    }
+    Offsets next;
+    for (auto &modulator : modulators)
+    {
+      const int32_t offset = modulator.offset(time);
+      switch (modulator.get_target())
+      {
+      case ModBegin:
+        next.begin += offset;
+        break;
+      case ModEnd:
+        next.end += offset;
+        break;
+      case ModScan:
+        next.scan += offset;
+        break;
+      case ModOpacity:
+        next.opacity += offset;
+        break;
+      case ModRate:
+        next.rate += offset;
+        break;
+      }
+    }
+    if (next.begin != offsets.begin || next.end != offsets.end)
+    {
+      bounds(next.begin, next.end, first, last);
+      if (position < first || position >= last)
+      {
+        position = first;
+      }
+    }
+    offsets = next;
 
-    if (last < first)
+    // each stop takes the sum of its modulators once
+    for (size_t i = 0; i < modulators.size(); i++)
     {
-      std::swap(first, last);
+      const Modulator &modulator = modulators[i];
+      if (!modulator.is_color())
+      {
+        continue;
+      }
+      bool done = false;
+      for (size_t j = 0; j < i && !done; j++)
+      {
+        done = modulators[j].is_color() && modulators[j].get_stop() == modulator.get_stop();
+      }
+      if (done)
+      {
+        continue;
+      }
+      int32_t hsv[3] = {0, 0, 0};
+      for (size_t j = i; j < modulators.size(); j++)
+      {
+        const Modulator &other = modulators[j];
+        if (other.is_color() && other.get_stop() == modulator.get_stop())
+        {
+          hsv[other.get_target() - ModHue] += other.offset(time);
+        }
+      }
+      chroma.modulate(modulator.get_stop(), hsv[0], hsv[1], hsv[2]);
     }
   }
 
@@ -2,6 +2,7 @@
 
 #include <string>
 #include <algorithm>
+#include <math.h>
 
 #include "base.h"
 
@@ -12,9 +13,32 @@
 
 #include "Grid.h"
 #include "Chroma.h"
+#include "Blend.h"
+#include "Envelope.h"
+#include "Noise.h"
+#include "Particles.h"
+#include "Text.h"
+#include "Modulator.h"
+#include "Mask.h"
+#include "Expression.h"
 
 namespace glow
 {
+  enum : uint16_t
+  {
+    ScanForward,
+    ScanReverse,
+    ScanPingPong,
+    ScanRandom,
+    ScanEase,
+    ScanSine,
+    ScanBounce,
+    SCAN_MODE_COUNT,
+  };
+
+  const uint32_t SCAN_SEED = 2463534242;
+  const uint32_t MAXIMUM_INTERVAL = 10000;
+
   class Layer
   {
   private:
@@ -27,11 +51,43 @@ namespace glow
     uint16_t scan = 0;
     uint16_t begin = 0;
     uint16_t end = 100;
+    uint16_t blend = BlendReplace;
+    uint16_t opacity = MAXIMUM_PERCENT;
+    uint16_t brightness = MAXIMUM_PERCENT;
+    Envelope envelope;
+    uint32_t rate = 0;
+    uint16_t scan_mode = ScanForward;
+    uint16_t speed = 0;
+    Noise noise;
+    Particles particles;
+    Text text;
+    std::vector<Modulator> modulators;
+    uint16_t mask = MaskNone;
+    bool clip = false;
+    ExpressionFunction expression = nullptr;
 
     // variant
     uint16_t position = 0;
     uint16_t first = 0;
     uint16_t last = 0;
+    uint32_t spins = 0;
+    uint32_t clock = 0;
+    uint32_t phase = 0;
+    uint32_t seed = 0;
+    uint8_t level = MAXIMUM_LEVEL;
+    uint8_t alpha = MAXIMUM_LEVEL;
+    uint32_t time = 0;
+    struct Offsets
+    {
+      int32_t begin = 0;
+      int32_t end = 0;
+      int32_t scan = 0;
+      int32_t opacity = 0;
+      int32_t rate = 0;
+    } offsets;
+    // how much the layer covered each light this spin, kept for
+    // masks and the layer clipped above
+    std::vector<uint8_t> coverage;
 
   public:
     Layer() = default;
@@ -43,9 +99,25 @@ namespace glow
           int16_t p_hue_shift = 0,
           uint16_t p_scan = 0,
           uint16_t p_begin = 0,
-          uint16_t p_end = 100)
+          uint16_t p_end = 100,
+          uint16_t p_blend = BlendReplace,
+          uint16_t p_opacity = MAXIMUM_PERCENT,
+          uint16_t p_brightness = MAXIMUM_PERCENT,
+          const Envelope &p_envelope = Envelope(),
+          uint32_t p_rate = 0,
+          uint16_t p_scan_mode = ScanForward,
+          uint16_t p_speed = 0,
+          const Noise &p_noise = Noise(),
+          const Particles &p_particles = Particles(),
+          const Text &p_text = Text(),
+          const std::vector<Modulator> &p_modulators = {},
+          uint16_t p_mask = MaskNone,
+          bool p_clip = false,
+          ExpressionFunction p_expression = nullptr)
     {
-      setup(p_length, p_rows, p_grid, p_chroma, p_hue_shift, p_scan, p_begin, p_end);
+      setup(p_length, p_rows, p_grid, p_chroma, p_hue_shift, p_scan, p_begin, p_end,
+            p_blend, p_opacity, p_brightness, p_envelope, p_rate, p_scan_mode, p_speed,
+            p_noise, p_particles, p_text, p_modulators, p_mask, p_clip, p_expression);
     }
 
     uint16_t get_length() const ALWAYS_INLINE { return length; }
@@ -58,6 +130,22 @@ namespace glow
     uint16_t get_scan() const ALWAYS_INLINE { return scan; }
     uint16_t get_first() const ALWAYS_INLINE { return first; }
     uint16_t get_last() const ALWAYS_INLINE { return last; }
+    uint16_t get_position() const ALWAYS_INLINE { return position; }
+    uint16_t get_blend() const ALWAYS_INLINE { return blend; }
+    uint16_t get_opacity() const ALWAYS_INLINE { return opacity; }
+    uint16_t get_brightness() const ALWAYS_INLINE { return brightness; }
+    const Envelope &get_envelope() const ALWAYS_INLINE { return envelope; }
+    uint32_t get_rate() const ALWAYS_INLINE { return rate; }
+    uint16_t get_scan_mode() const ALWAYS_INLINE { return scan_mode; }
+    uint16_t get_speed() const ALWAYS_INLINE { return speed; }
+    const Noise &get_noise() const ALWAYS_INLINE { return noise; }
+    const Particles &get_particles() const ALWAYS_INLINE { return particles; }
+    const Text &get_text() const ALWAYS_INLINE { return text; }
+    const std::vector<Modulator> &get_modulators() const ALWAYS_INLINE { return modulators; }
+    uint16_t get_mask() const ALWAYS_INLINE { return mask; }
+    bool get_clip() const ALWAYS_INLINE { return clip; }
+    ExpressionFunction get_expression() const ALWAYS_INLINE { return expression; }
+    const std::vector<uint8_t> &get_coverage() const ALWAYS_INLINE { return coverage; }
 
     bool setup()
     {
@@ -91,6 +179,39 @@ namespace glow
         end = 100;
       }
 
+      if (blend >= BLEND_COUNT)
+      {
+        blend = BlendReplace;
+      }
+
+      if (scan_mode >= SCAN_MODE_COUNT)
+      {
+        scan_mode = ScanForward;
+      }
+
+      if (opacity > MAXIMUM_PERCENT)
+      {
+        opacity = MAXIMUM_PERCENT;
+      }
+
+      if (brightness > MAXIMUM_PERCENT)
+      {
+        brightness = MAXIMUM_PERCENT;
+      }
+
+      noise.setup();
+      particles.setup();
+      text.setup();
+      for (auto &modulator : modulators)
+      {
+        modulator.setup();
+      }
+
+      if (mask >= MASK_MODE_COUNT)
+      {
+        mask = MaskNone;
+      }
+      offsets = Offsets();
       set_bounds();
 
       return true;
@@ -98,6 +219,22 @@ namespace glow
 
     void set_bounds();
 
+    // first and last light with begin and end moved by percent offsets
+    void bounds(int32_t begin_offset, int32_t end_offset, uint16_t &p_first, uint16_t &p_last);
+
+    // move the modulated parameters to their values at the layer's time
+    void modulate();
+
+    // the modulated scan keeps at least one light
+    uint16_t scan_length() const ALWAYS_INLINE
+    {
+      if (scan == 0 || offsets.scan == 0)
+      {
+        return scan;
+      }
+      return static_cast<uint16_t>(clamp_offset(scan, offsets.scan, 1, length));
+    }
+
     bool setup(uint16_t p_length,
                uint16_t p_rows,
                const Grid &p_grid,
@@ -105,7 +242,21 @@ namespace glow
                int16_t p_hue_shift = 0,
                uint16_t p_scan = 0,
                uint16_t p_begin = 0,
-               uint16_t p_end = 100)
+               uint16_t p_end = 100,
+               uint16_t p_blend = BlendReplace,
+               uint16_t p_opacity = MAXIMUM_PERCENT,
+               uint16_t p_brightness = MAXIMUM_PERCENT,
+               const Envelope &p_envelope = Envelope(),
+               uint32_t p_rate = 0,
+               uint16_t p_scan_mode = ScanForward,
+               uint16_t p_speed = 0,
+               const Noise &p_noise = Noise(),
+               const Particles &p_particles = Particles(),
+               const Text &p_text = Text(),
+               const std::vector<Modulator> &p_modulators = {},
+               uint16_t p_mask = MaskNone,
+               bool p_clip = false,
+               ExpressionFunction p_expression = nullptr)
     {
       length = p_length;
       rows = p_rows;
@@ -115,9 +266,25 @@ namespace glow
       scan = p_scan;
       begin = p_begin;
       end = p_end;
+      blend = p_blend;
+      opacity = p_opacity;
+      brightness = p_brightness;
+      envelope = p_envelope;
+      rate = p_rate;
+      scan_mode = p_scan_mode;
+      speed = p_speed;
+      noise = p_noise;
+      particles = p_particles;
+      text = p_text;
+      modulators = p_modulators;
+      mask = p_mask;
+      clip = p_clip;
+      expression = p_expression;
       return setup();
     }
 
+    void set_wiring(uint16_t p_wiring) ALWAYS_INLINE { grid.set_wiring(p_wiring); }
+
     bool setup_length(uint16_t a_length, uint16_t a_rows = 1) ALWAYS_INLINE
     {
       length = a_length;
@@ -125,34 +292,338 @@ namespace glow
       return setup();
     }
 
-    void update_position(uint16_t &start_at, uint16_t &end_at) ALWAYS_INLINE
+    // position within 0..travel for a phase in 0..2*travel, out and back
+    uint32_t scan_travel(uint32_t at, uint32_t travel) const
+    {
+      uint32_t out = (at > travel) ? 2 * travel - at : at;
+
+      switch (scan_mode)
+      {
+      case ScanEase:
+      {
+        uint64_t u = out, t = travel;
+        return static_cast<uint32_t>(u * u * (3 * t - 2 * u) / (t * t));
+      }
+      case ScanSine:
+      {
+        double ratio = static_cast<double>(at) / static_cast<double>(travel);
+        return static_cast<uint32_t>(static_cast<double>(travel) * (1 - cos(M_PI * ratio)) / 2 + 0.5);
+      }
+      case ScanBounce:
+      {
+        uint64_t u = at, t = travel;
+        return static_cast<uint32_t>(u * (2 * t - u) / t);
+      }
+      }
+      return out;
+    }
+
+    // xorshift keeps random jumps reproducible
+    uint32_t random() ALWAYS_INLINE
+    {
+      if (seed == 0)
+      {
+        seed = SCAN_SEED;
+      }
+      seed ^= seed << 13;
+      seed ^= seed >> 17;
+      seed ^= seed << 5;
+      return seed;
+    }
+
+    void update_position()
+    {
+      const uint16_t span = last - first;
+      if (span == 0)
+      {
+        return;
+      }
+
+      const uint32_t step = (speed == 0) ? 1 : speed;
+      switch (scan_mode)
+      {
+      case ScanReverse:
+      {
+        int32_t at = static_cast<int32_t>(position) - static_cast<int32_t>(step);
+        while (at < first)
+        {
+          at += span;
+        }
+        position = static_cast<uint16_t>(at);
+        break;
+      }
+      case ScanRandom:
+        position = first + static_cast<uint16_t>(random() % span);
+        break;
+      case ScanPingPong:
+      case ScanEase:
+      case ScanSine:
+      case ScanBounce:
+      {
+        const uint32_t travel = span - std::min(scan_length(), span);
+        if (travel == 0)
+        {
+          position = first;
+          break;
+        }
+        phase = (phase + step) % (2 * travel);
+        position = first + static_cast<uint16_t>(scan_travel(phase, travel));
+        break;
+      }
+      default:
+        position += step;
+        if (position >= last)
+        {
+          position = first + (position - last) % span;
+        }
+      }
+    }
+
+    // a layer without a rate follows the frame interval
+    uint32_t tick(uint32_t elapsed, uint32_t interval) ALWAYS_INLINE
+    {
+      uint32_t step_rate = (rate == 0) ? interval : rate;
+      if (step_rate == 0)
+      {
+        return 1;
+      }
+      if (offsets.rate != 0)
+      {
+        step_rate = clamp_offset(step_rate, offsets.rate, 1, MAXIMUM_INTERVAL);
+      }
+      clock += elapsed;
+      uint32_t steps = clock / step_rate;
+      clock %= step_rate;
+      return steps;
+    }
+
+    void update_levels(uint16_t frame_brightness, uint16_t frame_opacity) ALWAYS_INLINE
+    {
+      uint32_t value = percent_level(brightness) * percent_level(frame_brightness) / MAXIMUM_LEVEL;
+      value = value * envelope.level(spins) / MAXIMUM_LEVEL;
+      level = static_cast<uint8_t>(value);
+      const uint16_t layer_opacity = clamp_offset(opacity, offsets.opacity, 0, MAXIMUM_PERCENT);
+      alpha = static_cast<uint8_t>(percent_level(layer_opacity) * percent_level(frame_opacity) / MAXIMUM_LEVEL);
+    }
+
+    // the chroma color of index x, or with noise the gradient
+    // color picked by the noise level at the light's position, or
+    // with an expression the color it gives the light
+    Color color(uint16_t x, uint16_t offset)
+    {
+      if (expression == nullptr && noise.get_kind() == NoiseNone)
+      {
+        return chroma.map(x);
+      }
+      uint16_t column, row;
+      grid.locate(offset, column, row);
+      if (expression != nullptr)
+      {
+        ExpressionInput in = {static_cast<double>(column), static_cast<double>(row),
+                              static_cast<double>(x), static_cast<double>(time) / 1000,
+                              static_cast<double>(spins)};
+        ExpressionOutput out;
+        expression(in, out);
+        return expression_color(out).to_rgb();
+      }
+      uint8_t level = noise.sample(column, row, rows, spins);
+      return chroma.map(static_cast<uint16_t>(static_cast<uint32_t>(level) * (length - 1) / MAXIMUM_LEVEL));
+    }
+
+    // record coverage this spin, or stop when on is false
+    void cover(bool on)
+    {
+      if (!on)
+      {
+        coverage.clear();
+        return;
+      }
+      coverage.assign(length, 0);
+    }
+
+    // drawn is how much of the light the layer drew, kept at the
+    // layer's alpha as its coverage
+    template <typename LIGHT>
+    void put(LIGHT &light, uint16_t index, Color color, uint8_t drawn = MAXIMUM_LEVEL)
     {
-      start_at = position;
-      end_at = position + scan;
+      if (level < MAXIMUM_LEVEL)
+      {
+        color.red = scale_level(color.red, level);
+        color.green = scale_level(color.green, level);
+        color.blue = scale_level(color.blue, level);
+      }
+      if (index < coverage.size())
+      {
+        coverage[index] = mask_cover((mask == MaskNone) ? MaskAlpha : mask, color,
+                                     static_cast<uint8_t>(static_cast<uint32_t>(drawn) * alpha / MAXIMUM_LEVEL));
+      }
+
+      if (blend == BlendReplace && alpha == MAXIMUM_LEVEL)
+      {
+        light.get(index) = color.get();
+        return;
+      }
 
-      position++;
-      if (position >= last)
+      Color current(light.get(index).get());
+      color = blend_colors(blend, current, color);
+      if (alpha < MAXIMUM_LEVEL)
       {
-        position = first;
+        color = blend_colors(BlendAlphaOver, current, color, alpha);
+      }
+      light.get(index) = color.get();
+    }
+
+    template <typename LIGHT>
+    void put_level(LIGHT &light, uint16_t index, Color color, uint8_t particle_level)
+    {
+      if (particle_level == 0)
+      {
+        return;
+      }
+      color.red = scale_level(color.red, particle_level);
+      color.green = scale_level(color.green, particle_level);
+      color.blue = scale_level(color.blue, particle_level);
+      put(light, index, color, particle_level);
+    }
+
+    // a replacing layer owns its range and clears it first,
+    // other blend modes leave the lights beneath
+    template <typename LIGHT>
+    void spin_particles(LIGHT &light, uint32_t steps)
+    {
+      if (blend == BlendReplace)
+      {
+        for (uint16_t i = first; i < last; ++i)
+        {
+          put(light, grid.map(i), Color(0, 0, 0), 0);
+        }
+      }
+
+      for (uint16_t i = 0; i < particles.get_count(); i++)
+      {
+        const Particle &particle = particles.get(i);
+        const uint16_t tail = (particles.get_kind() == ParticleMeteor) ? particles.tail_length() : 0;
+        for (uint16_t k = 0; k <= tail; k++)
+        {
+          if (particle.position < first + k)
+          {
+            break;
+          }
+          const uint16_t at = particle.position - k;
+          if (at >= last)
+          {
+            continue;
+          }
+          put_level(light, grid.map(at), chroma.map(particle.color), particles.level(particle, k));
+        }
+      }
+
+      for (uint32_t i = 0; i < steps; ++i)
+      {
+        particles.update(first, last, length, speed);
+        chroma.update();
+      }
+    }
+
+    // a replacing layer clears the lights around the message,
+    // other blend modes leave them
+    template <typename LIGHT>
+    void spin_text(LIGHT &light, uint32_t steps)
+    {
+      const uint16_t columns = grid.get_columns();
+      const uint16_t width = text.get_width();
+      for (uint16_t y = 0; y < rows; ++y)
+      {
+        for (uint16_t x = 0; x < columns; ++x)
+        {
+          const uint16_t index = grid.map_wiring(y * columns + x);
+          int32_t column;
+          const uint8_t coverage = text.sample(x, y, columns, rows, column);
+          if (coverage == 0)
+          {
+            if (blend == BlendReplace)
+            {
+              put(light, index, Color(0, 0, 0), 0);
+            }
+            continue;
+          }
+
+          Color color = chroma.map(0);
+          if (text.get_gradient() && width > 1)
+          {
+            color = chroma.map(static_cast<uint16_t>(column * (length - 1) / (width - 1)));
+          }
+          put_level(light, index, color, coverage);
+        }
+      }
+
+      for (uint32_t i = 0; i < steps; ++i)
+      {
+        text.update(speed, columns, rows);
+        chroma.update();
       }
     }
 
     template <typename LIGHT>
     void spin(LIGHT &light)
     {
+      spin(light, rate, 0);
+    }
+
+    template <typename LIGHT>
+    void spin(LIGHT &light,
+              uint32_t elapsed,
+              uint32_t interval,
+              uint16_t frame_brightness = MAXIMUM_PERCENT,
+              uint16_t frame_opacity = MAXIMUM_PERCENT)
+    {
+      modulate();
+      time += elapsed;
+      const uint32_t steps = tick(elapsed, interval);
+      update_levels(frame_brightness, frame_opacity);
+
+      if (text.get_width() > 0)
+      {
+        spin_text(light, steps);
+        spins += steps;
+        return;
+      }
+
+      if (particles.get_kind() != ParticleNone)
+      {
+        spin_particles(light, steps);
+        spins += steps;
+        return;
+      }
+
       uint16_t start_at{first};
       uint16_t end_at{last};
+      const uint16_t scan = scan_length();
 
       if (scan > 0)
       {
-        update_position(start_at, end_at);
+        start_at = position;
+        end_at = position + scan;
+      }
+
+      // a scan passing the last light wraps to the first
+      const uint16_t span = last - first;
+      for (uint16_t i = start_at; i < end_at && span > 0; ++i)
+      {
+        uint16_t x = first + (i - first) % span;
+        uint16_t offset = grid.map(x);
+        put(light, offset, color(x, offset));
       }
 
-      for (uint16_t i = start_at; i < end_at; ++i)
+      for (uint32_t i = 0; i < steps; ++i)
       {
-        light.get(grid.map(i)) = chroma.map(i).get();
+        if (scan > 0)
+        {
+          update_position();
+        }
+        chroma.update();
       }
-      chroma.update();
+      spins += steps;
     }
 
 #ifndef MICRO_CONTROLLER
@@ -166,6 +637,19 @@ namespace glow
       SCAN,
       BEGIN,
       END,
+      BLEND,
+      OPACITY,
+      BRIGHTNESS,
+      ENVELOPE,
+      RATE,
+      SCAN_MODE,
+      SPEED,
+      NOISE,
+      PARTICLES,
+      TEXT,
+      MODULATORS,
+      MASK,
+      CLIP,
       KEY_COUNT,
     };
 
@@ -227,6 +711,28 @@ namespace YAML
       node[Layer::keys[Layer::SCAN]] = layer.scan;
       node[Layer::keys[Layer::BEGIN]] = layer.begin;
       node[Layer::keys[Layer::END]] = layer.end;
+      node[Layer::keys[Layer::BLEND]] = layer.blend;
+      node[Layer::keys[Layer::OPACITY]] = layer.opacity;
+      node[Layer::keys[Layer::BRIGHTNESS]] = layer.brightness;
+      node[Layer::keys[Layer::ENVELOPE]] = layer.envelope;
+      node[Layer::keys[Layer::RATE]] = layer.rate;
+      node[Layer::keys[Layer::SCAN_MODE]] = layer.scan_mode;
+      node[Layer::keys[Layer::SPEED]] = layer.speed;
+      node[Layer::keys[Layer::NOISE]] = layer.noise;
+      node[Layer::keys[Layer::PARTICLES]] = layer.particles;
+      node[Layer::keys[Layer::TEXT]] = layer.text;
+      if (layer.modulators.size() > 0)
+      {
+        node[Layer::keys[Layer::MODULATORS]] = layer.modulators;
+      }
+      if (layer.mask != MaskNone)
+      {
+        node[Layer::keys[Layer::MASK]] = layer.mask;
+      }
+      if (layer.clip)
+      {
+        node[Layer::keys[Layer::CLIP]] = layer.clip;
+      }
       return node;
     }
 
@@ -272,6 +778,45 @@ namespace YAML
         case Layer::END:
           layer.end = item.as<uint16_t>();
           break;
+        case Layer::BLEND:
+          layer.blend = item.as<uint16_t>();
+          break;
+        case Layer::OPACITY:
+          layer.opacity = item.as<uint16_t>();
+          break;
+        case Layer::BRIGHTNESS:
+          layer.brightness = item.as<uint16_t>();
+          break;
+        case Layer::ENVELOPE:
+          layer.envelope = item.as<Envelope>();
+          break;
+        case Layer::RATE:
+          layer.rate = item.as<uint32_t>();
+          break;
+        case Layer::SCAN_MODE:
+          layer.scan_mode = item.as<uint16_t>();
+          break;
+        case Layer::SPEED:
+          layer.speed = item.as<uint16_t>();
+          break;
+        case Layer::NOISE:
+          layer.noise = item.as<Noise>();
+          break;
+        case Layer::PARTICLES:
+          layer.particles = item.as<Particles>();
+          break;
+        case Layer::TEXT:
+          layer.text = item.as<Text>();
+          break;
+        case Layer::MODULATORS:
+          layer.modulators = item.as<std::vector<Modulator>>();
+          break;
+        case Layer::MASK:
+          layer.mask = item.as<uint16_t>();
+          break;
+        case Layer::CLIP:
+          layer.clip = item.as<bool>();
+          break;
         }
       }
 
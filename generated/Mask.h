@@ -0,0 +1,59 @@
+#pragma once
+
+#include <stdint.h>
+#include <vector>
+
+#include "base.h"
+#include "RGBColor.h"
+#include "Envelope.h"
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    MaskNone,
+    MaskLuminance,
+    MaskAlpha,
+    MASK_MODE_COUNT,
+  };
+
+  // how much a light drawn with color at alpha lets through, alpha
+  // being how much of the light was drawn whatever its color
+  inline uint8_t mask_cover(uint16_t mode, const Color &color, uint8_t alpha)
+  {
+    if (mode != MaskLuminance)
+    {
+      return alpha;
+    }
+    uint32_t level = (77 * static_cast<uint32_t>(color.red) +
+                      150 * static_cast<uint32_t>(color.green) +
+                      29 * static_cast<uint32_t>(color.blue)) >>
+                     8;
+    return static_cast<uint8_t>(level * alpha / MAXIMUM_LEVEL);
+  }
+
+  inline uint8_t mix_channel(uint8_t a, uint8_t b, uint8_t level)
+  {
+    return static_cast<uint8_t>(a + (static_cast<int32_t>(b) - a) * level / MAXIMUM_LEVEL);
+  }
+
+  // the color level of the way from one to the other
+  inline Color mix_colors(const Color &from, const Color &to, uint8_t level)
+  {
+    return Color(mix_channel(from.red, to.red, level),
+                 mix_channel(from.green, to.green, level),
+                 mix_channel(from.blue, to.blue, level));
+  }
+
+  // lights drawn off the strip
+  struct MaskBuffer
+  {
+    std::vector<Color> lights;
+    Color spare;
+    Color &get(uint16_t index) ALWAYS_INLINE
+    {
+      return (index < lights.size()) ? lights[index] : spare;
+    }
+    void update() ALWAYS_INLINE {}
+  };
+} // namespace glow
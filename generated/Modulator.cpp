@@ -0,0 +1,27 @@
+#include "Modulator.h"
+
+namespace glow
+{
+#ifndef MICRO_CONTROLLER
+  std::string Modulator::make_code()
+  {
+    std::stringstream s;
+    s << "{" << target << ","
+      << waveform << ","
+      << rate << ","
+      << depth << ","
+      << phase << ","
+      << stop << "}";
+    return s.str();
+  }
+
+  std::string Modulator::keys[Modulator::KEY_COUNT] = {
+      "target",
+      "waveform",
+      "rate",
+      "depth",
+      "phase",
+      "stop",
+  };
+#endif
+}
@@ -0,0 +1,227 @@
+#pragma once
+
+#include <stdint.h>
+#include <string>
+#include <algorithm>
+
+#include "base.h"
+#ifndef MICRO_CONTROLLER
+#include <yaml-cpp/yaml.h>
+#include <sstream>
+#endif
+
+#include "Interpolate.h"
+#include "Noise.h"
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    WaveSine,
+    WaveTriangle,
+    WaveSaw,
+    WaveSquare,
+    WaveRandom,
+    WAVEFORM_COUNT,
+  };
+
+  enum : uint16_t
+  {
+    ModBegin,
+    ModEnd,
+    ModScan,
+    ModOpacity,
+    ModRate,
+    ModHue,
+    ModSaturation,
+    ModValue,
+    MOD_TARGET_COUNT,
+  };
+
+  // the peak of a wave in 14 bit fixed point
+  const int32_t WAVE_UNIT = 1 << 14;
+
+  inline uint32_t clamp_offset(uint32_t value, int32_t offset, int32_t low, int32_t high)
+  {
+    return static_cast<uint32_t>(std::min(std::max(static_cast<int32_t>(value) + offset, low), high));
+  }
+
+  // swings a layer parameter about its set value, each cycle of the
+  // waveform lasting rate milliseconds starting phase degrees in
+  class Modulator
+  {
+  private:
+    uint16_t target{ModBegin};
+    uint16_t waveform{WaveSine};
+    uint32_t rate{0};
+    int16_t depth{0};
+    uint16_t phase{0};
+    uint16_t stop{0};
+
+  public:
+    Modulator() = default;
+
+    Modulator(uint16_t p_target, uint16_t p_waveform, uint32_t p_rate,
+              int16_t p_depth, uint16_t p_phase = 0, uint16_t p_stop = 0)
+        : target(p_target), waveform(p_waveform), rate(p_rate),
+          depth(p_depth), phase(p_phase), stop(p_stop)
+    {
+      setup();
+    }
+
+    void setup()
+    {
+      if (target >= MOD_TARGET_COUNT)
+      {
+        target = ModBegin;
+      }
+      if (waveform >= WAVEFORM_COUNT)
+      {
+        waveform = WaveSine;
+      }
+      phase %= 360;
+    }
+
+    uint16_t get_target() const ALWAYS_INLINE { return target; }
+    uint16_t get_waveform() const ALWAYS_INLINE { return waveform; }
+    uint32_t get_rate() const ALWAYS_INLINE { return rate; }
+    int16_t get_depth() const ALWAYS_INLINE { return depth; }
+    uint16_t get_phase() const ALWAYS_INLINE { return phase; }
+    uint16_t get_stop() const ALWAYS_INLINE { return stop; }
+    bool is_color() const ALWAYS_INLINE { return target >= ModHue; }
+
+    // the change to the target at time milliseconds
+    int32_t offset(uint32_t time) const
+    {
+      if (rate == 0 || depth == 0)
+      {
+        return 0;
+      }
+      return static_cast<int32_t>(depth) * wave(time) / WAVE_UNIT;
+    }
+
+    // the waveform at time between -WAVE_UNIT and WAVE_UNIT
+    int32_t wave(uint32_t time) const
+    {
+      const uint64_t at = static_cast<uint64_t>(time) * 65536 / rate +
+                          static_cast<uint64_t>(phase) * 65536 / 360;
+      const uint16_t turn = static_cast<uint16_t>(at);
+      switch (waveform)
+      {
+      case WaveTriangle:
+      {
+        const int32_t t = turn;
+        if (t < 16384)
+        {
+          return t;
+        }
+        if (t < 49152)
+        {
+          return 32768 - t;
+        }
+        return t - 65536;
+      }
+      case WaveSaw:
+        return static_cast<int32_t>(static_cast<int16_t>(turn)) / 2;
+      case WaveSquare:
+        return (turn < 32768) ? WAVE_UNIT : -WAVE_UNIT;
+      case WaveRandom:
+      {
+        // wanders between a random level each cycle
+        const uint32_t cycle = static_cast<uint32_t>(at >> 16);
+        const int32_t from = level(cycle);
+        const int32_t to = level(cycle + 1);
+        return from + (to - from) * static_cast<int32_t>(turn >> 2) / WAVE_UNIT;
+      }
+      }
+      return sine(turn);
+    }
+
+  private:
+    int32_t level(uint32_t cycle) const ALWAYS_INLINE
+    {
+      return static_cast<int32_t>(noise_hash(cycle, target, stop, 0) & 0x7fff) - WAVE_UNIT;
+    }
+
+#ifndef MICRO_CONTROLLER
+  public:
+    enum : uint8_t
+    {
+      TARGET,
+      WAVEFORM,
+      RATE,
+      DEPTH,
+      PHASE,
+      STOP,
+      KEY_COUNT,
+    };
+    static std::string keys[KEY_COUNT];
+    friend YAML::convert<Modulator>;
+    std::string make_code();
+#endif
+  };
+} // namespace glow
+
+#ifndef MICRO_CONTROLLER
+namespace YAML
+{
+  using glow::Modulator;
+
+  template <>
+  struct convert<Modulator>
+  {
+    static Node encode(const Modulator &modulator)
+    {
+      Node node;
+      node[Modulator::keys[Modulator::TARGET]] = modulator.target;
+      node[Modulator::keys[Modulator::WAVEFORM]] = modulator.waveform;
+      node[Modulator::keys[Modulator::RATE]] = modulator.rate;
+      node[Modulator::keys[Modulator::DEPTH]] = modulator.depth;
+      node[Modulator::keys[Modulator::PHASE]] = modulator.phase;
+      node[Modulator::keys[Modulator::STOP]] = modulator.stop;
+      return node;
+    }
+
+    static bool decode(const Node &node, Modulator &modulator)
+    {
+      if (!node.IsMap())
+      {
+        return false;
+      }
+
+      for (auto key = 0; key < Modulator::KEY_COUNT; ++key)
+      {
+        Node item = node[Modulator::keys[key]];
+        if (!item.IsDefined())
+        {
+          continue;
+        }
+
+        switch (key)
+        {
+        case Modulator::TARGET:
+          modulator.target = item.as<uint16_t>();
+          break;
+        case Modulator::WAVEFORM:
+          modulator.waveform = item.as<uint16_t>();
+          break;
+        case Modulator::RATE:
+          modulator.rate = item.as<uint32_t>();
+          break;
+        case Modulator::DEPTH:
+          modulator.depth = item.as<int16_t>();
+          break;
+        case Modulator::PHASE:
+          modulator.phase = item.as<uint16_t>();
+          break;
+        case Modulator::STOP:
+          modulator.stop = item.as<uint16_t>();
+          break;
+        }
+      }
+      modulator.setup();
+      return true;
+    }
+  };
+}
+#endif // MICRO_CONTROLLER
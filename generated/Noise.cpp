@@ -0,0 +1,23 @@
+#include "Noise.h"
+
+namespace glow
+{
+#ifndef MICRO_CONTROLLER
+  std::string Noise::make_code()
+  {
+    std::stringstream s;
+    s << "{" << kind << ","
+      << seed << ","
+      << scale << ","
+      << drift << "}";
+    return s.str();
+  }
+
+  std::string Noise::keys[Noise::KEY_COUNT] = {
+      "kind",
+      "seed",
+      "scale",
+      "drift",
+  };
+#endif
+}
@@ -0,0 +1,241 @@
+#pragma once
+
+#include <stdint.h>
+#include <string>
+
+#include "base.h"
+#include "Envelope.h"
+#ifndef MICRO_CONTROLLER
+#include <yaml-cpp/yaml.h>
+#include <sstream>
+#endif
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    NoiseNone,
+    NoiseFire,
+    NoisePlasma,
+    NoiseLava,
+    NoiseClouds,
+    NOISE_COUNT,
+  };
+
+  // one lattice cell in the fixed point noise space
+  const uint32_t NOISE_UNIT = 256;
+
+  struct NoisePreset
+  {
+    uint32_t scale;
+    uint32_t drift;
+    uint32_t octaves;
+  };
+
+  const NoisePreset noise_presets[NOISE_COUNT] = {
+      {0, 0, 0},
+      {64, 48, 2},
+      {48, 24, 1},
+      {32, 8, 2},
+      {40, 12, 3},
+  };
+
+  inline uint32_t noise_hash(uint32_t x, uint32_t y, uint32_t z, uint32_t seed)
+  {
+    uint32_t h = seed ^ x * 0x8da6b343u ^ y * 0xd8163841u ^ z * 0xcb1ab31fu;
+    h ^= h >> 16;
+    h *= 0x7feb352du;
+    h ^= h >> 15;
+    h *= 0x846ca68bu;
+    h ^= h >> 16;
+    return h;
+  }
+
+  inline uint32_t noise_fade(uint32_t t)
+  {
+    return t * t * (3 * NOISE_UNIT - 2 * t) / (NOISE_UNIT * NOISE_UNIT);
+  }
+
+  inline uint32_t noise_lerp(uint32_t a, uint32_t b, uint32_t t)
+  {
+    return static_cast<uint32_t>(static_cast<int32_t>(a) +
+                                 (static_cast<int32_t>(b) - static_cast<int32_t>(a)) *
+                                     static_cast<int32_t>(t) / static_cast<int32_t>(NOISE_UNIT));
+  }
+
+  // interpolates the lattice values around a fixed point position
+  inline uint32_t noise_value(uint32_t x, uint32_t y, uint32_t z, uint32_t seed)
+  {
+    const uint32_t xi = x / NOISE_UNIT, yi = y / NOISE_UNIT, zi = z / NOISE_UNIT;
+    const uint32_t u = noise_fade(x % NOISE_UNIT);
+    const uint32_t v = noise_fade(y % NOISE_UNIT);
+    const uint32_t w = noise_fade(z % NOISE_UNIT);
+
+    auto corner = [&](uint32_t dx, uint32_t dy, uint32_t dz)
+    {
+      return noise_hash(xi + dx, yi + dy, zi + dz, seed) & 0xff;
+    };
+    auto plane = [&](uint32_t dz)
+    {
+      return noise_lerp(
+          noise_lerp(corner(0, 0, dz), corner(1, 0, dz), u),
+          noise_lerp(corner(0, 1, dz), corner(1, 1, dz), u), v);
+    };
+    return noise_lerp(plane(0), plane(1), w);
+  }
+
+  // sums octaves at doubling frequency and halving amplitude
+  inline uint32_t noise_fractal(uint32_t x, uint32_t y, uint32_t z,
+                                uint32_t octaves, uint32_t seed)
+  {
+    uint32_t sum = 0, total = 0, amplitude = 128;
+    for (uint32_t octave = 0; octave < octaves; octave++)
+    {
+      sum += noise_value(x << octave, y << octave, z << octave, seed + octave) * amplitude;
+      total += amplitude;
+      amplitude >>= 1;
+    }
+    return (total == 0) ? 0 : sum / total;
+  }
+
+  class Noise
+  {
+  private:
+    uint16_t kind{NoiseNone};
+    uint32_t seed{0};
+    uint16_t scale{0};
+    uint16_t drift{0};
+
+  public:
+    Noise() = default;
+
+    Noise(uint16_t p_kind, uint32_t p_seed = 0,
+          uint16_t p_scale = 0, uint16_t p_drift = 0)
+        : kind(p_kind), seed(p_seed), scale(p_scale), drift(p_drift) {}
+
+    uint16_t get_kind() const ALWAYS_INLINE { return kind; }
+    uint32_t get_seed() const ALWAYS_INLINE { return seed; }
+    uint16_t get_scale() const ALWAYS_INLINE { return scale; }
+    uint16_t get_drift() const ALWAYS_INLINE { return drift; }
+
+    void setup()
+    {
+      if (kind >= NOISE_COUNT)
+      {
+        kind = NoiseNone;
+      }
+    }
+
+    // noise level of the light at column x and row y after spins steps
+    uint8_t sample(uint16_t x, uint16_t y, uint16_t rows, uint32_t spins) const
+    {
+      const NoisePreset &preset = noise_presets[kind];
+      const uint32_t step = (scale == 0) ? preset.scale : scale;
+      const uint32_t travel = (drift == 0) ? preset.drift : drift;
+
+      const uint32_t px = x * step, py = y * step;
+      const uint32_t time = spins * travel;
+      switch (kind)
+      {
+      case NoiseFire:
+      {
+        // flames rise through a field that cools with height
+        uint32_t v = noise_fractal(px, py + time, time / 2, preset.octaves, seed);
+        uint32_t heat = (y + 1u) * MAXIMUM_LEVEL / ((rows == 0) ? 1 : rows);
+        return static_cast<uint8_t>(v * heat / MAXIMUM_LEVEL);
+      }
+      case NoisePlasma:
+      {
+        uint32_t v = (noise_fractal(px, py, time, preset.octaves, seed) * 4) & 511;
+        if (v > MAXIMUM_LEVEL)
+        {
+          v = 511 - v;
+        }
+        return static_cast<uint8_t>(v);
+      }
+      case NoiseLava:
+      {
+        int32_t v = static_cast<int32_t>(noise_fractal(px, py, time, preset.octaves, seed)) * 2 - MAXIMUM_LEVEL;
+        if (v < 0)
+        {
+          v = -v;
+        }
+        return static_cast<uint8_t>(MAXIMUM_LEVEL - v);
+      }
+      case NoiseClouds:
+        return static_cast<uint8_t>(noise_fractal(px, py, time, preset.octaves, seed));
+      }
+      return 0;
+    }
+
+#ifndef MICRO_CONTROLLER
+    enum : uint8_t
+    {
+      KIND,
+      SEED,
+      SCALE,
+      DRIFT,
+      KEY_COUNT,
+    };
+    static std::string keys[KEY_COUNT];
+    friend YAML::convert<Noise>;
+    std::string make_code();
+#endif
+  };
+} // namespace glow
+
+#ifndef MICRO_CONTROLLER
+namespace YAML
+{
+  using glow::Noise;
+
+  template <>
+  struct convert<Noise>
+  {
+    static Node encode(const Noise &noise)
+    {
+      Node node;
+      node[Noise::keys[Noise::KIND]] = noise.kind;
+      node[Noise::keys[Noise::SEED]] = noise.seed;
+      node[Noise::keys[Noise::SCALE]] = noise.scale;
+      node[Noise::keys[Noise::DRIFT]] = noise.drift;
+      return node;
+    }
+
+    static bool decode(const Node &node, Noise &noise)
+    {
+      if (!node.IsMap())
+      {
+        return false;
+      }
+
+      for (auto key = 0; key < Noise::KEY_COUNT; ++key)
+      {
+        Node item = node[Noise::keys[key]];
+        if (!item.IsDefined())
+        {
+          continue;
+        }
+
+        switch (key)
+        {
+        case Noise::KIND:
+          noise.kind = item.as<uint16_t>();
+          break;
+        case Noise::SEED:
+          noise.seed = item.as<uint32_t>();
+          break;
+        case Noise::SCALE:
+          noise.scale = item.as<uint16_t>();
+          break;
+        case Noise::DRIFT:
+          noise.drift = item.as<uint16_t>();
+          break;
+        }
+      }
+      noise.setup();
+      return true;
+    }
+  };
+}
+#endif // MICRO_CONTROLLER
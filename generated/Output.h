@@ -0,0 +1,55 @@
+#pragma once
+
+#include <stdint.h>
+#include <vector>
+
+#include "base.h"
+#include "RGBColor.h"
+
+namespace glow
+{
+  // lookup tables correcting each channel for gamma,
+  // white balance and the brightness cap
+  struct Output
+  {
+    uint8_t red[256];
+    uint8_t green[256];
+    uint8_t blue[256];
+
+    Color correct(const Color &color) const ALWAYS_INLINE
+    {
+      return Color(red[color.red], green[color.green], blue[color.blue]);
+    }
+  };
+
+  // corrects each color for the target lights on update while
+  // get returns colors uncorrected so layers blend as designed.
+  // call update after Frame::spin on esphome.
+  template <typename LIGHT>
+  class OutputLight
+  {
+  private:
+    const Output &output;
+    LIGHT &target;
+    std::vector<Color> cells;
+
+  public:
+    OutputLight(const Output &p_output, LIGHT &p_target, uint16_t p_size)
+        : output(p_output), target(p_target), cells(p_size) {}
+
+    uint16_t size() const { return cells.size(); }
+
+    Color &get(uint16_t index) { return cells[index]; }
+
+    void update()
+    {
+      for (uint16_t i = 0; i < cells.size(); i++)
+      {
+        target.get(i) = output.correct(cells[i]).get();
+      }
+#ifndef ESPHOME_CONTROLLER
+      target.update();
+#endif
+    }
+  };
+} // namespace glow
@@ -0,0 +1,27 @@
+#include "Particles.h"
+
+namespace glow
+{
+#ifndef MICRO_CONTROLLER
+  std::string Particles::make_code()
+  {
+    std::stringstream s;
+    s << "{" << kind << ","
+      << spawn << ","
+      << lifetime << ","
+      << fade << ","
+      << tail << ","
+      << seed << "}";
+    return s.str();
+  }
+
+  std::string Particles::keys[Particles::KEY_COUNT] = {
+      "kind",
+      "spawn",
+      "lifetime",
+      "fade",
+      "tail",
+      "seed",
+  };
+#endif
+}
@@ -0,0 +1,291 @@
+#pragma once
+
+#include <stdint.h>
+#include <string>
+
+#include "base.h"
+#include "Envelope.h"
+#ifndef MICRO_CONTROLLER
+#include <yaml-cpp/yaml.h>
+#include <sstream>
+#endif
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    ParticleNone,
+    ParticleTwinkle,
+    ParticleMeteor,
+    ParticleConfetti,
+    PARTICLE_COUNT,
+  };
+
+  enum : uint16_t
+  {
+    FadeLinear,
+    FadeQuadratic,
+    FadeSudden,
+    FADE_COUNT,
+  };
+
+  const uint16_t MAX_PARTICLES = 32;
+  const uint16_t DEFAULT_SPAWN = 50;
+  const uint16_t DEFAULT_LIFETIME = 16;
+  const uint16_t DEFAULT_TAIL = 4;
+  const uint32_t DEFAULT_PARTICLES = 2463534242;
+
+  struct Particle
+  {
+    uint16_t position;
+    uint16_t color;
+    uint16_t age;
+  };
+
+  class Particles
+  {
+  private:
+    uint16_t kind{ParticleNone};
+    uint16_t spawn{0};
+    uint16_t lifetime{0};
+    uint16_t fade{FadeLinear};
+    uint16_t tail{0};
+    uint32_t seed{0};
+
+    // variant
+    Particle pool[MAX_PARTICLES];
+    uint16_t count{0};
+    uint32_t state{DEFAULT_PARTICLES};
+
+  public:
+    Particles() = default;
+
+    Particles(uint16_t p_kind, uint16_t p_spawn = 0, uint16_t p_lifetime = 0,
+              uint16_t p_fade = FadeLinear, uint16_t p_tail = 0, uint32_t p_seed = 0)
+        : kind(p_kind), spawn(p_spawn), lifetime(p_lifetime),
+          fade(p_fade), tail(p_tail), seed(p_seed)
+    {
+      setup();
+    }
+
+    uint16_t get_kind() const ALWAYS_INLINE { return kind; }
+    uint16_t get_spawn() const ALWAYS_INLINE { return spawn; }
+    uint16_t get_lifetime() const ALWAYS_INLINE { return lifetime; }
+    uint16_t get_fade() const ALWAYS_INLINE { return fade; }
+    uint16_t get_tail() const ALWAYS_INLINE { return tail; }
+    uint32_t get_seed() const ALWAYS_INLINE { return seed; }
+    uint16_t get_count() const ALWAYS_INLINE { return count; }
+    const Particle &get(uint16_t index) const ALWAYS_INLINE { return pool[index]; }
+
+    // clamps the settings and restarts the particles from the seed
+    void setup()
+    {
+      if (kind >= PARTICLE_COUNT)
+      {
+        kind = ParticleNone;
+      }
+      if (fade >= FADE_COUNT)
+      {
+        fade = FadeLinear;
+      }
+      count = 0;
+      state = (seed == 0) ? DEFAULT_PARTICLES : seed;
+    }
+
+    uint32_t random() ALWAYS_INLINE
+    {
+      state ^= state << 13;
+      state ^= state >> 17;
+      state ^= state << 5;
+      return state;
+    }
+
+    uint16_t life() const ALWAYS_INLINE
+    {
+      return (lifetime == 0 && kind != ParticleMeteor) ? DEFAULT_LIFETIME : lifetime;
+    }
+
+    uint16_t tail_length() const ALWAYS_INLINE
+    {
+      return (tail == 0) ? DEFAULT_TAIL : tail;
+    }
+
+    // brightness of a particle, or of the light k behind the head
+    // of a meteor, shaped by the fade curve
+    uint8_t level(const Particle &particle, uint16_t k) const
+    {
+      uint32_t remaining;
+      switch (kind)
+      {
+      case ParticleMeteor:
+        remaining = MAXIMUM_LEVEL - static_cast<uint32_t>(k) * MAXIMUM_LEVEL / (tail_length() + 1u);
+        break;
+      case ParticleTwinkle:
+      {
+        int32_t t = static_cast<int32_t>(static_cast<uint32_t>(particle.age) * MAXIMUM_LEVEL / life());
+        t = 2 * t - MAXIMUM_LEVEL;
+        if (t < 0)
+        {
+          t = -t;
+        }
+        remaining = static_cast<uint32_t>(MAXIMUM_LEVEL - t);
+        break;
+      }
+      default:
+        remaining = MAXIMUM_LEVEL - static_cast<uint32_t>(particle.age) * MAXIMUM_LEVEL / life();
+      }
+
+      switch (fade)
+      {
+      case FadeQuadratic:
+        remaining = remaining * remaining / MAXIMUM_LEVEL;
+        break;
+      case FadeSudden:
+        if (remaining > 0)
+        {
+          remaining = MAXIMUM_LEVEL;
+        }
+        break;
+      }
+      return static_cast<uint8_t>(remaining);
+    }
+
+    // ages, moves and retires particles then spawns new ones
+    // between first and last
+    void update(uint16_t first, uint16_t last, uint16_t length, uint16_t speed)
+    {
+      const uint16_t span = last - first;
+      if (span == 0)
+      {
+        return;
+      }
+      if (speed == 0)
+      {
+        speed = 1;
+      }
+
+      const uint16_t span_life = life();
+      uint16_t alive = 0;
+      for (uint16_t i = 0; i < count; i++)
+      {
+        Particle particle = pool[i];
+        particle.age++;
+        if (kind == ParticleMeteor)
+        {
+          particle.position += speed;
+          if (particle.position - first >= span + tail_length())
+          {
+            continue;
+          }
+        }
+        if (span_life > 0 && particle.age >= span_life)
+        {
+          continue;
+        }
+        pool[alive++] = particle;
+      }
+      count = alive;
+
+      const uint32_t chance = (spawn == 0) ? DEFAULT_SPAWN : spawn;
+      uint32_t spawns = chance / 100;
+      if (random() % 100 < chance % 100)
+      {
+        spawns++;
+      }
+      for (; spawns > 0 && count < MAX_PARTICLES; spawns--)
+      {
+        Particle particle{first, 0, 0};
+        if (kind != ParticleMeteor)
+        {
+          particle.position = first + static_cast<uint16_t>(random() % span);
+        }
+        particle.color = particle.position;
+        if (kind != ParticleTwinkle)
+        {
+          particle.color = static_cast<uint16_t>(random() % length);
+        }
+        pool[count++] = particle;
+      }
+    }
+
+#ifndef MICRO_CONTROLLER
+    enum : uint8_t
+    {
+      KIND,
+      SPAWN,
+      LIFETIME,
+      FADE,
+      TAIL,
+      SEED,
+      KEY_COUNT,
+    };
+    static std::string keys[KEY_COUNT];
+    friend YAML::convert<Particles>;
+    std::string make_code();
+#endif
+  };
+} // namespace glow
+
+#ifndef MICRO_CONTROLLER
+namespace YAML
+{
+  using glow::Particles;
+
+  template <>
+  struct convert<Particles>
+  {
+    static Node encode(const Particles &particles)
+    {
+      Node node;
+      node[Particles::keys[Particles::KIND]] = particles.kind;
+      node[Particles::keys[Particles::SPAWN]] = particles.spawn;
+      node[Particles::keys[Particles::LIFETIME]] = particles.lifetime;
+      node[Particles::keys[Particles::FADE]] = particles.fade;
+      node[Particles::keys[Particles::TAIL]] = particles.tail;
+      node[Particles::keys[Particles::SEED]] = particles.seed;
+      return node;
+    }
+
+    static bool decode(const Node &node, Particles &particles)
+    {
+      if (!node.IsMap())
+      {
+        return false;
+      }
+
+      for (auto key = 0; key < Particles::KEY_COUNT; ++key)
+      {
+        Node item = node[Particles::keys[key]];
+        if (!item.IsDefined())
+        {
+          continue;
+        }
+
+        switch (key)
+        {
+        case Particles::KIND:
+          particles.kind = item.as<uint16_t>();
+          break;
+        case Particles::SPAWN:
+          particles.spawn = item.as<uint16_t>();
+          break;
+        case Particles::LIFETIME:
+          particles.lifetime = item.as<uint16_t>();
+          break;
+        case Particles::FADE:
+          particles.fade = item.as<uint16_t>();
+          break;
+        case Particles::TAIL:
+          particles.tail = item.as<uint16_t>();
+          break;
+        case Particles::SEED:
+          particles.seed = item.as<uint32_t>();
+          break;
+        }
+      }
+      particles.setup();
+      return true;
+    }
+  };
+}
+#endif // MICRO_CONTROLLER
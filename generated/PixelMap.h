@@ -0,0 +1,63 @@
+#pragma once
+
+#include <stdint.h>
+#include <vector>
+
+#include "base.h"
+#include "RGBColor.h"
+
+namespace glow
+{
+  // places each physical light on a virtual grid of columns by rows
+  class PixelMap
+  {
+  private:
+    uint16_t columns{0};
+    uint16_t rows{1};
+    std::vector<uint16_t> lookup;
+
+  public:
+    PixelMap() = default;
+
+    PixelMap(uint16_t p_columns, uint16_t p_rows,
+             std::vector<uint16_t> p_lookup)
+        : columns(p_columns), rows(p_rows), lookup(p_lookup) {}
+
+    uint16_t get_columns() const ALWAYS_INLINE { return columns; }
+    uint16_t get_rows() const ALWAYS_INLINE { return rows; }
+    uint16_t get_length() const ALWAYS_INLINE { return columns * rows; }
+    uint16_t get_count() const ALWAYS_INLINE { return lookup.size(); }
+    uint16_t offset(uint16_t index) const ALWAYS_INLINE { return lookup[index]; }
+  };
+
+  // renders onto the virtual grid of a pixel map and copies each
+  // mapped cell to its physical light on update.
+  // call update after Frame::spin on esphome.
+  template <typename LIGHT>
+  class MapLight
+  {
+  private:
+    const PixelMap &map;
+    LIGHT &target;
+    std::vector<Color> cells;
+
+  public:
+    MapLight(const PixelMap &p_map, LIGHT &p_target)
+        : map(p_map), target(p_target), cells(p_map.get_length()) {}
+
+    uint16_t size() const { return map.get_length(); }
+
+    Color &get(uint16_t index) { return cells[index]; }
+
+    void update()
+    {
+      for (uint16_t i = 0; i < map.get_count(); i++)
+      {
+        target.get(i) = cells[map.offset(i)].get();
+      }
+#ifndef ESPHOME_CONTROLLER
+      target.update();
+#endif
+    }
+  };
+} // namespace glow
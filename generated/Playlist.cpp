@@ -0,0 +1,67 @@
+#include "Playlist.h"
+
+namespace glow
+{
+  static uint8_t mix_level(uint8_t a, uint8_t b, uint8_t level)
+  {
+    return static_cast<uint8_t>(a + (static_cast<int32_t>(b) - a) * level / MAXIMUM_LEVEL);
+  }
+
+  Color mix_transition(uint16_t transition, const Color &from, const Color &to,
+                       uint16_t index, uint16_t length, uint8_t level)
+  {
+    switch (transition)
+    {
+    case TransitionCrossfade:
+      return Color(mix_level(from.red, to.red, level),
+                   mix_level(from.green, to.green, level),
+                   mix_level(from.blue, to.blue, level));
+    case TransitionWipe:
+      return (static_cast<uint32_t>(index) * MAXIMUM_LEVEL <
+              static_cast<uint32_t>(length) * level)
+                 ? to
+                 : from;
+    case TransitionDissolve:
+      // each light switches at its own level
+      return (static_cast<uint8_t>((index * 2654435761u) >> 24) < level) ? to : from;
+    }
+    return to;
+  }
+
+  bool Playlist::setup(uint16_t p_length, uint16_t p_rows, Frame *catalog, uint16_t count)
+  {
+    length = p_length;
+    frames.clear();
+    slots.clear();
+    std::vector<uint16_t> indexes;
+    for (auto &entry : entries)
+    {
+      if (entry.index >= count)
+      {
+        return false;
+      }
+      uint16_t slot = 0;
+      while (slot < indexes.size() && indexes[slot] != entry.index)
+      {
+        slot++;
+      }
+      if (slot == indexes.size())
+      {
+        Frame &source = catalog[entry.index];
+        indexes.push_back(entry.index);
+        frames.push_back(source);
+        frames.back().set_wiring(wiring);
+        uint32_t interval = source.get_interval();
+        if (!frames.back().setup(p_length, p_rows, (interval == 0) ? DEFAULT_INTERVAL : interval))
+        {
+          return false;
+        }
+      }
+      slots.push_back(slot);
+    }
+    buffer.lights.assign(length, Color());
+    current = 0;
+    clock = 0;
+    return entries.size() > 0;
+  }
+} // namespace glow
@@ -0,0 +1,153 @@
+#pragma once
+#include <vector>
+
+#include "base.h"
+#include "Frame.h"
+
+namespace glow
+{
+  enum Transition : uint16_t
+  {
+    TransitionCut,
+    TransitionCrossfade,
+    TransitionWipe,
+    TransitionDissolve,
+    TRANSITION_COUNT,
+  };
+
+  const uint32_t DEFAULT_DURATION = 10000;
+  const uint32_t DEFAULT_INTERVAL = 48;
+
+  // play the catalog frame at index for duration milliseconds, the
+  // first fade of them taking over from the entry before
+  struct PlaylistEntry
+  {
+    uint16_t index = 0;
+    uint32_t duration = DEFAULT_DURATION;
+    uint16_t transition = TransitionCut;
+    uint32_t fade = 0;
+  };
+
+  Color mix_transition(uint16_t transition, const Color &from, const Color &to,
+                       uint16_t index, uint16_t length, uint8_t level);
+
+  class Playlist
+  {
+  private:
+    struct Buffer
+    {
+      std::vector<Color> lights;
+      Color spare;
+      Color &get(uint16_t index) ALWAYS_INLINE
+      {
+        return (index < lights.size()) ? lights[index] : spare;
+      }
+      void update() ALWAYS_INLINE {}
+    };
+
+    std::vector<PlaylistEntry> entries;
+    // entries naming the same catalog frame share one copy of it
+    std::vector<Frame> frames;
+    std::vector<uint16_t> slots;
+    Buffer buffer;
+    uint16_t length = 0;
+    uint16_t wiring = Progressive;
+    uint16_t current = 0;
+    uint32_t clock = 0;
+    uint32_t next = 0;
+    uint32_t last = 0;
+    uint32_t elapsed = 0;
+
+  public:
+    Playlist() = default;
+
+    Playlist(std::initializer_list<PlaylistEntry> p_entries)
+    {
+      entries = p_entries;
+    }
+
+    bool setup(uint16_t p_length, uint16_t p_rows, Frame *catalog, uint16_t count);
+
+    uint32_t get_tick() const
+    {
+      uint32_t tick = UINT32_MAX;
+      for (auto &frame : frames)
+      {
+        if (frame.get_tick() < tick)
+        {
+          tick = frame.get_tick();
+        }
+      }
+      return tick;
+    }
+
+    uint16_t get_current() const ALWAYS_INLINE { return current; }
+    // wire every frame as the lights they play on, before setup
+    void set_wiring(uint16_t p_wiring) ALWAYS_INLINE { wiring = p_wiring; }
+    size_t get_size() const ALWAYS_INLINE { return entries.size(); }
+
+    template <typename LIGHT>
+    void spin(LIGHT &light)
+    {
+      spin(light, (elapsed == 0) ? frames[slots[current]].get_interval() : elapsed);
+    }
+
+    // draw the entry playing, mixed with the one before while it
+    // takes over, and advance both by elapsed milliseconds
+    template <typename LIGHT>
+    void spin(LIGHT &light, uint32_t p_elapsed)
+    {
+      if (entries.size() == 0)
+      {
+        return;
+      }
+      const PlaylistEntry &entry = entries[current];
+      Frame &frame = frames[slots[current]];
+      uint16_t before = (current + entries.size() - 1) % entries.size();
+      if (clock < entry.fade && slots[before] != slots[current])
+      {
+        frames[slots[before]].spin_layers(buffer, p_elapsed);
+        frame.spin_layers(light, p_elapsed);
+        uint8_t level = clock * MAXIMUM_LEVEL / entry.fade;
+        for (uint16_t i = 0; i < length; i++)
+        {
+          Color color(light.get(i).get());
+          color = mix_transition(entry.transition, buffer.get(i), color, i, length, level);
+          light.get(i) = color.get();
+        }
+      }
+      else
+      {
+        frame.spin_layers(light, p_elapsed);
+      }
+#ifndef ESPHOME_CONTROLLER
+      light.update();
+#endif
+
+      clock += p_elapsed;
+      if (clock >= entry.duration)
+      {
+        clock = 0;
+        current = (current + 1) % entries.size();
+      }
+    }
+
+#ifdef ESPHOME_CONTROLLER
+    bool is_ready() ALWAYS_INLINE
+    {
+#define millis() esphome::millis()
+      const uint32_t now = millis();
+      const uint32_t tick = get_tick();
+
+      if (next - now > tick)
+      {
+        next = now + tick;
+        elapsed = (last == 0) ? frames[slots[current]].get_interval() : now - last;
+        last = now;
+        return true;
+      }
+      return false;
+    }
+#endif
+  };
+} // namespace glow
@@ -0,0 +1,89 @@
+#pragma once
+
+#include <stdint.h>
+#include <vector>
+
+#include "base.h"
+#include "RGBColor.h"
+#include "Envelope.h"
+
+namespace glow
+{
+  // milliamps one LED draws with each channel at full level
+  // and while dark
+  struct PowerModel
+  {
+    uint16_t red{20};
+    uint16_t green{20};
+    uint16_t blue{20};
+    uint16_t idle{1};
+
+    // current above idle in 1/255 milliamps
+    uint32_t current(const Color &color) const ALWAYS_INLINE
+    {
+      return static_cast<uint32_t>(red) * color.red +
+             static_cast<uint32_t>(green) * color.green +
+             static_cast<uint32_t>(blue) * color.blue;
+    }
+  };
+
+  // holds colors until update then dims them all evenly so the
+  // target draws no more than budget milliamps.
+  // call update after Frame::spin on esphome.
+  template <typename LIGHT>
+  class PowerLimiter
+  {
+  private:
+    PowerModel model;
+    uint32_t budget;
+    LIGHT &target;
+    std::vector<Color> cells;
+    uint8_t level{MAXIMUM_LEVEL};
+
+  public:
+    PowerLimiter(const PowerModel &p_model, uint32_t p_budget,
+                 LIGHT &p_target, uint16_t p_size)
+        : model(p_model), budget(p_budget), target(p_target), cells(p_size) {}
+
+    uint16_t size() const { return cells.size(); }
+
+    uint8_t get_level() const ALWAYS_INLINE { return level; }
+
+    Color &get(uint16_t index) { return cells[index]; }
+
+    void update()
+    {
+      uint64_t current = 0;
+      for (const Color &color : cells)
+      {
+        current += model.current(color);
+      }
+
+      level = MAXIMUM_LEVEL;
+      const uint32_t idle = static_cast<uint32_t>(model.idle) * cells.size();
+      if (budget > 0 && current > 0)
+      {
+        const uint64_t available = (budget > idle) ? static_cast<uint64_t>(budget - idle) * MAXIMUM_LEVEL : 0;
+        if (current > available)
+        {
+          level = static_cast<uint8_t>(available * MAXIMUM_LEVEL / current);
+        }
+      }
+
+      for (uint16_t i = 0; i < cells.size(); i++)
+      {
+        Color color = cells[i];
+        if (level < MAXIMUM_LEVEL)
+        {
+          color.red = scale_level(color.red, level);
+          color.green = scale_level(color.green, level);
+          color.blue = scale_level(color.blue, level);
+        }
+        target.get(i) = color.get();
+      }
+#ifndef ESPHOME_CONTROLLER
+      target.update();
+#endif
+    }
+  };
+} // namespace glow
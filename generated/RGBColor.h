@@ -33,6 +33,16 @@ namespace glow
       white = 0;
     }
 
+#ifdef ESPHOME_CONTROLLER
+    Color(const esphome::Color &color) ALWAYS_INLINE
+    {
+      red = color.r;
+      green = color.g;
+      blue = color.b;
+      white = color.w;
+    }
+#endif
+
     inline Color &operator=(const Color &color)
     {
       red = color.red;
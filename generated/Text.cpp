@@ -0,0 +1,29 @@
+#include "Text.h"
+
+namespace glow
+{
+#ifndef MICRO_CONTROLLER
+  std::string Text::make_code()
+  {
+    std::stringstream s;
+    s << "{" << direction << ","
+      << gradient << ","
+      << width << ","
+      << height << ",{";
+    for (auto alpha : bitmap)
+    {
+      s << static_cast<uint16_t>(alpha) << ",";
+    }
+    s << "}}";
+    return s.str();
+  }
+
+  std::string Text::keys[Text::KEY_COUNT] = {
+      "direction",
+      "gradient",
+      "width",
+      "height",
+      "bitmap",
+  };
+#endif
+}
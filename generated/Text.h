@@ -0,0 +1,207 @@
+#pragma once
+
+#include <stdint.h>
+#include <string>
+#include <vector>
+
+#include "base.h"
+#ifndef MICRO_CONTROLLER
+#include <yaml-cpp/yaml.h>
+#include <sstream>
+#endif
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    TextLeft,
+    TextRight,
+    TextUp,
+    TextDown,
+    TextStatic,
+    TEXT_DIRECTION_COUNT,
+  };
+
+  // a message rendered as rows of coverage that scrolls through
+  // the grid at the layer's speed
+  class Text
+  {
+  private:
+    uint16_t direction{TextLeft};
+    bool gradient{false};
+    uint16_t width{0};
+    uint16_t height{0};
+    std::vector<uint8_t> bitmap;
+
+    // variant
+    uint16_t position{0};
+
+  public:
+    Text() = default;
+
+    Text(uint16_t p_direction, bool p_gradient, uint16_t p_width, uint16_t p_height,
+         const std::vector<uint8_t> &p_bitmap = {})
+        : direction(p_direction), gradient(p_gradient),
+          width(p_width), height(p_height), bitmap(p_bitmap)
+    {
+      setup();
+    }
+
+    uint16_t get_direction() const ALWAYS_INLINE { return direction; }
+    bool get_gradient() const ALWAYS_INLINE { return gradient; }
+    uint16_t get_width() const ALWAYS_INLINE { return width; }
+    uint16_t get_height() const ALWAYS_INLINE { return height; }
+    const std::vector<uint8_t> &get_bitmap() const ALWAYS_INLINE { return bitmap; }
+
+    void setup()
+    {
+      if (direction >= TEXT_DIRECTION_COUNT)
+      {
+        direction = TextLeft;
+      }
+      if (bitmap.size() < static_cast<size_t>(width) * height)
+      {
+        width = 0;
+        height = 0;
+        bitmap.clear();
+      }
+      position = 0;
+    }
+
+    // number of positions for the message to cross the grid
+    uint16_t period(uint16_t columns, uint16_t rows) const
+    {
+      switch (direction)
+      {
+      case TextLeft:
+      case TextRight:
+        return width + columns;
+      case TextUp:
+      case TextDown:
+        return height + rows;
+      }
+      return 1;
+    }
+
+    void update(uint16_t speed, uint16_t columns, uint16_t rows)
+    {
+      if (speed == 0)
+      {
+        speed = 1;
+      }
+      position = (position + speed) % period(columns, rows);
+    }
+
+    // coverage of the message at column x and row y of the grid,
+    // setting column to the message column shown there
+    uint8_t sample(uint16_t x, uint16_t y, uint16_t columns, uint16_t rows, int32_t &column) const
+    {
+      int32_t sx = x, sy = y;
+      switch (direction)
+      {
+      case TextLeft:
+        sx += position - columns;
+        break;
+      case TextRight:
+        sx += width - position;
+        break;
+      case TextUp:
+        sy += position - rows;
+        break;
+      case TextDown:
+        sy += height - position;
+        break;
+      }
+      column = sx;
+      if (sx < 0 || sx >= width || sy < 0 || sy >= height)
+      {
+        return 0;
+      }
+      return bitmap[sy * width + sx];
+    }
+
+#ifndef MICRO_CONTROLLER
+    enum : uint8_t
+    {
+      DIRECTION,
+      GRADIENT,
+      WIDTH,
+      HEIGHT,
+      BITMAP,
+      KEY_COUNT,
+    };
+    static std::string keys[KEY_COUNT];
+    friend YAML::convert<Text>;
+    std::string make_code();
+#endif
+  };
+} // namespace glow
+
+#ifndef MICRO_CONTROLLER
+namespace YAML
+{
+  using glow::Text;
+
+  template <>
+  struct convert<Text>
+  {
+    static Node encode(const Text &text)
+    {
+      Node node;
+      node[Text::keys[Text::DIRECTION]] = text.direction;
+      node[Text::keys[Text::GRADIENT]] = text.gradient;
+      node[Text::keys[Text::WIDTH]] = text.width;
+      node[Text::keys[Text::HEIGHT]] = text.height;
+      Node bitmap;
+      for (auto alpha : text.bitmap)
+      {
+        bitmap.push_back(static_cast<uint16_t>(alpha));
+      }
+      node[Text::keys[Text::BITMAP]] = bitmap;
+      return node;
+    }
+
+    static bool decode(const Node &node, Text &text)
+    {
+      if (!node.IsMap())
+      {
+        return false;
+      }
+
+      for (auto key = 0; key < Text::KEY_COUNT; ++key)
+      {
+        Node item = node[Text::keys[key]];
+        if (!item.IsDefined())
+        {
+          continue;
+        }
+
+        switch (key)
+        {
+        case Text::DIRECTION:
+          text.direction = item.as<uint16_t>();
+          break;
+        case Text::GRADIENT:
+          text.gradient = item.as<bool>();
+          break;
+        case Text::WIDTH:
+          text.width = item.as<uint16_t>();
+          break;
+        case Text::HEIGHT:
+          text.height = item.as<uint16_t>();
+          break;
+        case Text::BITMAP:
+          text.bitmap.clear();
+          for (auto alpha : item)
+          {
+            text.bitmap.push_back(static_cast<uint8_t>(alpha.as<uint16_t>()));
+          }
+          break;
+        }
+      }
+      text.setup();
+      return true;
+    }
+  };
+}
+#endif // MICRO_CONTROLLER
@@ -0,0 +1,201 @@
+#pragma once
+
+#include <stdint.h>
+#include <vector>
+
+#include "base.h"
+#include "RGBColor.h"
+#include "Envelope.h"
+
+namespace glow
+{
+  enum : uint16_t
+  {
+    FormatRGB,
+    FormatRGBW,
+    FormatRGBWW,
+    COLOR_FORMAT_COUNT,
+  };
+
+  enum : uint16_t
+  {
+    WhiteMinimum,
+    WhiteTemperature,
+    WHITE_MODE_COUNT,
+  };
+
+  // a color for lights with a white channel,
+  // and on RGBWW lights a warm white channel.
+  // esphome colors carry one white so warm is left off there.
+  struct WhiteColor
+  {
+    uint8_t red = 0;
+    uint8_t green = 0;
+    uint8_t blue = 0;
+    uint8_t white = 0;
+    uint8_t warm = 0;
+
+#ifdef ESPHOME_CONTROLLER
+    esphome::Color get() const ALWAYS_INLINE
+    {
+      return esphome::Color{red, green, blue, white};
+    }
+#else
+    WhiteColor get() const ALWAYS_INLINE
+    {
+      return *this;
+    }
+#endif
+  };
+
+  // extracts the white channels of colors for RGBW and RGBWW lights
+  // given the red, green and blue levels each white LED matches.
+  struct White
+  {
+    uint16_t format;
+    uint16_t mode;
+    uint8_t cool[3];
+    uint8_t warm[3];
+
+    WhiteColor extract(const Color &color) const
+    {
+      uint8_t rgb[3] = {color.red, color.green, color.blue};
+      WhiteColor result;
+      if (format == FormatRGBW)
+      {
+        result.white = take(rgb, cool);
+      }
+      else if (format == FormatRGBWW)
+      {
+        mix(rgb, result.white, result.warm);
+        if (mode == WhiteMinimum)
+        {
+          result.warm = result.white / 2;
+          result.white -= result.warm;
+        }
+      }
+      result.red = rgb[0];
+      result.green = rgb[1];
+      result.blue = rgb[2];
+      return result;
+    }
+
+  private:
+    static uint8_t fits(const uint8_t rgb[3], const uint8_t levels[3])
+    {
+      uint16_t amount = MAXIMUM_LEVEL;
+      for (uint8_t ch = 0; ch < 3; ch++)
+      {
+        if (levels[ch] > 0)
+        {
+          uint16_t level = static_cast<uint16_t>(rgb[ch]) * MAXIMUM_LEVEL / levels[ch];
+          if (level < amount)
+          {
+            amount = level;
+          }
+        }
+      }
+      return amount;
+    }
+
+    static uint8_t take(uint8_t rgb[3], const uint8_t levels[3])
+    {
+      uint8_t amount = fits(rgb, levels);
+      for (uint8_t ch = 0; ch < 3; ch++)
+      {
+        rgb[ch] -= static_cast<uint16_t>(amount) * levels[ch] / MAXIMUM_LEVEL;
+      }
+      return amount;
+    }
+
+    bool fits_both(const uint8_t rgb[3], int32_t a, int32_t b) const
+    {
+      for (uint8_t ch = 0; ch < 3; ch++)
+      {
+        if (a * cool[ch] + b * warm[ch] > static_cast<int32_t>(rgb[ch]) * MAXIMUM_LEVEL)
+        {
+          return false;
+        }
+      }
+      return true;
+    }
+
+    // the mix of both LEDs taking the most light uses one
+    // LED alone or exactly fills two of the channels.
+    void mix(uint8_t rgb[3], uint8_t &white, uint8_t &warm_white) const
+    {
+      const int32_t cool_sum = cool[0] + cool[1] + cool[2];
+      const int32_t warm_sum = warm[0] + warm[1] + warm[2];
+      int32_t best = -1, best_cool = 0, best_warm = 0;
+      auto attempt = [&](int32_t a, int32_t b)
+      {
+        if (a > MAXIMUM_LEVEL)
+          a = MAXIMUM_LEVEL;
+        if (b > MAXIMUM_LEVEL)
+          b = MAXIMUM_LEVEL;
+        if (a < 0 || b < 0 || !fits_both(rgb, a, b))
+          return;
+        int32_t moved = a * cool_sum + b * warm_sum;
+        if (moved > best)
+        {
+          best = moved;
+          best_cool = a;
+          best_warm = b;
+        }
+      };
+
+      attempt(fits(rgb, cool), 0);
+      attempt(0, fits(rgb, warm));
+      for (uint8_t i = 0; i < 2; i++)
+      {
+        for (uint8_t j = i + 1; j < 3; j++)
+        {
+          int32_t det = static_cast<int32_t>(cool[i]) * warm[j] - static_cast<int32_t>(cool[j]) * warm[i];
+          if (det == 0)
+            continue;
+          int32_t a = (static_cast<int32_t>(rgb[i]) * warm[j] - static_cast<int32_t>(rgb[j]) * warm[i]) * MAXIMUM_LEVEL / det;
+          int32_t b = (static_cast<int32_t>(cool[i]) * rgb[j] - static_cast<int32_t>(cool[j]) * rgb[i]) * MAXIMUM_LEVEL / det;
+          attempt(a, b);
+        }
+      }
+
+      for (uint8_t ch = 0; ch < 3; ch++)
+      {
+        rgb[ch] -= (best_cool * cool[ch] + best_warm * warm[ch]) / MAXIMUM_LEVEL;
+      }
+      white = best_cool;
+      warm_white = best_warm;
+    }
+  };
+
+  // extracts the white channels of each color for RGBW target
+  // lights on update while get returns colors as they were set.
+  // call update after Frame::spin on esphome.
+  template <typename LIGHT>
+  class WhiteLight
+  {
+  private:
+    const White &white;
+    LIGHT &target;
+    std::vector<Color> cells;
+
+  public:
+    WhiteLight(const White &p_white, LIGHT &p_target, uint16_t p_size)
+        : white(p_white), target(p_target), cells(p_size) {}
+
+    uint16_t size() const { return cells.size(); }
+
+    Color &get(uint16_t index) { return cells[index]; }
+
+    void update()
+    {
+      for (uint16_t i = 0; i < cells.size(); i++)
+      {
+        target.get(i) = white.extract(cells[i]).get();
+      }
+#ifndef ESPHOME_CONTROLLER
+      target.update();
+#endif
+    }
+  };
+} // namespace glow
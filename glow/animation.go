@@ -0,0 +1,234 @@
+package glow
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// DefaultFrameDelay is the delay in milliseconds of animation frames
+// whose source gives none.
+const DefaultFrameDelay = 100
+
+// Animation is a sequence of pictures with the delay in milliseconds
+// that each is shown. A still picture is an animation of one frame.
+// Frames of an image sequence have no delay and follow the layer rate.
+type Animation struct {
+	Frames []*image.NRGBA
+	Delays []uint32
+}
+
+func (anim *Animation) Len() int {
+	return len(anim.Frames)
+}
+
+// Timed reports whether the frames carry their own delays.
+func (anim *Animation) Timed() bool {
+	for _, delay := range anim.Delays {
+		if delay > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Resample returns the animation resized to cols by rows.
+func (anim *Animation) Resample(rows, cols int, filter imaging.ResampleFilter) *Animation {
+	resized := &Animation{
+		Frames: make([]*image.NRGBA, len(anim.Frames)),
+		Delays: append([]uint32{}, anim.Delays...),
+	}
+	for i, frame := range anim.Frames {
+		resized.Frames[i] = imaging.Resize(frame, cols, rows, filter)
+	}
+	return resized
+}
+
+// LoadAnimation reads a still picture, an animated GIF or PNG, or the
+// numbered sequence path names, and resamples each frame.
+func LoadAnimation(path string, rows, cols int, filter imaging.ResampleFilter) (anim *Animation, err error) {
+	if IsSequence(path) {
+		var paths []string
+		paths, err = SequencePaths(path)
+		if err == nil {
+			anim, err = LoadSequence(paths)
+		}
+		if err == nil {
+			anim = anim.Resample(rows, cols, filter)
+		}
+		return
+	}
+
+	var data []byte
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gif":
+		anim, err = DecodeGIF(bytes.NewReader(data))
+	case ".png", ".apng":
+		if IsAPNG(data) {
+			anim, err = DecodeAPNG(bytes.NewReader(data))
+			break
+		}
+		anim, err = decodeStill(data)
+	default:
+		anim, err = decodeStill(data)
+	}
+	if err != nil {
+		return
+	}
+	anim = anim.Resample(rows, cols, filter)
+	return
+}
+
+func decodeStill(data []byte) (*Animation, error) {
+	pic, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, err
+	}
+	return &Animation{
+		Frames: []*image.NRGBA{imaging.Clone(pic)},
+		Delays: []uint32{0},
+	}, nil
+}
+
+// DecodeGIF composes the frames of an animated GIF
+// following each frame's disposal.
+func DecodeGIF(rdr io.Reader) (anim *Animation, err error) {
+	var source *gif.GIF
+	source, err = gif.DecodeAll(rdr)
+	if err != nil {
+		return
+	}
+
+	bounds := image.Rect(0, 0, source.Config.Width, source.Config.Height)
+	canvas := image.NewNRGBA(bounds)
+	anim = &Animation{}
+	for i, frame := range source.Image {
+		var previous *image.NRGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(source.Disposal) {
+			disposal = source.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = imaging.Clone(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		anim.Frames = append(anim.Frames, imaging.Clone(canvas))
+		anim.Delays = append(anim.Delays, gifDelay(source.Delay[i]))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	if len(anim.Frames) == 0 {
+		err = fmt.Errorf("DecodeGIF no frames")
+	}
+	return
+}
+
+// gifDelay converts hundredths of a second to milliseconds. Like
+// browsers, delays too short to be intended play at the default.
+func gifDelay(delay int) uint32 {
+	if delay < 2 {
+		return DefaultFrameDelay
+	}
+	return uint32(delay) * 10
+}
+
+// SequenceMark stands for the frame number in the name of a numbered
+// sequence, walk_*.png for walk_1.png, walk_2.png ... walk_10.png.
+const SequenceMark = "*"
+
+var sequencePattern = regexp.MustCompile(`^(.*?)(\d+)(\.[^.]+)$`)
+
+// IsSequence reports whether path names a numbered sequence.
+func IsSequence(path string) bool {
+	return strings.Contains(filepath.Base(path), SequenceMark)
+}
+
+// SequenceName returns the name of the numbered sequence the file at
+// path belongs to, or path when its name has no number.
+func SequenceName(path string) string {
+	dir, name := filepath.Split(path)
+	match := sequencePattern.FindStringSubmatch(name)
+	if match == nil {
+		return path
+	}
+	return filepath.Join(dir, match[1]+SequenceMark+match[3])
+}
+
+// SequencePaths lists the files of the sequence path names in numeric
+// order. A path without the mark is a sequence of one.
+func SequencePaths(path string) (paths []string, err error) {
+	dir, name := filepath.Split(path)
+	prefix, suffix, found := strings.Cut(name, SequenceMark)
+	if !found {
+		paths = []string{path}
+		return
+	}
+
+	var entries []os.DirEntry
+	entries, err = os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return
+	}
+
+	numbers := make(map[string]int)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		rest, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || len(rest) <= len(suffix) ||
+			!strings.EqualFold(rest[len(rest)-len(suffix):], suffix) {
+			continue
+		}
+		number, err := strconv.ParseUint(rest[:len(rest)-len(suffix)], 10, 32)
+		if err != nil {
+			continue
+		}
+		numbers[entry.Name()] = int(number)
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return numbers[filepath.Base(paths[i])] < numbers[filepath.Base(paths[j])]
+	})
+	return
+}
+
+// LoadSequence reads one frame from each path. The frames are untimed.
+func LoadSequence(paths []string) (anim *Animation, err error) {
+	anim = &Animation{}
+	for _, path := range paths {
+		var pic image.Image
+		pic, err = imaging.Open(path, imaging.AutoOrientation(true))
+		if err != nil {
+			return
+		}
+		anim.Frames = append(anim.Frames, imaging.Clone(pic))
+		anim.Delays = append(anim.Delays, 0)
+	}
+	if len(anim.Frames) == 0 {
+		err = fmt.Errorf("LoadSequence no frames")
+	}
+	return
+}
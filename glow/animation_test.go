@@ -0,0 +1,177 @@
+package glow
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+var animationColors = []color.NRGBA{
+	{255, 0, 0, 255},
+	{0, 255, 0, 255},
+	{0, 0, 255, 255},
+}
+
+func writeTestGIF(t *testing.T, path string, delays ...int) {
+	anim := &gif.GIF{}
+	for i, delay := range delays {
+		frame := image.NewPaletted(image.Rect(0, 0, 8, 4), palette.Plan9)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(frame.Palette.Index(animationColors[i%3]))
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testFrames(count int) []*image.NRGBA {
+	frames := make([]*image.NRGBA, count)
+	for i := range frames {
+		frames[i] = imaging.New(8, 4, animationColors[i%3])
+	}
+	return frames
+}
+
+func checkAnimation(t *testing.T, anim *Animation, delays ...uint32) {
+	if anim.Len() != len(delays) {
+		t.Fatalf("frames want %d got %d", len(delays), anim.Len())
+	}
+	for i, frame := range anim.Frames {
+		if frame.Bounds().Dx() != 4 || frame.Bounds().Dy() != 2 {
+			t.Fatalf("frame %d not resampled %v", i, frame.Bounds())
+		}
+		if frame.NRGBAAt(1, 1) != animationColors[i%3] {
+			t.Fatalf("frame %d want %v got %v", i, animationColors[i%3], frame.NRGBAAt(1, 1))
+		}
+		if anim.Delays[i] != delays[i] {
+			t.Fatalf("frame %d delay want %d got %d", i, delays[i], anim.Delays[i])
+		}
+	}
+}
+
+func TestAnimationGIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "walk.gif")
+	writeTestGIF(t, path, 5, 20, 0)
+	anim, err := LoadAnimation(path, 2, 4, imaging.NearestNeighbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnimation(t, anim, 50, 200, DefaultFrameDelay)
+}
+
+func TestAnimationAPNG(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeAPNG(&buf, testFrames(3), 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPNG(buf.Bytes()) {
+		t.Fatalf("IsAPNG false for animated png")
+	}
+	path := filepath.Join(t.TempDir(), "walk.png")
+	if err = os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := LoadAnimation(path, 2, 4, imaging.NearestNeighbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnimation(t, anim, 40, 40, 40)
+}
+
+func TestAnimationSequence(t *testing.T) {
+	dir := t.TempDir()
+	frames := testFrames(3)
+	for i, name := range []string{"walk_1.png", "walk_2.png", "walk_10.png", "other_3.png"} {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frames[i%3]); err != nil {
+			t.Fatal(err)
+		}
+		if IsAPNG(buf.Bytes()) {
+			t.Fatalf("IsAPNG true for still png")
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	name := SequenceName(filepath.Join(dir, "walk_2.png"))
+	if name != filepath.Join(dir, "walk_*.png") {
+		t.Fatalf("SequenceName got %s", name)
+	}
+	anim, err := LoadAnimation(name, 2, 4, imaging.Box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnimation(t, anim, 0, 0, 0)
+
+	anim, err = LoadAnimation(filepath.Join(dir, "other_*.png"), 2, 4, imaging.Box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAnimation(t, anim, 0)
+
+	// a numbered file is a still picture unless named as a sequence
+	anim, err = LoadAnimation(filepath.Join(dir, "walk_2.png"), 2, 4, imaging.Box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anim.Len() != 1 || anim.Frames[0].NRGBAAt(1, 1) != animationColors[1] {
+		t.Fatalf("still want one %v frame got %d", animationColors[1], anim.Len())
+	}
+
+	if _, err = LoadAnimation(filepath.Join(dir, "run_*.png"), 2, 4, imaging.Box); err == nil {
+		t.Fatalf("empty sequence loaded")
+	}
+}
+
+func TestLayerAnimation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "walk.gif")
+	writeTestGIF(t, path, 10, 5, 5)
+
+	layer := &Layer{ImageName: path}
+	frame := &Frame{Interval: 50}
+	frame.AddLayers(layer)
+	if err := frame.Setup(8, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := layer.LoadImage(2, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	// a 4 by 2 grid, light 7 is the last column of the second row
+	light := NewImageLight(8, 2)
+	shown := func() color.NRGBA {
+		frame.Spin(light)
+		return light.Get(7)
+	}
+	for i, want := range []int{0, 0, 1, 2, 0, 0, 1, 2} {
+		if got := shown(); got != animationColors[want] {
+			t.Fatalf("spin %d want %v got %v", i, animationColors[want], got)
+		}
+	}
+
+	// the picture delays, not the layer rate, pace a timed animation
+	layer.Rate = 25
+	layer.picture, layer.shown = 0, 0
+	for i, want := range []int{0, 0, 1, 2} {
+		if got := shown(); got != animationColors[want] {
+			t.Fatalf("rate spin %d want %v got %v", i, animationColors[want], got)
+		}
+	}
+}
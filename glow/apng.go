@@ -0,0 +1,349 @@
+package glow
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+type apngWriter struct {
+	w        io.Writer
+	err      error
+	sequence uint32
+}
+
+func (aw *apngWriter) chunk(name string, data []byte) {
+	if aw.err != nil {
+		return
+	}
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	footer := binary.BigEndian.AppendUint32(nil, crc.Sum32())
+
+	for _, b := range [][]byte{header, data, footer} {
+		if _, aw.err = aw.w.Write(b); aw.err != nil {
+			return
+		}
+	}
+}
+
+func (aw *apngWriter) next() uint32 {
+	seq := aw.sequence
+	aw.sequence++
+	return seq
+}
+
+// EncodeAPNG writes images as an endlessly looping animated png
+// with a delay of interval milliseconds between frames.
+// All images must share the bounds of the first.
+func EncodeAPNG(w io.Writer, images []*image.NRGBA, interval uint32) error {
+	if len(images) == 0 {
+		return fmt.Errorf("EncodeAPNG no images")
+	}
+
+	bounds := images[0].Bounds()
+	width, height := uint32(bounds.Dx()), uint32(bounds.Dy())
+
+	aw := &apngWriter{w: w}
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+
+	ihdr := binary.BigEndian.AppendUint32(nil, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	// 8 bit depth, truecolor with alpha, deflate, adaptive filter, no interlace
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+	aw.chunk("IHDR", ihdr)
+
+	actl := binary.BigEndian.AppendUint32(nil, uint32(len(images)))
+	actl = binary.BigEndian.AppendUint32(actl, 0)
+	aw.chunk("acTL", actl)
+
+	for i, img := range images {
+		if img.Bounds().Size() != bounds.Size() {
+			return fmt.Errorf("EncodeAPNG image %d size %v want %v",
+				i, img.Bounds().Size(), bounds.Size())
+		}
+
+		fctl := binary.BigEndian.AppendUint32(nil, aw.next())
+		fctl = binary.BigEndian.AppendUint32(fctl, width)
+		fctl = binary.BigEndian.AppendUint32(fctl, height)
+		fctl = binary.BigEndian.AppendUint32(fctl, 0)
+		fctl = binary.BigEndian.AppendUint32(fctl, 0)
+		fctl = binary.BigEndian.AppendUint16(fctl, uint16(min(interval, 0xffff)))
+		fctl = binary.BigEndian.AppendUint16(fctl, 1000)
+		// dispose none, blend source
+		fctl = append(fctl, 0, 0)
+		aw.chunk("fcTL", fctl)
+
+		data, err := compressNRGBA(img)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			aw.chunk("IDAT", data)
+		} else {
+			aw.chunk("fdAT", append(binary.BigEndian.AppendUint32(nil, aw.next()), data...))
+		}
+	}
+
+	aw.chunk("IEND", nil)
+	return aw.err
+}
+
+func compressNRGBA(img *image.NRGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	b := img.Bounds()
+	rowLength := b.Dx() * 4
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		start := img.PixOffset(b.Min.X, y)
+		// filter type none
+		if _, err := zw.Write([]byte{0}); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(img.Pix[start : start+rowLength]); err != nil {
+			return nil, err
+		}
+	}
+	err := zw.Close()
+	return buf.Bytes(), err
+}
+
+const (
+	apngDisposeNone = iota
+	apngDisposeBackground
+	apngDisposePrevious
+)
+
+const (
+	apngBlendSource = iota
+	apngBlendOver
+)
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+type apngFrame struct {
+	width, height    uint32
+	x, y             uint32
+	delayNum         uint16
+	delayDen         uint16
+	dispose, blendOp byte
+	data             []byte
+}
+
+// IsAPNG reports whether data is a PNG with an animation control chunk.
+func IsAPNG(data []byte) bool {
+	chunks, err := readPNGChunks(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	for _, chunk := range chunks {
+		switch chunk.kind {
+		case "acTL":
+			return true
+		case "IDAT":
+			return false
+		}
+	}
+	return false
+}
+
+func readPNGChunks(rdr io.Reader) (chunks []pngChunk, err error) {
+	signature := make([]byte, len(pngSignature))
+	if _, err = io.ReadFull(rdr, signature); err != nil {
+		return
+	}
+	if !bytes.Equal(signature, pngSignature) {
+		err = fmt.Errorf("not a PNG")
+		return
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(rdr, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunk := pngChunk{kind: string(header[4:8]), data: make([]byte, length)}
+		if _, err = io.ReadFull(rdr, chunk.data); err != nil {
+			return
+		}
+		if _, err = io.ReadFull(rdr, header[:4]); err != nil {
+			return
+		}
+		chunks = append(chunks, chunk)
+		if chunk.kind == "IEND" {
+			return
+		}
+	}
+}
+
+// DecodeAPNG composes the frames of an animated PNG following each
+// frame's dispose and blend operations. A PNG without animation
+// decodes as a single frame.
+func DecodeAPNG(rdr io.Reader) (anim *Animation, err error) {
+	var chunks []pngChunk
+	chunks, err = readPNGChunks(rdr)
+	if err != nil {
+		return
+	}
+	if len(chunks) == 0 || chunks[0].kind != "IHDR" || len(chunks[0].data) != 13 {
+		err = fmt.Errorf("DecodeAPNG missing header")
+		return
+	}
+	ihdr := chunks[0].data
+
+	// chunks such as the palette and transparency are shared by every frame
+	var shared []pngChunk
+	var frames []*apngFrame
+	var current *apngFrame
+	for _, chunk := range chunks[1:] {
+		switch chunk.kind {
+		case "acTL", "IEND":
+		case "fcTL":
+			if len(chunk.data) != 26 {
+				err = fmt.Errorf("DecodeAPNG bad frame control")
+				return
+			}
+			d := chunk.data
+			current = &apngFrame{
+				width:    binary.BigEndian.Uint32(d[4:]),
+				height:   binary.BigEndian.Uint32(d[8:]),
+				x:        binary.BigEndian.Uint32(d[12:]),
+				y:        binary.BigEndian.Uint32(d[16:]),
+				delayNum: binary.BigEndian.Uint16(d[20:]),
+				delayDen: binary.BigEndian.Uint16(d[22:]),
+				dispose:  d[24],
+				blendOp:  d[25],
+			}
+			frames = append(frames, current)
+		case "IDAT":
+			if current != nil {
+				current.data = append(current.data, chunk.data...)
+			} else if len(frames) == 0 && !hasChunk(chunks, "acTL") {
+				// a still PNG is its own single frame
+				current = &apngFrame{
+					width:  binary.BigEndian.Uint32(ihdr[0:]),
+					height: binary.BigEndian.Uint32(ihdr[4:]),
+					data:   chunk.data,
+				}
+				frames = append(frames, current)
+			}
+		case "fdAT":
+			if current == nil || len(chunk.data) < 4 {
+				err = fmt.Errorf("DecodeAPNG frame data without control")
+				return
+			}
+			current.data = append(current.data, chunk.data[4:]...)
+		default:
+			if len(frames) == 0 {
+				shared = append(shared, chunk)
+			}
+		}
+	}
+	if len(frames) == 0 {
+		err = fmt.Errorf("DecodeAPNG no frames")
+		return
+	}
+
+	width := int(binary.BigEndian.Uint32(ihdr[0:]))
+	height := int(binary.BigEndian.Uint32(ihdr[4:]))
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	anim = &Animation{}
+	for i, frame := range frames {
+		var pic image.Image
+		pic, err = frame.decode(ihdr, shared)
+		if err != nil {
+			return
+		}
+		bounds := image.Rect(int(frame.x), int(frame.y),
+			int(frame.x+frame.width), int(frame.y+frame.height))
+
+		dispose := frame.dispose
+		if i == 0 && dispose == apngDisposePrevious {
+			dispose = apngDisposeBackground
+		}
+		var previous *image.NRGBA
+		if dispose == apngDisposePrevious {
+			previous = imaging.Clone(canvas)
+		}
+
+		op := draw.Over
+		if frame.blendOp == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, bounds, pic, image.Point{}, op)
+		anim.Frames = append(anim.Frames, imaging.Clone(canvas))
+		anim.Delays = append(anim.Delays, frame.delay())
+
+		switch dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, bounds, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+	return
+}
+
+func hasChunk(chunks []pngChunk, kind string) bool {
+	for _, chunk := range chunks {
+		if chunk.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// decode rebuilds the frame as a standalone PNG for image/png.
+func (frame *apngFrame) decode(ihdr []byte, shared []pngChunk) (image.Image, error) {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	aw := &apngWriter{w: &buf}
+	header := append([]byte{}, ihdr...)
+	binary.BigEndian.PutUint32(header[0:], frame.width)
+	binary.BigEndian.PutUint32(header[4:], frame.height)
+	aw.chunk("IHDR", header)
+	for _, chunk := range shared {
+		aw.chunk(chunk.kind, chunk.data)
+	}
+	aw.chunk("IDAT", frame.data)
+	aw.chunk("IEND", nil)
+	if aw.err != nil {
+		return nil, aw.err
+	}
+	return png.Decode(&buf)
+}
+
+// delay converts the frame's fraction of a second to milliseconds.
+func (frame *apngFrame) delay() uint32 {
+	den := uint32(frame.delayDen)
+	if den == 0 {
+		den = 100
+	}
+	delay := uint32(frame.delayNum) * 1000 / den
+	if delay == 0 {
+		return DefaultFrameDelay
+	}
+	return delay
+}
@@ -0,0 +1,59 @@
+package glow
+
+import "image/color"
+
+type BlendMode uint16
+
+const (
+	BlendReplace BlendMode = iota
+	BlendAdd
+	BlendMultiply
+	BlendScreen
+	BlendMax
+	BlendMin
+	// BlendAlphaOver mixes by the source's own alpha. Chroma colors are
+	// opaque, so it draws as Replace unless the layer's opacity is below
+	// 100, while image pixels keep their alpha.
+	BlendAlphaOver
+	BlendDifference
+	BLEND_COUNT
+)
+
+func blendChannel(mode BlendMode, dst, src, alpha uint8) uint8 {
+	d, s := int(dst), int(src)
+	switch mode {
+	case BlendAdd:
+		return uint8(min(d+s, 255))
+	case BlendMultiply:
+		return uint8(d * s / 255)
+	case BlendScreen:
+		return uint8(255 - (255-d)*(255-s)/255)
+	case BlendMax:
+		return uint8(max(d, s))
+	case BlendMin:
+		return uint8(min(d, s))
+	case BlendAlphaOver:
+		a := int(alpha)
+		return uint8((s*a + d*(255-a)) / 255)
+	case BlendDifference:
+		if d > s {
+			return uint8(d - s)
+		}
+		return uint8(s - d)
+	}
+	return src
+}
+
+// Blend combines the source color with the destination color
+// already on the light.
+func (mode BlendMode) Blend(dst, src color.NRGBA) color.NRGBA {
+	if mode == BlendReplace {
+		return src
+	}
+	return color.NRGBA{
+		R: blendChannel(mode, dst.R, src.R, src.A),
+		G: blendChannel(mode, dst.G, src.G, src.A),
+		B: blendChannel(mode, dst.B, src.B, src.A),
+		A: 255,
+	}
+}
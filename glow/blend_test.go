@@ -0,0 +1,106 @@
+package glow
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+type testLight struct {
+	lights []color.NRGBA
+}
+
+func newTestLight(length int) *testLight {
+	return &testLight{lights: make([]color.NRGBA, length)}
+}
+
+func (tl *testLight) Get(i uint16) color.NRGBA    { return tl.lights[i] }
+func (tl *testLight) Set(i uint16, c color.NRGBA) { tl.lights[i] = c }
+func (tl *testLight) Refresh()                    {}
+
+func TestBlend(t *testing.T) {
+	dst := color.NRGBA{200, 100, 0, 255}
+	src := color.NRGBA{100, 200, 50, 255}
+	half := color.NRGBA{100, 200, 50, 127}
+
+	tests := []struct {
+		mode BlendMode
+		src  color.NRGBA
+		want color.NRGBA
+	}{
+		{BlendReplace, src, src},
+		{BlendAdd, src, color.NRGBA{255, 255, 50, 255}},
+		{BlendMultiply, src, color.NRGBA{78, 78, 0, 255}},
+		{BlendScreen, src, color.NRGBA{222, 222, 50, 255}},
+		{BlendMax, src, color.NRGBA{200, 200, 50, 255}},
+		{BlendMin, src, color.NRGBA{100, 100, 0, 255}},
+		{BlendAlphaOver, src, color.NRGBA{100, 200, 50, 255}},
+		{BlendAlphaOver, half, color.NRGBA{150, 149, 24, 255}},
+		{BlendDifference, src, color.NRGBA{100, 100, 50, 255}},
+	}
+
+	for i, test := range tests {
+		got := test.mode.Blend(dst, test.src)
+		if got != test.want {
+			t.Fatalf("%d mode %d want %v got %v", i, test.mode, test.want, got)
+		}
+	}
+}
+
+func TestLayerBlend(t *testing.T) {
+	red := &Layer{}
+	red.Chroma.AddColors(HSV{HueRed, 1, 1})
+	blue := &Layer{Blend: BlendAdd}
+	blue.Chroma.AddColors(HSV{HueBlue, 1, 1})
+
+	var frame Frame
+	frame.AddLayers(red, blue)
+	if err := frame.Setup(8, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	light := newTestLight(8)
+	frame.Spin(light)
+	want := color.NRGBA{255, 0, 255, 255}
+	for i, c := range light.lights {
+		if c != want {
+			t.Fatalf("light %d want %v got %v", i, want, c)
+		}
+	}
+}
+
+func TestLayerAlphaOver(t *testing.T) {
+	pic := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	for x := 1; x < 4; x++ {
+		pic.SetNRGBA(x, 0, color.NRGBA{B: 255, A: 128})
+	}
+	spin := func(mode BlendMode) []color.NRGBA {
+		red := &Layer{}
+		red.Chroma.AddColors(HSV{HueRed, 1, 1})
+		picture := &Layer{Blend: mode}
+		picture.Chroma.AddColors(HSV{HueGreen, 1, 1})
+
+		var frame Frame
+		frame.AddLayers(red, picture)
+		if err := frame.Setup(4, 1); err != nil {
+			t.Fatal(err)
+		}
+		picture.animation = &Animation{Frames: []*image.NRGBA{pic}, Delays: []uint32{0}}
+		light := newTestLight(4)
+		frame.Spin(light)
+		return light.lights
+	}
+
+	replace, over := spin(BlendReplace), spin(BlendAlphaOver)
+	if want := (color.NRGBA{B: 255, A: 255}); replace[3] != want {
+		t.Fatalf("replace want %v got %v", want, replace[3])
+	}
+	if want := (color.NRGBA{R: 127, B: 128, A: 255}); over[3] != want {
+		t.Fatalf("alpha over want %v got %v", want, over[3])
+	}
+	// transparent pixels leave the layer below
+	red := color.NRGBA{R: 255, A: 255}
+	if replace[0] != red || over[0] != red {
+		t.Fatalf("transparent want %v got %v and %v", red, replace[0], over[0])
+	}
+}
@@ -6,13 +6,28 @@ import (
 )
 
 type Chroma struct {
-	Length   uint16 `yaml:"length" json:"length"`
-	HueShift int16  `yaml:"hue_shift" json:"hue_shift"`
-	Colors   []HSV  `yaml:"colors" json:"colors"`
+	Length        uint16        `yaml:"length" json:"length"`
+	HueShift      int16         `yaml:"hue_shift" json:"hue_shift"`
+	Colors        []HSV         `yaml:"colors" json:"colors"`
+	Interpolation Interpolation `yaml:"interpolation" json:"interpolation"`
+	// Positions optionally places each color along the layer as a
+	// percent. Missing colors and PositionAuto share the space evenly.
+	Positions []uint16 `yaml:"positions,omitempty" json:"positions,omitempty"`
+	// Palette names a shared palette whose colors fill Colors when
+	// resolved, unless Override keeps the layer's own colors.
+	Palette  string `yaml:"palette,omitempty" json:"palette,omitempty"`
+	Override bool   `yaml:"override,omitempty" json:"override,omitempty"`
 	// segmentSize uint16
 	quick_color color.NRGBA
+	stops       []uint16
+	// firmware renders with the device's integer math when set
+	firmware *HSVGradient
+	// modulated holds the modulator offsets applied to each color
+	modulated []HSV
 }
 
+const PositionAuto uint16 = 0xffff
+
 func (chroma *Chroma) Setup(length uint16,
 	source HSV, target HSV, hueShift int16) error {
 	chroma.Length = length
@@ -32,15 +47,94 @@ func (chroma *Chroma) Validate() error {
 		return fmt.Errorf("Chroma.Setup zero length")
 	}
 
+	chroma.unmodulate()
 	if len(chroma.Colors) == 0 {
 		chroma.Colors = append(chroma.Colors, HSV{0, 0, 1})
 	}
 
+	if chroma.Interpolation >= INTERPOLATION_COUNT {
+		chroma.Interpolation = InterpolateHSV
+	}
+
 	chroma.quick_color = chroma.Colors[0].ToRGB()
+	chroma.stops = nil
+	if len(chroma.Positions) > 0 && len(chroma.Colors) > 1 {
+		chroma.stops = chroma.StopPositions()
+		for i, position := range chroma.stops {
+			chroma.stops[i] = uint16(uint32(position) * uint32(chroma.Length) / 100)
+		}
+	}
+	if chroma.firmware != nil {
+		chroma.firmware = NewHSVGradient(chroma)
+	}
 	return nil
 }
 
+// SetParity switches Map and UpdateColors to the firmware's integer
+// math so that colors match the device bit for bit.
+func (chroma *Chroma) SetParity(parity bool) {
+	chroma.firmware = nil
+	if parity {
+		chroma.firmware = NewHSVGradient(chroma)
+	}
+}
+
+func (chroma *Chroma) Parity() bool {
+	return chroma.firmware != nil
+}
+
+// StopPositions returns the percent position of each color, filling
+// in those left automatic and keeping them in order.
+func (chroma *Chroma) StopPositions() []uint16 {
+	count := len(chroma.Colors)
+	positions := make([]int, count)
+	last := 0
+	for i := range positions {
+		positions[i] = -1
+		if i < len(chroma.Positions) && chroma.Positions[i] <= 100 {
+			positions[i] = max(int(chroma.Positions[i]), last)
+			last = positions[i]
+		}
+	}
+	if count == 0 {
+		return []uint16{}
+	}
+	if positions[0] < 0 {
+		positions[0] = 0
+	}
+	if positions[count-1] < 0 {
+		positions[count-1] = 100
+	}
+
+	for i := 1; i < count; i++ {
+		if positions[i] >= 0 {
+			continue
+		}
+		j := i
+		for positions[j] < 0 {
+			j++
+		}
+		from, to, steps := positions[i-1], positions[j], j-i+1
+		for k := i; k < j; k++ {
+			positions[k] = from + (to-from)*(k-i+1)/steps
+		}
+		i = j
+	}
+
+	stops := make([]uint16, count)
+	for i, position := range positions {
+		stops[i] = uint16(position)
+	}
+	return stops
+}
+
 func (chroma *Chroma) Map(index uint16) color.NRGBA {
+	if chroma.firmware != nil {
+		return chroma.firmware.Map(index)
+	}
+	if chroma.stops != nil {
+		return chroma.mapStops(index)
+	}
 	segmentCount := uint16(len(chroma.Colors)) - 1
 	if segmentCount == 0 || index == 0 {
 		return chroma.quick_color
@@ -51,11 +145,42 @@ func (chroma *Chroma) Map(index uint16) color.NRGBA {
 	offset := index % segmentSize
 	first := chroma.Colors[colorIndex]
 	last := chroma.Colors[colorIndex+1]
-	result := first.ToGradient(last, offset, segmentSize)
+	return chroma.mix(first, last, offset, segmentSize)
+}
+
+func (chroma *Chroma) mix(first, last HSV, offset, size uint16) color.NRGBA {
+	if !chroma.Interpolation.IsHSV() {
+		return chroma.Interpolation.Mix(first.ToRGB(), last.ToRGB(),
+			int(offset), int(size))
+	}
+	result := first.ToGradientIn(last, offset, size, chroma.Interpolation)
 	return result.ToRGB()
 }
 
+// mapStops finds the segment holding index between positioned stops.
+// Stops at the same position make a hard edge.
+func (chroma *Chroma) mapStops(index uint16) color.NRGBA {
+	stops := chroma.stops
+	last := len(stops) - 1
+	if index <= stops[0] {
+		return chroma.quick_color
+	}
+	if index >= stops[last] {
+		return chroma.Colors[last].ToRGB()
+	}
+	segment := 0
+	for segment+1 < last && stops[segment+1] <= index {
+		segment++
+	}
+	return chroma.mix(chroma.Colors[segment], chroma.Colors[segment+1],
+		index-stops[segment], stops[segment+1]-stops[segment])
+}
+
 func (chroma *Chroma) UpdateColors() {
+	if chroma.firmware != nil {
+		chroma.firmware.Update()
+		return
+	}
 	if chroma.HueShift == 0 {
 		return
 	}
@@ -76,6 +201,64 @@ func (chroma *Chroma) UpdateColors() {
 	chroma.quick_color = chroma.Colors[0].ToRGB()
 }
 
+// Modulate moves the color at stop from its own by hue degrees and
+// saturation and value percent, replacing the offsets of the last call.
+func (chroma *Chroma) Modulate(stop uint16, hue, saturation, value int32) {
+	if int(stop) >= len(chroma.Colors) {
+		return
+	}
+	if chroma.firmware != nil {
+		chroma.firmware.Modulate(stop, hue*int32(hue_limit)/360,
+			saturation*255/100, value*255/100)
+		return
+	}
+	if len(chroma.modulated) != len(chroma.Colors) {
+		chroma.unmodulate()
+		chroma.modulated = make([]HSV, len(chroma.Colors))
+	}
+
+	hsv, applied := &chroma.Colors[stop], &chroma.modulated[stop]
+	base := HSV{Hue: hsv.Hue - applied.Hue,
+		Saturation: hsv.Saturation - applied.Saturation,
+		Value:      hsv.Value - applied.Value}
+	next := HSV{Hue: base.Hue + float32(hue),
+		Saturation: min(max(base.Saturation+float32(saturation)/100, 0), 1),
+		Value:      min(max(base.Value+float32(value)/100, 0), 1)}
+	*applied = HSV{Hue: next.Hue - base.Hue,
+		Saturation: next.Saturation - base.Saturation,
+		Value:      next.Value - base.Value}
+	next.Hue = wrapHue(next.Hue)
+	*hsv = next
+
+	if stop == 0 {
+		chroma.quick_color = chroma.Colors[0].ToRGB()
+	}
+}
+
+// unmodulate takes the modulator offsets back out of the colors.
+func (chroma *Chroma) unmodulate() {
+	for i := range chroma.modulated {
+		if i >= len(chroma.Colors) {
+			break
+		}
+		hsv, applied := &chroma.Colors[i], &chroma.modulated[i]
+		hsv.Hue = wrapHue(hsv.Hue - applied.Hue)
+		hsv.Saturation -= applied.Saturation
+		hsv.Value -= applied.Value
+	}
+	chroma.modulated = nil
+}
+
+func wrapHue(hue float32) float32 {
+	for hue >= HueMax {
+		hue -= HueMax
+	}
+	for hue < 0 {
+		hue += HueMax
+	}
+	return hue
+}
+
 func (chroma *Chroma) AddColors(hsv ...HSV) {
 	chroma.Colors = append(chroma.Colors, hsv...)
 }
@@ -89,7 +272,15 @@ func (chroma *Chroma) MakeCode() string {
 		return s
 	}
 
-	s := fmt.Sprintf("{%d,{%s},%d}",
-		chroma.Length, colors(), chroma.HueShift)
-	return s
+	if len(chroma.Positions) == 0 {
+		return fmt.Sprintf("{%d,{%s},%d,%d}",
+			chroma.Length, colors(), chroma.HueShift, chroma.Interpolation)
+	}
+
+	var positions string
+	for _, position := range chroma.StopPositions() {
+		positions += fmt.Sprintf("%d,", position)
+	}
+	return fmt.Sprintf("{%d,{%s},%d,%d,{%s}}",
+		chroma.Length, colors(), chroma.HueShift, chroma.Interpolation, positions)
 }
@@ -1,6 +1,9 @@
 package glow
 
-import "testing"
+import (
+	"image/color"
+	"testing"
+)
 
 func testChromaBase(t *testing.T, chroma *Chroma,
 	length uint16, source HSV, target HSV, hueShift int16) {
@@ -65,3 +68,56 @@ func TestChroma(t *testing.T) {
 	testChromaBase(t, &chroma, 10, HSV{0, 1, 1}, HSV{180, 1, 1}, 1)
 	testChromaColors(t)
 }
+
+func TestChromaStopPositions(t *testing.T) {
+	chroma := Chroma{Colors: []HSV{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 0, 1}}}
+	tests := []struct {
+		positions []uint16
+		want      []uint16
+	}{
+		{nil, []uint16{0, 25, 50, 75, 100}},
+		{[]uint16{10, PositionAuto, PositionAuto, 70}, []uint16{10, 30, 50, 70, 100}},
+		{[]uint16{PositionAuto, 60, 40}, []uint16{0, 60, 60, 80, 100}},
+	}
+	for _, tt := range tests {
+		chroma.Positions = tt.positions
+		got := chroma.StopPositions()
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Fatalf("positions %v got %v want %v", tt.positions, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestChromaPositions(t *testing.T) {
+	blue := HSV{240, 1, 1}
+	white := HSV{0, 0, 1}
+	chroma := Chroma{
+		Colors:    []HSV{blue, blue, white, blue, blue},
+		Positions: []uint16{0, 48, 50, 52, 100},
+	}
+	if err := chroma.SetupLength(100, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := chroma.Map(50); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("white band got %v", got)
+	}
+	for _, index := range []uint16{0, 10, 47, 53, 99} {
+		if got := chroma.Map(index); got != (color.NRGBA{0, 0, 255, 255}) {
+			t.Fatalf("blue at %d got %v", index, got)
+		}
+	}
+	if got := chroma.Map(49); got.G == 0 || got.G == 255 {
+		t.Fatalf("blend into band got %v", got)
+	}
+
+	chroma = Chroma{Colors: []HSV{blue, white, blue}, Positions: []uint16{0, 50, 50}}
+	chroma.SetupLength(10, 0)
+	if got := chroma.Map(5); got != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("hard stop got %v", got)
+	}
+	if got := chroma.MakeCode(); got != "{10,{{1020,255,255},{0,0,255},{1020,255,255},},0,0,{0,50,50,}}" {
+		t.Fatalf("make code got %s", got)
+	}
+}
@@ -5,18 +5,19 @@ import (
 )
 
 type DeltaSegment struct {
-	C          color.NRGBA
+	C, To      color.NRGBA
 	Begin, End int
 	R, G, B, A int
 }
 
 func NewDeltaSegment(from, to color.NRGBA) *DeltaSegment {
 	return &DeltaSegment{
-		C: from,
-		R: int(to.R) - int(from.R),
-		G: int(to.G) - int(from.G),
-		B: int(to.B) - int(from.B),
-		A: int(to.A) - int(from.A),
+		C:  from,
+		To: to,
+		R:  int(to.R) - int(from.R),
+		G:  int(to.G) - int(from.G),
+		B:  int(to.B) - int(from.B),
+		A:  int(to.A) - int(from.A),
 	}
 }
 
@@ -34,9 +35,20 @@ func (dlt *DeltaSegment) Point(absolute int) color.NRGBA {
 }
 
 type Delta struct {
-	segments []*DeltaSegment
-	length   int
-	count    int
+	segments      []*DeltaSegment
+	length        int
+	count         int
+	interpolation Interpolation
+	interpolate   bool
+}
+
+// NewDeltaIn blends the stops through the interpolation space
+// rather than directly between their red, green and blue.
+func NewDeltaIn(stops []color.NRGBA, length int, interpolation Interpolation) *Delta {
+	delta := NewDelta(stops, length)
+	delta.interpolation = interpolation
+	delta.interpolate = true
+	return delta
 }
 
 func NewDelta(stops []color.NRGBA, length int) *Delta {
@@ -50,7 +62,7 @@ func NewDelta(stops []color.NRGBA, length int) *Delta {
 
 	if count < 2 {
 		delta.segments = make([]*DeltaSegment, 1)
-		delta.segments[0] = &DeltaSegment{C: stops[0]}
+		delta.segments[0] = &DeltaSegment{C: stops[0], To: stops[0]}
 		delta.count = len(delta.segments)
 		return delta
 	}
@@ -79,5 +91,10 @@ func (dlt *Delta) Point(i int) color.NRGBA {
 	index := i * dlt.count / dlt.length
 	// compensate for rare integer division
 	index -= B2I(dlt.segments[index].Begin > i)
-	return dlt.segments[index].Point(i)
+	segment := dlt.segments[index]
+	if dlt.interpolate {
+		return dlt.interpolation.Mix(segment.C, segment.To,
+			i-segment.Begin, segment.End-segment.Begin)
+	}
+	return segment.Point(i)
 }
@@ -0,0 +1,68 @@
+package glow
+
+import "fmt"
+
+const (
+	MaximumLevel   = 255
+	MaximumPercent = 100
+)
+
+// Envelope ramps brightness up over Attack spins, holds it for Sustain
+// spins and ramps it down over Decay spins before repeating.
+// An envelope with no spins leaves brightness at full.
+type Envelope struct {
+	Attack  uint16 `yaml:"attack" json:"attack"`
+	Sustain uint16 `yaml:"sustain" json:"sustain"`
+	Decay   uint16 `yaml:"decay" json:"decay"`
+}
+
+func (env *Envelope) Length() uint32 {
+	return uint32(env.Attack) + uint32(env.Sustain) + uint32(env.Decay)
+}
+
+// Level returns the envelope level from 0 to MaximumLevel at spin.
+func (env *Envelope) Level(spin uint32) uint8 {
+	length := env.Length()
+	if length == 0 {
+		return MaximumLevel
+	}
+
+	at := spin % length
+	if at < uint32(env.Attack) {
+		return uint8(MaximumLevel * at / uint32(env.Attack))
+	}
+
+	at -= uint32(env.Attack)
+	if at < uint32(env.Sustain) {
+		return MaximumLevel
+	}
+
+	at -= uint32(env.Sustain)
+	return uint8(MaximumLevel * (uint32(env.Decay) - at) / uint32(env.Decay))
+}
+
+func (env *Envelope) MakeCode() string {
+	return fmt.Sprintf("{%d,%d,%d}", env.Attack, env.Sustain, env.Decay)
+}
+
+// percentLevel scales a percentage to a level, where zero is off.
+func percentLevel(percent uint16) uint32 {
+	return uint32(min(percent, MaximumPercent)) * MaximumLevel / MaximumPercent
+}
+
+// Percent returns a set value for the optional percent fields.
+func Percent(percent uint16) *uint16 {
+	return &percent
+}
+
+// percentOrFull returns the set percent or MaximumPercent when unset.
+func percentOrFull(percent *uint16) uint16 {
+	if percent == nil {
+		return MaximumPercent
+	}
+	return min(*percent, MaximumPercent)
+}
+
+func scaleColor(c uint8, level uint8) uint8 {
+	return uint8(uint16(c) * uint16(level) / MaximumLevel)
+}
@@ -0,0 +1,127 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnvelope(t *testing.T) {
+	var env Envelope
+	if env.Level(7) != MaximumLevel {
+		t.Fatalf("empty envelope want %d got %d", MaximumLevel, env.Level(7))
+	}
+
+	env = Envelope{Attack: 5, Sustain: 2, Decay: 5}
+	want := []uint8{0, 51, 102, 153, 204, 255, 255, 255, 204, 153, 102, 51, 0}
+	for spin, level := range want {
+		if got := env.Level(uint32(spin)); got != level {
+			t.Fatalf("spin %d want %d got %d", spin, level, got)
+		}
+	}
+}
+
+func TestLayerLevels(t *testing.T) {
+	red := &Layer{}
+	red.Chroma.AddColors(HSV{HueRed, 1, 1})
+	blue := &Layer{Opacity: Percent(50)}
+	blue.Chroma.AddColors(HSV{HueBlue, 1, 1})
+
+	frame := &Frame{Brightness: Percent(50), Interval: DefaultInterval}
+	frame.AddLayers(red, blue)
+	if err := frame.Setup(4, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	light := newTestLight(4)
+	frame.Spin(light)
+	want := color.NRGBA{63, 0, 63, 255}
+	if light.lights[0] != want {
+		t.Fatalf("want %v got %v", want, light.lights[0])
+	}
+
+	red.Envelope = Envelope{Attack: 2}
+	red.Spin(light)
+	want = color.NRGBA{127, 0, 0, 255}
+	if light.lights[0] != want {
+		t.Fatalf("attack want %v got %v", want, light.lights[0])
+	}
+}
+
+func TestLayerOpacityZero(t *testing.T) {
+	red := &Layer{}
+	red.Chroma.AddColors(HSV{HueRed, 1, 1})
+	blue := &Layer{Opacity: Percent(0)}
+	blue.Chroma.AddColors(HSV{HueBlue, 1, 1})
+
+	frame := &Frame{}
+	frame.AddLayers(red, blue)
+	if err := frame.Setup(4, 1); err != nil {
+		t.Fatal(err)
+	}
+	if red.OpacityPercent() != MaximumPercent || blue.OpacityPercent() != 0 {
+		t.Fatalf("opacity want %d and 0 got %d and %d",
+			MaximumPercent, red.OpacityPercent(), blue.OpacityPercent())
+	}
+
+	light := newTestLight(4)
+	frame.Spin(light)
+	want := color.NRGBA{255, 0, 0, 255}
+	if light.lights[0] != want {
+		t.Fatalf("want %v got %v", want, light.lights[0])
+	}
+
+	buffer, err := yaml.Marshal(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame2 Frame
+	if err = yaml.Unmarshal(buffer, &frame2); err != nil {
+		t.Fatal(err)
+	}
+	if frame2.Layers[0].Opacity != nil || frame2.Layers[1].OpacityPercent() != 0 {
+		t.Fatalf("yaml opacity want unset and 0 got %v and %d",
+			frame2.Layers[0].Opacity, frame2.Layers[1].OpacityPercent())
+	}
+}
+
+func TestLayerBrightnessZero(t *testing.T) {
+	red := &Layer{}
+	red.Chroma.AddColors(HSV{HueRed, 1, 1})
+	dark := &Layer{Brightness: Percent(0), Scan: 2}
+	dark.Chroma.AddColors(HSV{HueBlue, 1, 1})
+
+	frame := &Frame{}
+	frame.AddLayers(red, dark)
+	if err := frame.Setup(4, 1); err != nil {
+		t.Fatal(err)
+	}
+	if red.BrightnessPercent() != MaximumPercent || dark.BrightnessPercent() != 0 {
+		t.Fatalf("brightness want %d and 0 got %d and %d",
+			MaximumPercent, red.BrightnessPercent(), dark.BrightnessPercent())
+	}
+
+	light := newTestLight(4)
+	frame.Spin(light)
+	want := []color.NRGBA{{0, 0, 0, 255}, {0, 0, 0, 255}, {255, 0, 0, 255}, {255, 0, 0, 255}}
+	for i := range want {
+		if light.lights[i] != want[i] {
+			t.Fatalf("light %d want %v got %v", i, want[i], light.lights[i])
+		}
+	}
+
+	buffer, err := yaml.Marshal(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame2 Frame
+	if err = yaml.Unmarshal(buffer, &frame2); err != nil {
+		t.Fatal(err)
+	}
+	if frame2.Brightness != nil || frame2.Layers[0].Brightness != nil ||
+		frame2.Layers[1].BrightnessPercent() != 0 {
+		t.Fatalf("yaml brightness want unset and 0 got %v and %d",
+			frame2.Layers[0].Brightness, frame2.Layers[1].BrightnessPercent())
+	}
+}
@@ -12,21 +12,29 @@ import (
 
 var fontData = goregular.TTF
 
-func DrawText(title string, rect image.Rectangle, chroma *Chroma) (*image.NRGBA, error) {
+func parseFont() *opentype.Font {
 	fnt, err := opentype.Parse(fontData)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return fnt
+}
 
-	fontsize, height := rect.Max.Y, rect.Max.Y
+func newFace(fnt *opentype.Font, size float64, hinting font.Hinting) font.Face {
 	face, err := opentype.NewFace(fnt, &opentype.FaceOptions{
-		Size:    float64(fontsize),
+		Size:    size,
 		DPI:     72,
-		Hinting: font.HintingNone,
+		Hinting: hinting,
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
+	return face
+}
+
+func DrawText(title string, rect image.Rectangle, chroma *Chroma) (*image.NRGBA, error) {
+	fontsize, height := rect.Max.Y, rect.Max.Y
+	face := newFace(parseFont(), float64(fontsize), font.HintingNone)
 
 	drawer := font.Drawer{Face: face}
 	width := drawer.MeasureString(title).Ceil()
@@ -54,3 +62,29 @@ func DrawText(title string, rect image.Rectangle, chroma *Chroma) (*image.NRGBA,
 
 	return dst, nil
 }
+
+// DrawTextMask rasterizes title as coverage in the largest font
+// whose glyphs fit within rows, centred vertically.
+func DrawTextMask(title string, rows int) *image.Alpha {
+	rows = max(rows, 1)
+	size := float64(rows) * 2
+	fnt := parseFont()
+	face := newFace(fnt, size, font.HintingFull)
+	bounds, _ := font.BoundString(face, title)
+	for size > 1 && (bounds.Max.Y-bounds.Min.Y).Ceil() > rows {
+		size -= 0.5
+		face = newFace(fnt, size, font.HintingFull)
+		bounds, _ = font.BoundString(face, title)
+	}
+
+	width := max((bounds.Max.X - bounds.Min.X).Ceil(), 1)
+	height := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	dst := image.NewAlpha(image.Rect(0, 0, width, rows))
+	drawer := font.Drawer{Face: face, Dst: dst, Src: image.Opaque}
+	drawer.Dot = fixed.Point26_6{
+		X: -bounds.Min.X,
+		Y: -bounds.Min.Y + fixed.I((rows-height)/2),
+	}
+	drawer.DrawString(title)
+	return dst
+}
@@ -1,6 +1,7 @@
 package glow
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/barkimedes/go-deepcopy"
@@ -13,10 +14,14 @@ const (
 )
 
 type Frame struct {
-	Length   uint16   `yaml:"length" json:"length"`
-	Rows     uint16   `yaml:"rows" json:"rows"`
-	Interval uint32   `yaml:"interval" json:"interval"`
-	Layers   []*Layer `yaml:"layers" json:"layers"`
+	Length     uint16   `yaml:"length" json:"length"`
+	Rows       uint16   `yaml:"rows" json:"rows"`
+	Interval   uint32   `yaml:"interval" json:"interval"`
+	Layers     []*Layer `yaml:"layers" json:"layers"`
+	Brightness *uint16  `yaml:"brightness,omitempty" json:"brightness,omitempty"`
+	Opacity    *uint16  `yaml:"opacity,omitempty" json:"opacity,omitempty"`
+	Wiring     Wiring   `yaml:"-" json:"-"`
+	buffer     bufferLight
 }
 
 func NewFrame() (frame *Frame) {
@@ -61,10 +66,21 @@ func (frame *Frame) InsertLayer(position int, layer *Layer) {
 
 func (frame *Frame) updateLayers() {
 	for i := range frame.Layers {
+		frame.Layers[i].Grid.Wiring = frame.Wiring
 		frame.Layers[i].SetupLength(frame.Length, frame.Rows)
 	}
 }
 
+// BrightnessPercent returns the brightness the frame draws its layers with.
+func (frame *Frame) BrightnessPercent() uint16 {
+	return percentOrFull(frame.Brightness)
+}
+
+// OpacityPercent returns the opacity the frame draws its layers with.
+func (frame *Frame) OpacityPercent() uint16 {
+	return percentOrFull(frame.Opacity)
+}
+
 func (frame *Frame) Validate() (err error) {
 	if frame.Length == 0 {
 		return fmt.Errorf("Frame.Setup zero length")
@@ -72,6 +88,15 @@ func (frame *Frame) Validate() (err error) {
 	if frame.Rows == 0 {
 		return fmt.Errorf("Frame.Setup zero rows")
 	}
+	if frame.Brightness != nil && *frame.Brightness > MaximumPercent {
+		frame.Brightness = Percent(MaximumPercent)
+	}
+	if frame.Opacity != nil && *frame.Opacity > MaximumPercent {
+		frame.Opacity = Percent(MaximumPercent)
+	}
+	if frame.Wiring >= WIRING_COUNT {
+		frame.Wiring = Progressive
+	}
 	frame.updateLayers()
 	return err
 }
@@ -82,29 +107,104 @@ func (frame *Frame) Setup(length, rows uint16) error {
 	return frame.Validate()
 }
 
+// SetParity renders every layer with the firmware's integer math,
+// matching the device bit for bit. It is not saved with the frame.
+func (frame *Frame) SetParity(parity bool) {
+	for _, layer := range frame.Layers {
+		layer.Chroma.SetParity(parity)
+	}
+}
+
 func (frame *Frame) SetInterval(interval uint32) {
 	frame.Interval = interval
 }
 
+// Spin advances the frame by one interval.
 func (frame *Frame) Spin(light Light) {
-	for i := range frame.Layers {
-		frame.Layers[i].Spin(light)
-	}
+	frame.SpinFor(light, frame.Interval)
+}
+
+// SpinFor draws every layer and advances each on its own clock
+// by elapsed milliseconds.
+func (frame *Frame) SpinFor(light Light, elapsed uint32) {
+	frame.spinLayers(light, elapsed)
 	light.Refresh()
 }
 
+// spinLayers draws the layers in order. A mask layer draws off the
+// strip, and the layers above it, like a layer clipped to the one
+// below, are drawn on a copy of the lights and mixed back by how much
+// they are covered.
+func (frame *Frame) spinLayers(light Light, elapsed uint32) {
+	var mask []uint8
+	for i, layer := range frame.Layers {
+		clip := layer.Clip && i > 0 && layer.Mask == MaskNone
+		layer.cover(layer.Mask != MaskNone ||
+			(i+1 < len(frame.Layers) && frame.Layers[i+1].Clip))
+		if layer.Mask == MaskNone && mask == nil && !clip {
+			layer.spin(light, elapsed, frame.Interval, frame.BrightnessPercent(), frame.OpacityPercent())
+			continue
+		}
+
+		if len(frame.buffer) != int(frame.Length) {
+			frame.buffer = make(bufferLight, frame.Length)
+		}
+		for j := range frame.buffer {
+			frame.buffer[j] = light.Get(uint16(j))
+		}
+		layer.spin(frame.buffer, elapsed, frame.Interval, frame.BrightnessPercent(), frame.OpacityPercent())
+		if layer.Mask != MaskNone {
+			mask = layer.coverage
+			continue
+		}
+
+		for j := range frame.buffer {
+			level := uint8(MaximumLevel)
+			if mask != nil {
+				level = mask[j]
+			}
+			if clip {
+				level = uint8(uint32(level) * uint32(frame.Layers[i-1].coverage[j]) / MaximumLevel)
+			}
+			light.Set(uint16(j), mixLevel(light.Get(uint16(j)), frame.buffer[j], level))
+		}
+	}
+}
+
+// Tick returns the shortest interval in milliseconds at which
+// the frame or any of its layers needs to advance.
+func (frame *Frame) Tick() uint32 {
+	tick := frame.Interval
+	for _, layer := range frame.Layers {
+		if layer.Rate > 0 && layer.Rate < tick {
+			tick = layer.Rate
+		}
+	}
+	if tick < MinimumInterval {
+		tick = MinimumInterval
+	}
+	return tick
+}
+
 func (frame *Frame) AddLayers(layers ...*Layer) {
 	frame.Layers = append(frame.Layers, layers...)
 	frame.updateLayers()
 }
 
-func (frame *Frame) LoadImages() {
+// LoadImages loads the image of every layer that names one. Layers
+// whose image fails to load draw their chroma and the errors are
+// returned together.
+func (frame *Frame) LoadImages() error {
+	var errs []error
 	for _, layer := range frame.Layers {
 		if len(layer.ImageName) > 0 {
-			fmt.Println("load image", layer.ImageName, int(layer.Grid.Rows), int(layer.Grid.columns))
-			layer.LoadImage(int(layer.Grid.Rows), int(layer.Grid.columns))
+			err := layer.LoadImage(int(layer.Grid.Rows), int(layer.Grid.columns))
+			if err != nil {
+				errs = append(errs, err)
+			}
 		}
 	}
+	return errors.Join(errs...)
 }
 
 func FrameDeepCopy(source *Frame) (frame *Frame, err error) {
@@ -135,7 +235,8 @@ func (frame *Frame) MakeCode() string {
 		return s
 	}
 
-	s := fmt.Sprintf("{%d,%d,%d,{%s}},\n",
-		frame.Length, frame.Rows, frame.Interval, layers())
+	s := fmt.Sprintf("{%d,%d,%d,{%s},%d,%d},\n",
+		frame.Length, frame.Rows, frame.Interval, layers(),
+		frame.BrightnessPercent(), frame.OpacityPercent())
 	return s
 }
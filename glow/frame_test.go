@@ -2,6 +2,7 @@ package glow
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -20,6 +21,14 @@ func compareFrames(t *testing.T, frame, frame2 *Frame) {
 		t.Fatalf("Frame.Interval want: %d got %d",
 			frame.Interval, frame2.Interval)
 	}
+	if frame2.BrightnessPercent() != frame.BrightnessPercent() {
+		t.Fatalf("Frame.Brightness want: %d got %d",
+			frame.BrightnessPercent(), frame2.BrightnessPercent())
+	}
+	if frame2.OpacityPercent() != frame.OpacityPercent() {
+		t.Fatalf("Frame.Opacity want: %d got %d",
+			frame.OpacityPercent(), frame2.OpacityPercent())
+	}
 	if len(frame2.Layers) != len(frame.Layers) {
 		t.Fatalf("Frame.Interval want: %d got %d",
 			frame.Interval, frame2.Interval)
@@ -88,3 +97,86 @@ func TestFrameBasic(t *testing.T) {
 	code := frame.MakeCode()
 	t.Log(code)
 }
+
+func TestFrameSettings(t *testing.T) {
+	var chroma Chroma
+	chroma.AddColors(HSV{HueRed, 1, 1}, HSV{HueBlue, 1, 1})
+	grid := Grid{Orientation: Diagonal, Origin: TopLeft}
+
+	layer1 := &Layer{Grid: grid, Chroma: chroma,
+		Noise: Noise{Kind: NoisePlasma, Seed: 42, Scale: 32}}
+	layer2 := &Layer{Grid: grid, Chroma: chroma, Scan: 4, HueShift: -1,
+		Blend: BlendScreen, Opacity: Percent(50),
+		Envelope: Envelope{Attack: 4, Sustain: 8, Decay: 4},
+		ScanMode: ScanPingPong, Speed: 2,
+		Particles: Particles{Kind: ParticleMeteor, Spawn: 20, Tail: 6, Seed: 5}}
+
+	frame := Frame{Brightness: Percent(80), Wiring: Serpentine}
+	frame.AddLayers(layer1, layer2)
+	if err := frame.Setup(36, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer, err := yaml.Marshal(&frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame2 Frame
+	if err = yaml.Unmarshal(buffer, &frame2); err != nil {
+		t.Fatal(err)
+	}
+	compareFrames(t, &frame, &frame2)
+	// wiring belongs to the lights' profile, not the effect
+	if strings.Contains(string(buffer), "wiring") || frame2.Wiring != Progressive {
+		t.Fatalf("Frame.Wiring saved %s", buffer)
+	}
+
+	buffer, err = json.Marshal(&frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame3 Frame
+	if err = json.Unmarshal(buffer, &frame3); err != nil {
+		t.Fatal(err)
+	}
+	compareFrames(t, &frame, &frame3)
+}
+
+func TestFrameRate(t *testing.T) {
+	slow := &Layer{Scan: 1, Rate: 100}
+	slow.Chroma.AddColors(HSV{HueRed, 1, 1})
+	fast := &Layer{Scan: 1, Rate: 25, Blend: BlendMax}
+	fast.Chroma.AddColors(HSV{HueBlue, 1, 1})
+	follow := &Layer{Scan: 1, Blend: BlendMax}
+	follow.Chroma.AddColors(HSV{HueGreen, 1, 1})
+
+	frame := &Frame{Interval: 50}
+	frame.AddLayers(slow, fast, follow)
+	if err := frame.Setup(16, 1); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Tick() != 25 {
+		t.Fatalf("Tick want 25 got %d", frame.Tick())
+	}
+
+	light := newTestLight(16)
+	for i := 0; i < 4; i++ {
+		frame.Spin(light)
+	}
+	if slow.position != 2 {
+		t.Fatalf("slow position want 2 got %d", slow.position)
+	}
+	if fast.position != 8 {
+		t.Fatalf("fast position want 8 got %d", fast.position)
+	}
+	if follow.position != 4 {
+		t.Fatalf("follow position want 4 got %d", follow.position)
+	}
+
+	frame.SpinFor(light, 30)
+	frame.SpinFor(light, 30)
+	if slow.position != 2 || fast.position != 10 || follow.position != 5 {
+		t.Fatalf("elapsed positions got %d %d %d",
+			slow.position, fast.position, follow.position)
+	}
+}
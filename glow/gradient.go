@@ -8,9 +8,11 @@ import (
 )
 
 type LinearGradient struct {
-	Origin      Origin
-	Orientation Orientation
-	Stops       []color.NRGBA
+	Origin        Origin
+	Orientation   Orientation
+	Stops         []color.NRGBA
+	interpolation Interpolation
+	interpolate   bool
 }
 
 func NewLinearGradient(origin Origin, orientation Orientation, stops []color.NRGBA) *LinearGradient {
@@ -22,6 +24,20 @@ func NewLinearGradient(origin Origin, orientation Orientation, stops []color.NRG
 	return lg
 }
 
+// SetInterpolation blends the stops through the interpolation
+// space rather than directly between their red, green and blue.
+func (lg *LinearGradient) SetInterpolation(interpolation Interpolation) {
+	lg.interpolation = interpolation
+	lg.interpolate = true
+}
+
+func (lg *LinearGradient) newDelta(length int) *Delta {
+	if lg.interpolate {
+		return NewDeltaIn(lg.Stops, length, lg.interpolation)
+	}
+	return NewDelta(lg.Stops, length)
+}
+
 type Extent struct {
 	Begin, End, Inc int
 }
@@ -53,6 +69,10 @@ func (lg *LinearGradient) Draw(dst *image.NRGBA) {
 		lg.DrawVertical(dst, xext, yext)
 	case Diagonal:
 		lg.DrawDiagonal(dst, xext, yext)
+	case Centred:
+		lg.DrawRadial(dst)
+	case Concentric:
+		lg.DrawConcentric(dst)
 	}
 	fmt.Println(lg.Stops)
 }
@@ -60,7 +80,7 @@ func (lg *LinearGradient) Draw(dst *image.NRGBA) {
 func (lg *LinearGradient) DrawHorizontal(dst *image.NRGBA, xext, yext Extent) {
 	var (
 		length        = dst.Bounds().Dy()
-		delta  *Delta = NewDelta(lg.Stops, length)
+		delta  *Delta = lg.newDelta(length)
 	)
 
 	i := 0
@@ -76,7 +96,7 @@ func (lg *LinearGradient) DrawHorizontal(dst *image.NRGBA, xext, yext Extent) {
 func (lg *LinearGradient) DrawVertical(dst *image.NRGBA, xext, yext Extent) {
 	var (
 		length        = dst.Bounds().Dx()
-		delta  *Delta = NewDelta(lg.Stops, length)
+		delta  *Delta = lg.newDelta(length)
 	)
 
 	i := 0
@@ -93,7 +113,7 @@ func (lg *LinearGradient) DrawAngle(dst *image.NRGBA, xext, yext Extent) {
 	var (
 		height, width        = dst.Bounds().Dy(), dst.Bounds().Dx()
 		length               = height * width
-		delta         *Delta = NewDelta(lg.Stops, length)
+		delta         *Delta = lg.newDelta(length)
 	)
 	i := 0
 	for y := yext.Begin; y != yext.End; y += yext.Inc {
@@ -115,7 +135,7 @@ func (lg *LinearGradient) DrawDiagonal(dst *image.NRGBA, xext, yext Extent) {
 	var (
 		height, width        = dst.Bounds().Dy(), dst.Bounds().Dx()
 		length               = height * width
-		delta         *Delta = NewDelta(lg.Stops, length)
+		delta         *Delta = lg.newDelta(length)
 		colour        color.NRGBA
 		point         int
 	)
@@ -144,3 +164,38 @@ func (lg *LinearGradient) DrawDiagonal(dst *image.NRGBA, xext, yext Extent) {
 	}
 
 }
+
+// DrawRadial shades by distance from the centre outward.
+func (lg *LinearGradient) DrawRadial(dst *image.NRGBA) {
+	var (
+		b        = dst.Bounds()
+		cx, cy   = float64(b.Min.X+b.Max.X-1) / 2, float64(b.Min.Y+b.Max.Y-1) / 2
+		distance = func(x, y int) int {
+			return int(math.Hypot(float64(x)-cx, float64(y)-cy))
+		}
+		delta = lg.newDelta(distance(b.Min.X, b.Min.Y) + 1)
+	)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.SetNRGBA(x, y, delta.Point(distance(x, y)))
+		}
+	}
+}
+
+// DrawConcentric shades by ring from the outer edge inward.
+func (lg *LinearGradient) DrawConcentric(dst *image.NRGBA) {
+	var (
+		b    = dst.Bounds()
+		ring = func(x, y int) int {
+			return min(x-b.Min.X, y-b.Min.Y, b.Max.X-1-x, b.Max.Y-1-y)
+		}
+		delta = lg.newDelta((min(b.Dx(), b.Dy()) + 1) / 2)
+	)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.SetNRGBA(x, y, delta.Point(ring(x, y)))
+		}
+	}
+}
@@ -149,3 +149,24 @@ func TestAngle2Divisors(t *testing.T) {
 	}
 
 }
+
+func TestRingGradient(t *testing.T) {
+	stops := []color.NRGBA{{255, 0, 0, 255}, {0, 0, 255, 255}}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	NewLinearGradient(TopLeft, Centred, stops).Draw(dst)
+	if dst.NRGBAAt(3, 3) != stops[0] {
+		t.Fatalf("radial centre want %v got %v", stops[0], dst.NRGBAAt(3, 3))
+	}
+	if dst.NRGBAAt(0, 0) != dst.NRGBAAt(7, 7) {
+		t.Fatalf("radial corners differ %v %v", dst.NRGBAAt(0, 0), dst.NRGBAAt(7, 7))
+	}
+
+	NewLinearGradient(TopLeft, Concentric, stops).Draw(dst)
+	if dst.NRGBAAt(0, 5) != stops[0] {
+		t.Fatalf("concentric edge want %v got %v", stops[0], dst.NRGBAAt(0, 5))
+	}
+	if dst.NRGBAAt(3, 4) != dst.NRGBAAt(4, 3) {
+		t.Fatalf("concentric inner ring differs %v %v", dst.NRGBAAt(3, 4), dst.NRGBAAt(4, 3))
+	}
+}
@@ -3,6 +3,7 @@ package glow
 import (
 	"fmt"
 	"math"
+	"sort"
 )
 
 type Orientation uint16
@@ -11,7 +12,8 @@ const (
 	Horizontal Orientation = iota
 	Vertical
 	Diagonal
-	// Centred
+	Centred
+	Concentric
 	ORIENTATION_COUNT
 )
 
@@ -30,11 +32,13 @@ type Grid struct {
 	Rows        uint16      `yaml:"rows" json:"rows"`
 	Origin      Origin      `yaml:"origin" json:"origin"`
 	Orientation Orientation `yaml:"orientation" json:"orientation"`
+	Wiring      Wiring      `yaml:"-" json:"-"`
 
 	columns   uint16
 	firstEdge uint16
 	lastEdge  uint16
 	centre    uint16
+	table     []uint16
 }
 
 func (grid *Grid) GetFirst() uint16 {
@@ -70,12 +74,66 @@ func (grid *Grid) Validate() error {
 	if grid.Rows == 0 {
 		grid.Rows = 1
 	}
+	if grid.Wiring >= WIRING_COUNT {
+		grid.Wiring = Progressive
+	}
 
 	grid.columns = grid.Length / grid.Rows
 	grid.setupDiagonal()
+
+	grid.table = nil
+	switch grid.Orientation {
+	case Centred:
+		grid.setupTable(grid.radialKey)
+	case Concentric:
+		grid.setupTable(grid.concentricKey)
+	}
 	return nil
 }
 
+// setupTable orders offsets by key, breaking ties by offset.
+func (grid *Grid) setupTable(key func(x, y int) uint64) {
+	count := int(grid.columns) * int(grid.Rows)
+	keys := make([]uint64, count)
+	grid.table = make([]uint16, count)
+	for i := range grid.table {
+		grid.table[i] = uint16(i)
+		keys[i] = key(i%int(grid.columns), i/int(grid.columns))*uint64(count) + uint64(i)
+	}
+	sort.Slice(grid.table, func(a, b int) bool {
+		return keys[grid.table[a]] < keys[grid.table[b]]
+	})
+}
+
+// radialKey is the squared distance from the centre in half steps.
+func (grid *Grid) radialKey(x, y int) uint64 {
+	dx := 2*x - int(grid.columns-1)
+	dy := 2*y - int(grid.Rows-1)
+	return uint64(dx*dx + dy*dy)
+}
+
+// concentricKey orders by ring from the outer edge inward,
+// then clockwise around the ring from its top left corner.
+func (grid *Grid) concentricKey(x, y int) uint64 {
+	columns, rows := int(grid.columns), int(grid.Rows)
+	ring := min(x, y, columns-1-x, rows-1-y)
+	width := columns - 2*ring
+	height := rows - 2*ring
+
+	var along int
+	switch {
+	case y == ring:
+		along = x - ring
+	case x == columns-1-ring:
+		along = width - 1 + y - ring
+	case y == rows-1-ring:
+		along = width - 1 + height - 1 + columns - 1 - ring - x
+	default:
+		along = 2*(width-1) + height - 1 + rows - 1 - ring - y
+	}
+	return uint64(ring)*uint64(4*columns*rows) + uint64(along)
+}
+
 func (grid *Grid) setupDiagonal() {
 	grid.firstEdge = 0
 	lesser := min(grid.Rows, grid.columns)
@@ -94,8 +152,13 @@ func (grid *Grid) Map(index uint16) uint16 {
 		offset = grid.mapDiagonal(index)
 	case Vertical:
 		offset = grid.mapColumns(index)
+	case Centred, Concentric:
+		// indexes past the table map as themselves
+		if int(index) < len(grid.table) {
+			offset = grid.table[index]
+		}
 	}
-	return grid.mapToOrigin(offset)
+	return grid.Wiring.Wire(grid.mapToOrigin(offset), grid.columns, grid.Rows)
 }
 
 func (grid *Grid) mapColumns(index uint16) uint16 {
@@ -188,8 +251,11 @@ func (grid *Grid) mapDiagonalBottom(index uint16) uint16 {
 
 func (grid *Grid) AdjustBounds(bound float32) uint16 {
 	scaled := uint16(math.Round(float64(bound)))
-	if grid.Orientation == Horizontal {
+	switch grid.Orientation {
+	case Horizontal:
 		return scaled / grid.columns * grid.columns
+	case Centred, Concentric:
+		return scaled
 	}
 	return scaled / grid.Rows * grid.Rows
 }
@@ -183,3 +183,44 @@ func TestGridFourByNineVertical(t *testing.T) {
 	testGridBase(t, &grid, length, rows, BottomRight, Vertical)
 	testTable(t, &grid, fourByNineVerticalBottomRight)
 }
+
+var fourByFourCentred = []uint16{
+	5, 6, 9, 10, 1, 2, 4, 7,
+	8, 11, 13, 14, 0, 3, 12, 15,
+}
+
+var fourBySixConcentric = []uint16{
+	0, 1, 2, 3, 4, 5, 11, 17, 23, 22, 21, 20,
+	19, 18, 12, 6, 7, 8, 9, 10, 16, 15, 14, 13,
+}
+
+func TestGridRings(t *testing.T) {
+	var grid Grid
+	testGridBase(t, &grid, 16, 4, TopLeft, Centred)
+	testTable(t, &grid, fourByFourCentred)
+
+	testGridBase(t, &grid, 24, 4, TopLeft, Concentric)
+	testTable(t, &grid, fourBySixConcentric)
+
+	if grid.AdjustBounds(7) != 7 {
+		t.Fatalf("AdjustBounds want 7 got %d", grid.AdjustBounds(7))
+	}
+
+	seen := make(map[uint16]bool)
+	testGridBase(t, &grid, 256, 16, BottomRight, Centred)
+	for i := uint16(0); i < grid.Length; i++ {
+		seen[grid.Map(i)] = true
+	}
+	if len(seen) != int(grid.Length) {
+		t.Fatalf("Map covered %d of %d", len(seen), grid.Length)
+	}
+
+	// lights past the rows by columns table map as themselves,
+	// as map_table does in the generated grid
+	testGridBase(t, &grid, 10, 3, TopLeft, Concentric)
+	for _, index := range []uint16{9, 20} {
+		if got := grid.Map(index); got != index {
+			t.Fatalf("Map out of range %d got %d", index, got)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package glow
 
 import (
-	"fmt"
 	"image/color"
 	"math"
 
@@ -108,13 +107,33 @@ func (hsv *HSV) ToRGB() color.NRGBA {
 }
 
 func (hsv *HSV) ToGradient(target HSV, index uint16, length uint16) HSV {
-	if hsv.Hue > target.Hue {
-		target.Hue += 360.0
+	return hsv.ToGradientIn(target, index, length, InterpolateHSV)
+}
+
+// ToGradientIn turns hue forward, the shorter way or in
+// reverse following the interpolation.
+func (hsv *HSV) ToGradientIn(target HSV, index uint16, length uint16,
+	interpolation Interpolation) HSV {
+	switch interpolation {
+	case InterpolateHSVShortest:
+		if target.Hue-hsv.Hue > HueMax/2 {
+			target.Hue -= HueMax
+		} else if hsv.Hue-target.Hue > HueMax/2 {
+			target.Hue += HueMax
+		}
+	case InterpolateHSVReverse:
+		if hsv.Hue < target.Hue {
+			target.Hue -= HueMax
+		}
+	default:
+		if hsv.Hue > target.Hue {
+			target.Hue += HueMax
+		}
 	}
 	ratio := float32(index) / float32(length)
 	hue := hsv.Hue + (target.Hue-hsv.Hue)*ratio
 
-	hue = float32(math.Mod(float64(hue), float64(HueMax)))
+	hue = float32(math.Mod(float64(hue)+float64(HueMax), float64(HueMax)))
 
 	saturation := hsv.Saturation + (target.Saturation-hsv.Saturation)*ratio
 	value := hsv.Value + (target.Value-hsv.Value)*ratio
@@ -148,8 +167,16 @@ func (hsv *HSV) UnmarshalYAML(node *yaml.Node) (err error) {
 	return nil
 }
 
+// ToHSVColor returns the integer color the firmware is given.
+func (hsv *HSV) ToHSVColor() HSVColor {
+	return HSVColor{
+		Hue:        uint16(int(hsv.Hue * 1530 / 360)),
+		Saturation: uint8(int(hsv.Saturation * 255)),
+		Value:      uint8(int(hsv.Value * 255)),
+	}
+}
+
 func (hsv *HSV) MakeCode() string {
-	s := fmt.Sprintf("{%d,%d,%d}",
-		int(hsv.Hue*1530/360), int(hsv.Saturation*255), int(hsv.Value*255))
-	return s
+	hsvColor := hsv.ToHSVColor()
+	return hsvColor.MakeCode()
 }
@@ -1,6 +1,9 @@
 package glow
 
-import "image/color"
+import (
+	"fmt"
+	"image/color"
+)
 
 const (
 	byte_limit        uint16 = 0xff
@@ -29,16 +32,18 @@ func (hsv *HSVColor) FromRGB(c color.NRGBA) {
 		diff        int = 0
 	)
 
+	// the segment start is added before dividing so that hues
+	// round down as they do from the color wheel
 	if color_range != 0 {
 		if c.R == uint8(primary) {
 			diff = int(c.G) - int(c.B)
-			hue = 255*diff/color_range + B2I(diff < 0)*int(hue_limit)
+			hue = (255*diff + B2I(diff < 0)*int(hue_limit)*color_range) / color_range
 		} else if c.G == uint8(primary) {
 			diff = int(c.B) - int(c.R)
-			hue = 255*diff/color_range + int(hue_green)
+			hue = (255*diff + int(hue_green)*color_range) / color_range
 		} else {
 			diff = int(c.R) - int(c.G)
-			hue = 255*diff/color_range + int(hue_blue)
+			hue = (255*diff + int(hue_blue)*color_range) / color_range
 		}
 	}
 
@@ -99,8 +104,11 @@ var hueFuncs = [6]func(hsv *HSVColor) color.NRGBA{
 }
 
 func (hsv *HSVColor) ToRGB() (c color.NRGBA) {
-	index := hsv.Hue / hue_segment // 0 - 5
-	c = hueFuncs[index](hsv)
+	if hsv.Hue < hue_limit {
+		c = hueFuncs[hsv.Hue/hue_segment](hsv)
+	} else {
+		c = color.NRGBA{A: 255, R: uint8(byte_limit)}
+	}
 
 	var saturation_multiplier uint16 = 1 + uint16(hsv.Saturation)
 	var saturation_added uint16 = hue_segment - uint16(hsv.Saturation)
@@ -121,17 +129,44 @@ func (hsv *HSVColor) ToRGB() (c color.NRGBA) {
 	return c
 }
 
-// func (hsv *HSVColor) ToGradient(target HSVColor, index, length uint16, reverse bool) HSVColor {
-// 	var dh int = (int(hsv.Hue)-int(target.Hue))*B2I(reverse) +
-// 		(int(target.Hue)-int(hsv.Hue))*B2I(!reverse)
-// 	hue *= index
-// 	hue /= int(length)
-
-// 	var ds int = int(hsv.Saturation) - int(target.Saturation)*dir
-// 	var dv int = int(hsv.Value) - int(target.Value)*dir
+// ToGradient returns the color index steps of length along the way
+// to target, turning hue forward, the shorter way or in reverse.
+// A zero length gradient stays at the color.
+func (hsv *HSVColor) ToGradient(target HSVColor, index, length uint16,
+	interpolation Interpolation) HSVColor {
+	if length == 0 {
+		return *hsv
+	}
+	hue, targetHue := int32(hsv.Hue), int32(target.Hue)
+	switch interpolation {
+	case InterpolateHSVShortest:
+		if targetHue-hue > int32(hue_limit/2) {
+			targetHue -= int32(hue_limit)
+		} else if hue-targetHue > int32(hue_limit/2) {
+			targetHue += int32(hue_limit)
+		}
+	case InterpolateHSVReverse:
+		if hue < targetHue {
+			targetHue -= int32(hue_limit)
+		}
+	default:
+		if hue > targetHue {
+			targetHue += int32(hue_limit)
+		}
+	}
+	gradientHue := hue + (targetHue-hue)*int32(index)/int32(length)
+	gradientHue = (gradientHue + int32(hue_limit)) % int32(hue_limit)
+	saturation := int32(hsv.Saturation) +
+		(int32(target.Saturation)-int32(hsv.Saturation))*int32(index)/int32(length)
+	value := int32(hsv.Value) +
+		(int32(target.Value)-int32(hsv.Value))*int32(index)/int32(length)
+	return HSVColor{
+		Hue:        uint16(gradientHue),
+		Saturation: uint8(saturation),
+		Value:      uint8(value),
+	}
+}
 
-// 	target.Hue += uint16(B2I(hsv.Hue > target.Hue)) * hue_limit
-// 	var gradient_hue uint16 = hsv.Hue + ((target.Hue-hsv.Hue)*index)/length
-// 	var gradient_saturation int16 = uint16(hsv.Saturation) +
-// 		(uint16(target.Saturation)-uint16(hsv.Saturation))*index/length
-// }
+func (hsv *HSVColor) MakeCode() string {
+	return fmt.Sprintf("{%d,%d,%d}", hsv.Hue, hsv.Saturation, hsv.Value)
+}
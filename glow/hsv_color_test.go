@@ -51,6 +51,14 @@ func TestToConversions(t *testing.T) {
 	}
 }
 
+func TestHSVColorToGradientZero(t *testing.T) {
+	hsv := HSVColor{Hue: 100, Saturation: 200, Value: 50}
+	target := HSVColor{Hue: 900, Saturation: 10, Value: 255}
+	if got := hsv.ToGradient(target, 3, 0, InterpolateHSV); got != hsv {
+		t.Fatalf("zero length want %v got %v", hsv, got)
+	}
+}
+
 // func TestHSVToGradient(t *testing.T) {
 // 	hsv := HSV{0, 1, 1}
 // 	expected := HSV{180, .5, .5}
@@ -1,36 +1,206 @@
 package glow
 
+import "image/color"
+
 type HSVMask struct {
 	H, S, V int
 }
 
 func (hm *HSVMask) None() bool { return hm.H|hm.S|hm.V == 0 }
 
-// func (hm *HSVMask) Apply(hsv HSVColor, index, length uint16) HSVColor {
-// 	var hue int = int(hsv.Hue) + hm.Hue*int(index)/int(length)
-// 	hue += B2I(hue < 0) * int(hue_limit)
-// 	var sat int = int(hsv.Saturation) + hm.Saturation*int(index)/int(length)
-// 	return HSVColor{
-// 		Hue:        uint16(hue),
-// 		Saturation: 0,
-// 		Value:      0,
-// 	}
-// }
+// Apply shifts hsv by index steps of length toward the full mask.
+// Hue wraps around the wheel while saturation and value clamp.
+func (hm *HSVMask) Apply(hsv HSVColor, index, length uint16) HSVColor {
+	return HSVColor{
+		Hue:        hm.NextHue(hsv.Hue, index, length),
+		Saturation: hm.NextSaturation(hsv.Saturation, index, length),
+		Value:      hm.NextValue(hsv.Value, index, length),
+	}
+}
 
 func (hm *HSVMask) NextHue(hue, index, length uint16) uint16 {
 	var next int = int(hue) + hm.H*int(index)/int(length)
-	next += B2I(next < 0) * int(hue_limit)
 	next %= int(hue_limit)
+	next += B2I(next < 0) * int(hue_limit)
 	return uint16(next)
 }
 
 func (hm *HSVMask) NextSaturation(saturation uint8, index, length uint16) uint8 {
-	return uint8(int(saturation) + hm.S*int(index)/int(length))
+	return uint8(min(max(int(saturation)+hm.S*int(index)/int(length), 0), 255))
 }
 func (hm *HSVMask) NextValue(value uint8, index, length uint16) uint8 {
-	return uint8(int(value) + hm.V*int(index)/int(length))
+	return uint8(min(max(int(value)+hm.V*int(index)/int(length), 0), 255))
 }
 
+// HSVGradient maps colors along a length with the integer math of
+// the firmware's Chroma, so its colors match the device exactly.
+// Mask.H is the hue shift of each update.
 type HSVGradient struct {
-	Mask HSVMask
+	Length        uint16
+	Colors        []HSVColor
+	Positions     []uint16
+	Interpolation Interpolation
+	Mask          HSVMask
+
+	segmentSize uint16
+	stops       []uint16
+	quickColor  color.NRGBA
+	rgbs        []color.NRGBA
+	labs        []fixedLab
+	modulated   [][3]int32
+}
+
+// NewHSVGradient converts chroma to the colors the firmware is given.
+func NewHSVGradient(chroma *Chroma) *HSVGradient {
+	gradient := &HSVGradient{
+		Length:        chroma.Length,
+		Colors:        make([]HSVColor, len(chroma.Colors)),
+		Positions:     append([]uint16{}, chroma.Positions...),
+		Interpolation: chroma.Interpolation,
+		Mask:          HSVMask{H: int(chroma.HueShift)},
+	}
+	for i := range chroma.Colors {
+		gradient.Colors[i] = chroma.Colors[i].ToHSVColor()
+	}
+	gradient.Setup()
+	return gradient
+}
+
+func (gradient *HSVGradient) Setup() {
+	if len(gradient.Colors) == 0 {
+		gradient.Colors = append(gradient.Colors, HSVColor{Value: 255})
+	}
+	if gradient.Interpolation >= INTERPOLATION_COUNT {
+		gradient.Interpolation = InterpolateHSV
+	}
+	gradient.quickColor = gradient.Colors[0].ToRGB()
+
+	size := uint16(len(gradient.Colors) - 1)
+	gradient.segmentSize = gradient.Length
+	if size >= 2 {
+		gradient.segmentSize = gradient.Length / size
+	}
+	gradient.segmentSize += uint16(B2I(gradient.segmentSize == 0))
+
+	gradient.stops = nil
+	if len(gradient.Positions) > 0 && len(gradient.Colors) > 1 {
+		chroma := Chroma{Colors: make([]HSV, len(gradient.Colors)),
+			Positions: gradient.Positions}
+		gradient.stops = chroma.StopPositions()
+		for i, position := range gradient.stops {
+			gradient.stops[i] = uint16(uint32(position) * uint32(gradient.Length) / 100)
+		}
+	}
+	gradient.prepare()
+}
+
+// prepare keeps the colors ready to mix outside of HSV.
+func (gradient *HSVGradient) prepare() {
+	gradient.rgbs, gradient.labs = nil, nil
+	switch gradient.Interpolation {
+	case InterpolateRGB:
+		for i := range gradient.Colors {
+			gradient.rgbs = append(gradient.rgbs, gradient.Colors[i].ToRGB())
+		}
+	case InterpolateOKLab, InterpolateOKLCh:
+		for i := range gradient.Colors {
+			gradient.labs = append(gradient.labs, fixedToOKLab(gradient.Colors[i].ToRGB()))
+		}
+	}
+}
+
+func (gradient *HSVGradient) Map(index uint16) color.NRGBA {
+	if gradient.stops != nil {
+		return gradient.mapStops(index)
+	}
+	size := uint16(len(gradient.Colors))
+	if size < 2 || index == 0 {
+		return gradient.quickColor
+	}
+	segment, offset := index/gradient.segmentSize, index%gradient.segmentSize
+	if segment >= size-1 {
+		return gradient.Colors[size-1].ToRGB()
+	}
+	return gradient.mix(segment, offset, gradient.segmentSize)
+}
+
+// mapStops finds the segment holding index between positioned stops.
+func (gradient *HSVGradient) mapStops(index uint16) color.NRGBA {
+	stops := gradient.stops
+	last := uint16(len(stops) - 1)
+	if index <= stops[0] {
+		return gradient.quickColor
+	}
+	if index >= stops[last] {
+		return gradient.Colors[last].ToRGB()
+	}
+	var segment uint16
+	for segment+1 < last && stops[segment+1] <= index {
+		segment++
+	}
+	return gradient.mix(segment, index-stops[segment], stops[segment+1]-stops[segment])
+}
+
+func (gradient *HSVGradient) mix(first, offset, size uint16) color.NRGBA {
+	switch gradient.Interpolation {
+	case InterpolateRGB:
+		return fixedMixLinear(gradient.rgbs[first], gradient.rgbs[first+1],
+			int32(offset), int32(size))
+	case InterpolateOKLab:
+		return fixedMixOKLab(gradient.labs[first], gradient.labs[first+1],
+			int32(offset), int32(size))
+	case InterpolateOKLCh:
+		return fixedMixOKLCh(gradient.labs[first], gradient.labs[first+1],
+			int32(offset), int32(size))
+	}
+	hsv := gradient.Colors[first].ToGradient(gradient.Colors[first+1],
+		offset, size, gradient.Interpolation)
+	return hsv.ToRGB()
+}
+
+// Update shifts the colors by the mask. Like the firmware, a hue
+// passing either end of the wheel starts again from the other end.
+func (gradient *HSVGradient) Update() {
+	if gradient.Mask.None() {
+		return
+	}
+	for i := range gradient.Colors {
+		hsv := &gradient.Colors[i]
+		hsv.Hue += uint16(int16(gradient.Mask.H))
+		if hsv.Hue > hue_limit {
+			hsv.Hue = hue_limit * uint16(B2I(gradient.Mask.H < 0))
+		}
+		hsv.Saturation = gradient.Mask.NextSaturation(hsv.Saturation, 1, 1)
+		hsv.Value = gradient.Mask.NextValue(hsv.Value, 1, 1)
+	}
+	gradient.quickColor = gradient.Colors[0].ToRGB()
+	gradient.prepare()
+}
+
+// Modulate moves the color at stop from its own by hue, saturation
+// and value steps, replacing the offsets of the last call.
+func (gradient *HSVGradient) Modulate(stop uint16, hue, saturation, value int32) {
+	if int(stop) >= len(gradient.Colors) {
+		return
+	}
+	if len(gradient.modulated) != len(gradient.Colors) {
+		gradient.modulated = make([][3]int32, len(gradient.Colors))
+	}
+	hsv, applied := &gradient.Colors[stop], &gradient.modulated[stop]
+	limit := int32(hue_limit)
+	baseHue := ((int32(hsv.Hue)-applied[0])%limit + limit) % limit
+	baseSaturation := int32(hsv.Saturation) - applied[1]
+	baseValue := int32(hsv.Value) - applied[2]
+
+	nextSaturation := min(max(baseSaturation+saturation, 0), 255)
+	nextValue := min(max(baseValue+value, 0), 255)
+	*applied = [3]int32{hue, nextSaturation - baseSaturation, nextValue - baseValue}
+	hsv.Hue = uint16(((baseHue+hue)%limit + limit) % limit)
+	hsv.Saturation = uint8(nextSaturation)
+	hsv.Value = uint8(nextValue)
+
+	if stop == 0 {
+		gradient.quickColor = gradient.Colors[0].ToRGB()
+	}
+	gradient.prepare()
 }
@@ -0,0 +1,72 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+)
+
+// expected colors are the output of the generated C++ Chroma
+func TestHSVGradientFirmware(t *testing.T) {
+	rgb := func(r, g, b uint8) color.NRGBA { return color.NRGBA{r, g, b, 255} }
+	tests := []struct {
+		interpolation Interpolation
+		colors        []HSV
+		length        uint16
+		want          []color.NRGBA
+	}{
+		{InterpolateHSV, []HSV{{0, 1, 1}, {240, 1, 1}}, 8, []color.NRGBA{
+			rgb(255, 0, 0), rgb(255, 127, 0), rgb(255, 255, 0), rgb(128, 255, 0),
+			rgb(0, 255, 0), rgb(0, 255, 127), rgb(0, 255, 255), rgb(0, 128, 255)}},
+		{InterpolateOKLCh, []HSV{{0, 1, 1}, {240, 1, 1}}, 8, []color.NRGBA{
+			rgb(255, 0, 0), rgb(247, 0, 81), rgb(232, 0, 123), rgb(212, 0, 160),
+			rgb(186, 0, 194), rgb(156, 0, 223), rgb(122, 0, 244), rgb(82, 0, 255)}},
+		{InterpolateRGB, []HSV{{0, 1, 1}, {240, 1, 1}}, 8, []color.NRGBA{
+			rgb(255, 0, 0), rgb(240, 0, 99), rgb(225, 0, 137), rgb(207, 0, 165),
+			rgb(188, 0, 188), rgb(165, 0, 207), rgb(137, 0, 225), rgb(99, 0, 240)}},
+		{InterpolateHSV, []HSV{{0, 1, 1}, {120, 1, 1}, {240, 1, 1}}, 7, []color.NRGBA{
+			rgb(255, 0, 0), rgb(255, 170, 0), rgb(170, 255, 0), rgb(0, 255, 0),
+			rgb(0, 255, 170), rgb(0, 170, 255), rgb(0, 0, 255)}},
+	}
+
+	for n, test := range tests {
+		chroma := Chroma{Colors: test.colors, Interpolation: test.interpolation}
+		chroma.SetParity(true)
+		err := chroma.SetupLength(test.length, 0)
+		if err != nil {
+			t.Fatalf("%d %v", n, err)
+		}
+		for i, want := range test.want {
+			got := chroma.Map(uint16(i))
+			if got != want {
+				t.Fatalf("%d index %d want %v got %v", n, i, want, got)
+			}
+		}
+	}
+}
+
+func TestHSVGradientUpdate(t *testing.T) {
+	gradient := HSVGradient{
+		Length: 4,
+		Colors: []HSVColor{{1520, 255, 255}, {10, 255, 255}},
+		Mask:   HSVMask{H: 20},
+	}
+	gradient.Setup()
+	gradient.Update()
+	if gradient.Colors[0].Hue != 0 || gradient.Colors[1].Hue != 30 {
+		t.Fatalf("hues %d %d", gradient.Colors[0].Hue, gradient.Colors[1].Hue)
+	}
+
+	limit := HSVColor{hue_limit, 255, 255}
+	if c := limit.ToRGB(); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("hue limit %v", c)
+	}
+}
+
+func TestHSVMaskApply(t *testing.T) {
+	mask := HSVMask{H: -100, S: 100, V: -300}
+	got := mask.Apply(HSVColor{50, 200, 100}, 1, 1)
+	want := HSVColor{1480, 255, 0}
+	if got != want {
+		t.Fatalf("want %v got %v", want, got)
+	}
+}
@@ -0,0 +1,62 @@
+package glow
+
+import (
+	"image"
+	"image/color"
+)
+
+var _ Light = (*ImageLight)(nil)
+
+// ImageLight is a headless Light with one pixel per light.
+type ImageLight struct {
+	Lights  *image.NRGBA
+	Wiring  Wiring
+	length  int
+	columns int
+	spins   int
+}
+
+func NewImageLight(length, rows uint16) *ImageLight {
+	if rows == 0 {
+		rows = 1
+	}
+	columns := int(length / rows)
+	il := &ImageLight{
+		Lights:  image.NewNRGBA(image.Rect(0, 0, columns, int(rows))),
+		length:  columns * int(rows),
+		columns: columns,
+	}
+	return il
+}
+
+func (il *ImageLight) Length() uint16 {
+	return uint16(il.length)
+}
+
+func (il *ImageLight) Rows() uint16 {
+	return uint16(il.Lights.Rect.Dy())
+}
+
+func (il *ImageLight) Spins() int {
+	return il.spins
+}
+
+func (il *ImageLight) Get(i uint16) color.NRGBA {
+	x, y := il.Wiring.Locate(i, uint16(il.columns), il.Rows())
+	return il.Lights.NRGBAAt(x, y)
+}
+
+func (il *ImageLight) Set(i uint16, c color.NRGBA) {
+	x, y := il.Wiring.Locate(i, uint16(il.columns), il.Rows())
+	il.Lights.SetNRGBA(x, y, c)
+}
+
+func (il *ImageLight) Refresh() {
+	il.spins++
+}
+
+func (il *ImageLight) Fill(c color.NRGBA) {
+	for i := 0; i < il.length; i++ {
+		il.Set(uint16(i), c)
+	}
+}
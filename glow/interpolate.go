@@ -0,0 +1,147 @@
+package glow
+
+import (
+	"image/color"
+	"math"
+)
+
+// Interpolation is the color space gradients blend through.
+// The HSV spaces differ in the way hue turns between colors.
+type Interpolation uint16
+
+const (
+	InterpolateHSV Interpolation = iota
+	InterpolateHSVShortest
+	InterpolateHSVReverse
+	InterpolateRGB
+	InterpolateOKLab
+	InterpolateOKLCh
+	INTERPOLATION_COUNT
+)
+
+// IsHSV is true for the spaces that blend hue, saturation and value.
+func (interpolation Interpolation) IsHSV() bool {
+	return interpolation <= InterpolateHSVReverse
+}
+
+// Mix returns the color index steps of length along the way from
+// one color to the other. Alpha blends linearly.
+func (interpolation Interpolation) Mix(from, to color.NRGBA, index, length int) color.NRGBA {
+	length += B2I(length == 0)
+	ratio := float64(index) / float64(length)
+	var c color.NRGBA
+	switch interpolation {
+	case InterpolateRGB:
+		a, b := toLinear(from), toLinear(to)
+		c = fromLinear(lerp3(a, b, ratio))
+	case InterpolateOKLab:
+		a, b := toOKLab(from), toOKLab(to)
+		c = fromOKLab(lerp3(a, b, ratio))
+	case InterpolateOKLCh:
+		a, b := toOKLCh(from), toOKLCh(to)
+		c = fromOKLCh(mixOKLCh(a, b, ratio))
+	default:
+		var a, b HSV
+		a.FromRGB(from)
+		b.FromRGB(to)
+		hsv := a.ToGradientIn(b, uint16(index), uint16(length), interpolation)
+		c = hsv.ToRGB()
+	}
+	c.A = from.A + uint8((int(to.A)-int(from.A))*index/length)
+	return c
+}
+
+func lerp3(a, b [3]float64, ratio float64) (c [3]float64) {
+	for i := range c {
+		c[i] = a[i] + (b[i]-a[i])*ratio
+	}
+	return
+}
+
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / MaximumLevel
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(f float64) uint8 {
+	f = max(min(f, 1), 0)
+	if f <= 0.0031308 {
+		f *= 12.92
+	} else {
+		f = 1.055*math.Pow(f, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(f * MaximumLevel))
+}
+
+func toLinear(c color.NRGBA) [3]float64 {
+	return [3]float64{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)}
+}
+
+func fromLinear(rgb [3]float64) color.NRGBA {
+	return color.NRGBA{linearToSRGB(rgb[0]), linearToSRGB(rgb[1]), linearToSRGB(rgb[2]), 255}
+}
+
+// toOKLab converts to lightness and the a and b axes of
+// Björn Ottosson's OKLab.
+func toOKLab(c color.NRGBA) [3]float64 {
+	rgb := toLinear(c)
+	l := math.Cbrt(0.4122214708*rgb[0] + 0.5363325363*rgb[1] + 0.0514459929*rgb[2])
+	m := math.Cbrt(0.2119034982*rgb[0] + 0.6806995451*rgb[1] + 0.1073969566*rgb[2])
+	s := math.Cbrt(0.0883024619*rgb[0] + 0.2817188376*rgb[1] + 0.6299787005*rgb[2])
+	return [3]float64{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func fromOKLab(lab [3]float64) color.NRGBA {
+	l := lab[0] + 0.3963377774*lab[1] + 0.2158037573*lab[2]
+	m := lab[0] - 0.1055613458*lab[1] - 0.0638541728*lab[2]
+	s := lab[0] - 0.0894841775*lab[1] - 1.2914855480*lab[2]
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return fromLinear([3]float64{
+		4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s,
+	})
+}
+
+// toOKLCh converts to OKLab lightness, chroma and hue in degrees.
+func toOKLCh(c color.NRGBA) [3]float64 {
+	lab := toOKLab(c)
+	hue := math.Atan2(lab[2], lab[1]) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return [3]float64{lab[0], math.Hypot(lab[1], lab[2]), hue}
+}
+
+func fromOKLCh(lch [3]float64) color.NRGBA {
+	radians := lch[2] * math.Pi / 180
+	return fromOKLab([3]float64{lch[0], lch[1] * math.Cos(radians), lch[1] * math.Sin(radians)})
+}
+
+// achromatic is the chroma below which OKLCh hue is meaningless.
+const achromatic = 0.002
+
+// mixOKLCh turns hue the shorter way, keeping the hue of
+// the other color when one of them is grey.
+func mixOKLCh(a, b [3]float64, ratio float64) [3]float64 {
+	if a[1] < achromatic {
+		a[2] = b[2]
+	} else if b[1] < achromatic {
+		b[2] = a[2]
+	}
+	turn := b[2] - a[2]
+	if turn > 180 {
+		turn -= 360
+	} else if turn < -180 {
+		turn += 360
+	}
+	hue := math.Mod(a[2]+turn*ratio+360, 360)
+	return [3]float64{a[0] + (b[0]-a[0])*ratio, a[1] + (b[1]-a[1])*ratio, hue}
+}
@@ -0,0 +1,215 @@
+package glow
+
+import (
+	"image/color"
+	"math"
+)
+
+// The fixed point twins of the float blends in interpolate.go match
+// generated/Interpolate.cpp step for step, so that the firmware's
+// colors can be reproduced exactly.
+
+// full linear light
+const linearOne int32 = 0xffff
+
+// fixedLab holds OKLab lightness and axes in 14 bit fixed point.
+type fixedLab struct {
+	l, a, b int16
+}
+
+var (
+	// linear light of each sRGB level
+	srgbLinear [256]uint16
+	// a quarter turn of sine in 14 bit fixed point
+	quarterSine [257]int16
+)
+
+func init() {
+	for i := range srgbLinear {
+		srgbLinear[i] = uint16(math.Round(srgbToLinear(uint8(i)) * float64(linearOne)))
+	}
+	for i := range quarterSine {
+		quarterSine[i] = int16(math.Round(math.Sin(float64(i)/256*math.Pi/2) * 16384))
+	}
+}
+
+func fixedToLinear(level uint8) int32 {
+	return int32(srgbLinear[level])
+}
+
+func fixedFromLinear(linear int32) uint8 {
+	if linear <= 0 {
+		return 0
+	}
+	if linear >= linearOne {
+		return 255
+	}
+	low, high := 0, 255
+	for low < high {
+		middle := (low + high + 1) >> 1
+		if int32(srgbLinear[middle]) <= linear {
+			low = middle
+		} else {
+			high = middle - 1
+		}
+	}
+	if low < 255 && int32(srgbLinear[low+1])-linear < linear-int32(srgbLinear[low]) {
+		low++
+	}
+	return uint8(low)
+}
+
+// fixedCubeRoot takes 16 bit linear light to 14 bit fixed point.
+func fixedCubeRoot(linear int32) int32 {
+	if linear <= 0 {
+		return 0
+	}
+	scaled := uint64(linear) << 26
+	var root uint64
+	for bit := 15; bit >= 0; bit-- {
+		trial := root | 1<<bit
+		if trial*trial*trial <= scaled {
+			root = trial
+		}
+	}
+	return int32(root)
+}
+
+// fixedCube takes 14 bit fixed point to 16 bit linear light.
+func fixedCube(q14 int32) int64 {
+	value := int64(q14)
+	return (value * value * value) >> 26
+}
+
+func fixedSquareRoot(value uint32) uint32 {
+	var root uint32
+	for bit := 15; bit >= 0; bit-- {
+		trial := root | 1<<bit
+		if trial*trial <= value {
+			root = trial
+		}
+	}
+	return root
+}
+
+// fixedAngle returns the angle of y over x in 1/65536 turns.
+func fixedAngle(y, x int32) uint16 {
+	if x == 0 && y == 0 {
+		return 0
+	}
+	ax, ay := x, y
+	if ax < 0 {
+		ax = -ax
+	}
+	if ay < 0 {
+		ay = -ay
+	}
+	steep := ay > ax
+	var ratio int64
+	if steep {
+		ratio = (int64(ax) << 15) / int64(ay)
+	} else {
+		ratio = (int64(ay) << 15) / int64(ax)
+	}
+	// atan(r) ~ r/8 + r (1 - r) (0.03895 + 0.01055 r) turns
+	bend := ratio * (32768 - ratio) >> 15
+	turn := int32((8192*ratio + bend*(2552+(692*ratio>>15))) >> 15)
+	if steep {
+		turn = 16384 - turn
+	}
+	if x < 0 {
+		turn = 32768 - turn
+	}
+	if y < 0 {
+		turn = 65536 - turn
+	}
+	return uint16(turn)
+}
+
+// fixedSine returns the sine of 1/65536 turns in 14 bit fixed point.
+func fixedSine(turn uint16) int32 {
+	quarter := turn >> 14
+	within := turn & 0x3fff
+	if quarter&1 != 0 {
+		within = 16384 - within
+	}
+	index := within >> 6
+	level := int32(quarterSine[index])
+	if index < 256 {
+		level += ((int32(quarterSine[index+1]) - level) * int32(within&63)) >> 6
+	}
+	if quarter&2 != 0 {
+		return -level
+	}
+	return level
+}
+
+func fixedToOKLab(c color.NRGBA) (lab fixedLab) {
+	r, g, b := fixedToLinear(c.R), fixedToLinear(c.G), fixedToLinear(c.B)
+	l := fixedCubeRoot((6754*r + 8787*g + 843*b) >> 14)
+	m := fixedCubeRoot((3472*r + 11153*g + 1760*b) >> 14)
+	s := fixedCubeRoot((1447*r + 4616*g + 10322*b) >> 14)
+	lab.l = int16((3448*l + 13003*m - 67*s) >> 14)
+	lab.a = int16((32408*l - 39790*m + 7383*s) >> 14)
+	lab.b = int16((424*l + 12825*m - 13249*s) >> 14)
+	return
+}
+
+func fixedFromOKLab(lab fixedLab) color.NRGBA {
+	L, A, B := int32(lab.l), int32(lab.a), int32(lab.b)
+	l := fixedCube((16384*L + 6494*A + 3536*B) >> 14)
+	m := fixedCube((16384*L - 1730*A - 1046*B) >> 14)
+	s := fixedCube((16384*L - 1466*A - 21160*B) >> 14)
+	return color.NRGBA{
+		R: fixedFromLinear(int32((66793*l - 54194*m + 3784*s) >> 14)),
+		G: fixedFromLinear(int32((-20782*l + 42758*m - 5592*s) >> 14)),
+		B: fixedFromLinear(int32((-69*l - 11525*m + 27978*s) >> 14)),
+		A: 255,
+	}
+}
+
+func fixedLerp(from, to, index, length int32) int32 {
+	return int32(int64(from) + int64(to-from)*int64(index)/int64(length))
+}
+
+func fixedMixLinear(from, to color.NRGBA, index, length int32) color.NRGBA {
+	mix := func(a, b uint8) uint8 {
+		return fixedFromLinear(fixedLerp(fixedToLinear(a), fixedToLinear(b), index, length))
+	}
+	return color.NRGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+}
+
+func fixedMixOKLab(from, to fixedLab, index, length int32) color.NRGBA {
+	return fixedFromOKLab(fixedLab{
+		l: int16(fixedLerp(int32(from.l), int32(to.l), index, length)),
+		a: int16(fixedLerp(int32(from.a), int32(to.a), index, length)),
+		b: int16(fixedLerp(int32(from.b), int32(to.b), index, length)),
+	})
+}
+
+// fixedMixOKLCh turns hue the shorter way keeping the hue of a grey end.
+func fixedMixOKLCh(from, to fixedLab, index, length int32) color.NRGBA {
+	// chroma below which hue is meaningless
+	const achromatic = 33
+	chroma := func(lab fixedLab) int32 {
+		a, b := int32(lab.a), int32(lab.b)
+		return int32(fixedSquareRoot(uint32(a*a + b*b)))
+	}
+	fromChroma, toChroma := chroma(from), chroma(to)
+	fromHue := fixedAngle(int32(from.b), int32(from.a))
+	toHue := fixedAngle(int32(to.b), int32(to.a))
+	if fromChroma < achromatic {
+		fromHue = toHue
+	} else if toChroma < achromatic {
+		toHue = fromHue
+	}
+
+	turn := int16(toHue - fromHue)
+	hue := uint16(int64(fromHue) + int64(turn)*int64(index)/int64(length))
+	c := fixedLerp(fromChroma, toChroma, index, length)
+	return fixedFromOKLab(fixedLab{
+		l: int16(fixedLerp(int32(from.l), int32(to.l), index, length)),
+		a: int16((c * fixedSine(hue+16384)) >> 14),
+		b: int16((c * fixedSine(hue)) >> 14),
+	})
+}
@@ -0,0 +1,109 @@
+package glow
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func near(a, b color.NRGBA, tolerance int) bool {
+	diff := func(x, y uint8) bool {
+		d := int(x) - int(y)
+		return d <= tolerance && d >= -tolerance
+	}
+	return diff(a.R, b.R) && diff(a.G, b.G) && diff(a.B, b.B) && a.A == b.A
+}
+
+func TestInterpolateEnds(t *testing.T) {
+	from := color.NRGBA{200, 30, 90, 255}
+	to := color.NRGBA{10, 180, 240, 255}
+	for mode := InterpolateHSV; mode < INTERPOLATION_COUNT; mode++ {
+		if got := mode.Mix(from, to, 0, 10); !near(got, from, 1) {
+			t.Fatalf("mode %d start got %v", mode, got)
+		}
+		if got := mode.Mix(from, to, 10, 10); !near(got, to, 1) {
+			t.Fatalf("mode %d end got %v", mode, got)
+		}
+	}
+}
+
+func TestInterpolateHue(t *testing.T) {
+	source, target := HSV{350, 1, 1}, HSV{10, 1, 1}
+	tests := []struct {
+		mode Interpolation
+		hue  float32
+	}{
+		{InterpolateHSV, 0},
+		{InterpolateHSVShortest, 0},
+		{InterpolateHSVReverse, 180},
+	}
+	for _, tt := range tests {
+		got := source.ToGradientIn(target, 5, 10, tt.mode)
+		if math.Abs(float64(got.Hue-tt.hue)) > 0.01 {
+			t.Fatalf("mode %d hue got %v want %v", tt.mode, got.Hue, tt.hue)
+		}
+	}
+
+	source, target = HSV{10, 1, 1}, HSV{350, 1, 1}
+	if got := source.ToGradientIn(target, 5, 10, InterpolateHSVReverse); got.Hue != 0 {
+		t.Fatalf("reverse 10 to 350 hue got %v", got.Hue)
+	}
+	if got := source.ToGradient(target, 5, 10); got.Hue != 180 {
+		t.Fatalf("forward 10 to 350 hue got %v", got.Hue)
+	}
+}
+
+func TestInterpolateSpaces(t *testing.T) {
+	black := color.NRGBA{0, 0, 0, 255}
+	white := color.NRGBA{255, 255, 255, 255}
+	if got := InterpolateRGB.Mix(black, white, 1, 2); got.R != 188 || got.G != 188 || got.B != 188 {
+		t.Fatalf("linear rgb middle grey got %v", got)
+	}
+	if got := InterpolateOKLab.Mix(black, white, 1, 2); got.R != 99 || got.G != 99 || got.B != 99 {
+		t.Fatalf("oklab middle grey got %v", got)
+	}
+
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	lab := toOKLCh(InterpolateOKLab.Mix(red, blue, 1, 2))
+	lch := toOKLCh(InterpolateOKLCh.Mix(red, blue, 1, 2))
+	if lch[1] <= lab[1] {
+		t.Fatalf("oklch keeps chroma got %v oklab %v", lch[1], lab[1])
+	}
+
+	got := InterpolateOKLCh.Mix(white, red, 1, 2)
+	if hue := toOKLCh(got)[2]; math.Abs(hue-toOKLCh(red)[2]) > 2 {
+		t.Fatalf("oklch from grey should keep red hue got %v hue %v", got, hue)
+	}
+}
+
+func TestChromaInterpolation(t *testing.T) {
+	chroma := Chroma{Colors: []HSV{{10, 1, 1}, {350, 1, 1}}}
+	if err := chroma.SetupLength(10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := chroma.Map(5); got.G < 200 || got.R > 10 {
+		t.Fatalf("forward middle got %v", got)
+	}
+	chroma.Interpolation = InterpolateHSVShortest
+	if got := chroma.Map(5); got != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("shortest middle got %v", got)
+	}
+	chroma.Interpolation = InterpolateOKLCh
+	if got := chroma.Map(5); got.R != 255 || got.G > 40 || got.B > 40 {
+		t.Fatalf("oklch middle got %v", got)
+	}
+	if chroma.MakeCode() != "{10,{{42,255,255},{1487,255,255},},0,5}" {
+		t.Fatalf("make code got %s", chroma.MakeCode())
+	}
+}
+
+func TestDeltaInterpolation(t *testing.T) {
+	stops := []color.NRGBA{{0, 0, 0, 255}, {255, 255, 255, 255}}
+	if got := NewDelta(stops, 10).Point(5); got.R != 127 {
+		t.Fatalf("srgb delta got %v", got)
+	}
+	if got := NewDeltaIn(stops, 10, InterpolateRGB).Point(5); got.R != 188 {
+		t.Fatalf("linear rgb delta got %v", got)
+	}
+}
@@ -2,32 +2,62 @@ package glow
 
 import (
 	"fmt"
-	"image"
 	"image/color"
 )
 
 type Layer struct {
-	Length    uint16 `yaml:"length" json:"length"`
-	Rows      uint16 `yaml:"rows" json:"rows"`
-	Grid      Grid   `yaml:"grid" json:"grid"`
-	Chroma    Chroma `yaml:"chroma" json:"chroma"`
-	HueShift  int16  `yaml:"hue_shift" json:"hue_shift"`
-	Scan      uint16 `yaml:"scan" json:"scan"`
-	Begin     uint16 `yaml:"begin" json:"begin"`
-	End       uint16 `yaml:"end" json:"end"`
-	Rate      uint32 `yaml:"rate" json:"rate"`
-	ImageName string `yaml:"image_name" json:"image_name"`
-
-	position uint16
-	first    uint16
-	last     uint16
-	picture  image.Image
+	Length      uint16       `yaml:"length" json:"length"`
+	Rows        uint16       `yaml:"rows" json:"rows"`
+	Grid        Grid         `yaml:"grid" json:"grid"`
+	Chroma      Chroma       `yaml:"chroma" json:"chroma"`
+	HueShift    int16        `yaml:"hue_shift" json:"hue_shift"`
+	Scan        uint16       `yaml:"scan" json:"scan"`
+	Begin       uint16       `yaml:"begin" json:"begin"`
+	End         uint16       `yaml:"end" json:"end"`
+	Rate        uint32       `yaml:"rate" json:"rate"`
+	ImageName   string       `yaml:"image_name" json:"image_name"`
+	ImageFilter ResampleItem `yaml:"image_filter" json:"image_filter"`
+	Blend       BlendMode    `yaml:"blend" json:"blend"`
+	Opacity     *uint16      `yaml:"opacity,omitempty" json:"opacity,omitempty"`
+	Brightness  *uint16      `yaml:"brightness,omitempty" json:"brightness,omitempty"`
+	Envelope    Envelope     `yaml:"envelope" json:"envelope"`
+	ScanMode    ScanMode     `yaml:"scan_mode" json:"scan_mode"`
+	Speed       uint16       `yaml:"speed" json:"speed"`
+	Noise       Noise        `yaml:"noise" json:"noise"`
+	Particles   Particles    `yaml:"particles" json:"particles"`
+	Text        Text         `yaml:"text" json:"text"`
+	Modulators  []Modulator  `yaml:"modulators,omitempty" json:"modulators,omitempty"`
+	// Mask keeps the layer off the strip and lets the layers above it
+	// through where it draws. Clip shows the layer only where the
+	// layer below it draws.
+	Mask MaskMode `yaml:"mask,omitempty" json:"mask,omitempty"`
+	Clip bool     `yaml:"clip,omitempty" json:"clip,omitempty"`
+	// Expression is a program giving each light its color in place
+	// of the chroma. A program that does not compile is ignored.
+	Expression string `yaml:"expression,omitempty" json:"expression,omitempty"`
+
+	position  uint16
+	first     uint16
+	last      uint16
+	animation *Animation
+	picture   int
+	shown     uint32
+	spins     uint32
+	clock     uint32
+	phase     uint32
+	seed      uint32
+	level     uint8
+	alpha     uint8
+	time      uint32
+	offsets   modOffsets
+	coverage  []uint8
+	program   *Program
 }
 
 func NewLayer() *Layer {
 	var layer Layer
 	layer.Chroma.Colors = append(layer.Chroma.Colors,
-		HSV{Hue: 180, Saturation: 100, Value: 100})
+		HSV{Hue: 180, Saturation: 1, Value: 1})
 	return &layer
 }
 
@@ -81,86 +111,379 @@ func (layer *Layer) Validate() error {
 	if layer.Rate == 0 {
 		layer.Rate = 48
 	}
+	if layer.Blend >= BLEND_COUNT {
+		layer.Blend = BlendReplace
+	}
+	if layer.ScanMode >= SCAN_MODE_COUNT {
+		layer.ScanMode = ScanForward
+	}
+	if layer.Opacity != nil && *layer.Opacity > MaximumPercent {
+		layer.Opacity = Percent(MaximumPercent)
+	}
+	if layer.Brightness != nil && *layer.Brightness > MaximumPercent {
+		layer.Brightness = Percent(MaximumPercent)
+	}
+	layer.Noise.Validate()
+	layer.Particles.Validate()
+	layer.Text.Validate(layer.Rows)
+	for i := range layer.Modulators {
+		layer.Modulators[i].Validate()
+	}
+	if layer.Mask >= MASK_MODE_COUNT {
+		layer.Mask = MaskNone
+	}
+	// a layer whose expression does not compile draws its chroma,
+	// and Lint reports why
+	layer.program = nil
+	if layer.Expression != "" {
+		layer.program, _ = CompileProgram(layer.Expression)
+	}
+	layer.offsets = modOffsets{}
 	layer.setBounds()
 
 	return nil
 }
 
 func (layer *Layer) setBounds() {
-	ratio := func(offset, length uint16) float32 {
+	layer.first, layer.last = layer.bounds(0, 0)
+	layer.position = layer.first
+}
+
+// bounds returns the first and last light of the layer with begin
+// and end moved by percent offsets.
+func (layer *Layer) bounds(beginOffset, endOffset int32) (first, last uint16) {
+	ratio := func(offset uint16, change int32) float32 {
 		if offset > 100 {
 			offset %= 100
 		}
-		return float32(offset) / 100.0 * float32(length)
+		offset = uint16(clampOffset(uint32(offset), change, 0, 100))
+		return float32(offset) / 100.0 * float32(layer.Length)
+	}
+
+	first = layer.Grid.AdjustBounds(ratio(layer.Begin, beginOffset))
+	last = layer.Grid.AdjustBounds(ratio(layer.End, endOffset))
+
+	if last < first {
+		first, last = last, first
+	}
+	return
+}
+
+// modulate moves the modulated parameters to their values at the
+// layer's time.
+func (layer *Layer) modulate() {
+	if len(layer.Modulators) == 0 {
+		return
+	}
+	var offsets modOffsets
+	for i := range layer.Modulators {
+		mod := &layer.Modulators[i]
+		offset := mod.Offset(layer.time)
+		switch mod.Target {
+		case ModBegin:
+			offsets.begin += offset
+		case ModEnd:
+			offsets.end += offset
+		case ModScan:
+			offsets.scan += offset
+		case ModOpacity:
+			offsets.opacity += offset
+		case ModRate:
+			offsets.rate += offset
+		}
+	}
+	if offsets.begin != layer.offsets.begin || offsets.end != layer.offsets.end {
+		layer.first, layer.last = layer.bounds(offsets.begin, offsets.end)
+		if layer.position < layer.first || layer.position >= layer.last {
+			layer.position = layer.first
+		}
 	}
+	layer.offsets = offsets
 
-	layer.first = layer.Grid.AdjustBounds(ratio(layer.Begin, layer.Length))
-	layer.last = layer.Grid.AdjustBounds(ratio(layer.End, layer.Length))
+	// each stop takes the sum of its modulators once
+	for i := range layer.Modulators {
+		mod := &layer.Modulators[i]
+		if !mod.IsColor() || layer.stopModulated(i) {
+			continue
+		}
+		var hsv [3]int32
+		for j := i; j < len(layer.Modulators); j++ {
+			other := &layer.Modulators[j]
+			if other.IsColor() && other.Stop == mod.Stop {
+				hsv[other.Target-ModHue] += other.Offset(layer.time)
+			}
+		}
+		layer.Chroma.Modulate(mod.Stop, hsv[0], hsv[1], hsv[2])
+	}
+}
 
-	if layer.last < layer.first {
-		layer.first, layer.last = layer.last, layer.first
+// stopModulated reports whether a modulator before index changes the
+// same chroma stop.
+func (layer *Layer) stopModulated(index int) bool {
+	for i := 0; i < index; i++ {
+		if layer.Modulators[i].IsColor() && layer.Modulators[i].Stop == layer.Modulators[index].Stop {
+			return true
+		}
 	}
+	return false
 }
 
+// scanLength is the modulated scan, which keeps at least one light.
+func (layer *Layer) scanLength() uint16 {
+	if layer.Scan == 0 || layer.offsets.scan == 0 {
+		return layer.Scan
+	}
+	return uint16(clampOffset(uint32(layer.Scan), layer.offsets.scan, 1, int32(layer.Length)))
+}
+
+// Spin draws the layer and advances it by one step of its own clock.
 func (layer *Layer) Spin(light Light) {
-	if layer.picture != nil {
-		layer.spinImage(light)
+	layer.spin(light, layer.Rate, 0, MaximumPercent, MaximumPercent)
+}
+
+// spin draws the layer and advances it by the steps its rate allows
+// in elapsed milliseconds, applying the frame's brightness and
+// opacity percentages on top of the layer's own.
+func (layer *Layer) spin(light Light, elapsed, interval uint32, brightness, opacity uint16) {
+	layer.modulate()
+	layer.time += elapsed
+	steps := layer.tick(elapsed, interval)
+	layer.updateLevels(brightness, opacity)
+
+	if layer.animation != nil {
+		layer.spinImage(light, elapsed, steps)
+		layer.spins += steps
+		return
+	}
+
+	if layer.Text.mask != nil {
+		layer.spinText(light, steps)
+		layer.spins += steps
+		return
+	}
+
+	if layer.Particles.Kind != ParticleNone {
+		layer.spinParticles(light, steps)
+		layer.spins += steps
 		return
 	}
 
 	startAt := layer.first
 	endAt := layer.last
-	if layer.Scan > 0 {
-		startAt, endAt = layer.updateScanPosition()
+	scan := layer.scanLength()
+	if scan > 0 {
+		startAt, endAt = layer.position, layer.position+scan
+	}
+
+	// a scan passing the last light wraps to the first
+	span := layer.last - layer.first
+	for i := startAt; i < endAt && span > 0; i++ {
+		x := layer.first + (i-layer.first)%span
+		offset := layer.Grid.Map(x)
+		layer.put(light, offset, layer.color(x, offset))
+	}
+
+	for i := uint32(0); i < steps; i++ {
+		if scan > 0 {
+			layer.updateScanPosition()
+		}
+		layer.Chroma.UpdateColors()
+	}
+	layer.spins += steps
+}
+
+// tick adds elapsed milliseconds to the layer clock and returns the
+// number of steps due. Without a rate or interval it steps once.
+func (layer *Layer) tick(elapsed, interval uint32) (steps uint32) {
+	rate := layer.Rate
+	if rate == 0 {
+		rate = interval
+	}
+	if rate == 0 {
+		return 1
+	}
+	if layer.offsets.rate != 0 {
+		rate = clampOffset(rate, layer.offsets.rate, 1, MaximumInterval)
+	}
+	layer.clock += elapsed
+	steps = layer.clock / rate
+	layer.clock %= rate
+	return
+}
+
+// OpacityPercent returns the opacity the layer draws with. An unset
+// Opacity is full so that a set 0 can hide the layer.
+func (layer *Layer) OpacityPercent() uint16 {
+	return percentOrFull(layer.Opacity)
+}
+
+// BrightnessPercent returns the brightness the layer draws with. An
+// unset Brightness is full so that a set 0 can darken the layer.
+func (layer *Layer) BrightnessPercent() uint16 {
+	return percentOrFull(layer.Brightness)
+}
+
+func (layer *Layer) updateLevels(brightness, opacity uint16) {
+	level := percentLevel(layer.BrightnessPercent()) * percentLevel(brightness) / MaximumLevel
+	level = level * uint32(layer.Envelope.Level(layer.spins)) / MaximumLevel
+	layer.level = uint8(level)
+	layerOpacity := clampOffset(uint32(layer.OpacityPercent()), layer.offsets.opacity, 0, MaximumPercent)
+	layer.alpha = uint8(percentLevel(uint16(layerOpacity)) * percentLevel(opacity) / MaximumLevel)
+}
+
+// color returns the chroma color of index x, or with noise the
+// gradient color picked by the noise level at the light's position,
+// or with an expression the color its program gives the light.
+func (layer *Layer) color(x, offset uint16) color.NRGBA {
+	if layer.program != nil {
+		return layer.programColor(x, offset)
+	}
+	if layer.Noise.Kind == NoiseNone {
+		return layer.Chroma.Map(x)
+	}
+	column, row := layer.Grid.Wiring.Locate(offset, layer.Grid.columns, layer.Rows)
+	level := layer.Noise.Sample(uint16(column), uint16(row), layer.Rows, layer.spins)
+	return layer.Chroma.Map(uint16(uint32(level) * uint32(layer.Length-1) / MaximumLevel))
+}
+
+func (layer *Layer) programColor(x, offset uint16) color.NRGBA {
+	column, row := layer.Grid.Wiring.Locate(offset, layer.Grid.columns, layer.Rows)
+	hue, saturation, value := layer.program.Eval(ProgramInput{
+		X:     float64(column),
+		Y:     float64(row),
+		Index: float64(x),
+		T:     float64(layer.time) / 1000,
+		Spin:  float64(layer.spins),
+	})
+	if layer.Chroma.Parity() {
+		hsv := ProgramColor(hue, saturation, value)
+		return hsv.ToRGB()
+	}
+	hsv := HSV{Hue: float32(hue), Saturation: float32(saturation), Value: float32(value)}
+	return hsv.ToRGB()
+}
+
+func (layer *Layer) put(light Light, i uint16, c color.NRGBA) {
+	layer.putDrawn(light, i, c, MaximumLevel)
+}
+
+// putDrawn puts c on the light, recording drawn, how much of the
+// light the layer drew, at the layer's opacity as its coverage.
+func (layer *Layer) putDrawn(light Light, i uint16, c color.NRGBA, drawn uint8) {
+	if layer.level < MaximumLevel {
+		c.R = scaleColor(c.R, layer.level)
+		c.G = scaleColor(c.G, layer.level)
+		c.B = scaleColor(c.B, layer.level)
+	}
+	if int(i) < len(layer.coverage) {
+		mode := layer.Mask
+		if mode == MaskNone {
+			mode = MaskAlpha
+		}
+		layer.coverage[i] = mode.Cover(c, uint8(uint32(drawn)*uint32(layer.alpha)/MaximumLevel))
 	}
 
-	for i := startAt; i < endAt; i++ {
-		x := layer.first + (i % (layer.last - layer.first))
-		light.Set(layer.Grid.Map(x), layer.Chroma.Map(x))
+	if layer.Blend == BlendReplace && layer.alpha == MaximumLevel {
+		light.Set(i, c)
+		return
 	}
 
-	layer.Chroma.UpdateColors()
+	dst := light.Get(i)
+	c = layer.Blend.Blend(dst, c)
+	if layer.alpha < MaximumLevel {
+		c.A = layer.alpha
+		c = BlendAlphaOver.Blend(dst, c)
+	}
+	light.Set(i, c)
 }
 
-func (layer *Layer) updateScanPosition() (startAt, endAt uint16) {
-	startAt = layer.position
-	endAt = layer.position + layer.Scan
-	layer.position++
-	if layer.position >= layer.last {
-		layer.position = layer.first
+// cover starts recording how much the layer covers each light
+// this spin, or stops when on is false.
+func (layer *Layer) cover(on bool) {
+	if !on {
+		layer.coverage = nil
+		return
+	}
+	if len(layer.coverage) != int(layer.Length) {
+		layer.coverage = make([]uint8, layer.Length)
 	}
-	return startAt, endAt
+	clear(layer.coverage)
 }
 
 func (layer *Layer) MakeCode() string {
-	s := fmt.Sprintf("{%d,%d,%s,%s,%d,%d,%d,%d},",
+	modulators := "{"
+	for i := range layer.Modulators {
+		modulators += layer.Modulators[i].MakeCode() + ","
+	}
+	modulators += "}"
+	expression := "nullptr"
+	if layer.Expression != "" {
+		if program, err := CompileProgram(layer.Expression); err == nil {
+			expression = program.Name()
+		}
+	}
+	s := fmt.Sprintf("{%d,%d,%s,%s,%d,%d,%d,%d,%d,%d,%d,%s,%d,%d,%d,%s,%s,%s,%s,%d,%t,%s},",
 		layer.Length,
 		layer.Rows,
 		layer.Grid.MakeCode(),
 		layer.Chroma.MakeCode(),
-		layer.HueShift, layer.Scan, layer.Begin, layer.End)
+		layer.HueShift, layer.Scan, layer.Begin, layer.End,
+		layer.Blend, layer.OpacityPercent(), layer.BrightnessPercent(),
+		layer.Envelope.MakeCode(), layer.Rate,
+		layer.ScanMode, layer.Speed, layer.Noise.MakeCode(),
+		layer.Particles.MakeCode(),
+		layer.Text.MakeCode(), modulators,
+		layer.Mask, layer.Clip, expression)
 	return s
 }
 
+// LoadImage loads the picture, animation or image sequence
+// named by ImageName resampled to cols by rows.
 func (layer *Layer) LoadImage(rows, cols int) (err error) {
-	layer.picture, err = LoadPicPath(layer.ImageName, rows, cols)
+	if layer.ImageFilter >= RESAMPLE_ITEM_COUNT {
+		layer.ImageFilter = NearestNeighbor
+	}
+	layer.animation, err = LoadAnimation(layer.ImageName, rows, cols,
+		layer.ImageFilter.Filter())
+	layer.picture, layer.shown = 0, 0
 	return
 }
 
-func (layer *Layer) spinImage(light Light) {
-	if layer.picture == nil {
-		return
-	}
-	pic := layer.picture
+// spinImage draws the current picture then moves through the
+// animation. Timed animations follow their own delays while image
+// sequences show one picture per step. A replacing layer clears the
+// lights the picture leaves transparent.
+func (layer *Layer) spinImage(light Light, elapsed, steps uint32) {
+	anim := layer.animation
+	pic := anim.Frames[layer.picture]
+	columns, rows := layer.Grid.columns, layer.Rows
 	b := pic.Bounds()
-	for x := b.Min.X; x < b.Max.X && x < int(layer.Grid.columns); x++ {
-		for y := b.Min.Y; y < b.Max.Y && y < int(layer.Rows); y++ {
-			c := pic.At(x, y)
-			r, g, b, a := c.RGBA()
-			if a != 0 {
-				light.Set(uint16(y)*layer.Rows+uint16(x),
-					color.NRGBA{uint8(r), uint8(g), uint8(b), 255})
+	for y := 0; y < b.Dy() && y < int(rows); y++ {
+		for x := 0; x < b.Dx() && x < int(columns); x++ {
+			index := layer.Grid.Wiring.Wire(uint16(y)*columns+uint16(x), columns, rows)
+			c := pic.NRGBAAt(b.Min.X+x, b.Min.Y+y)
+			if c.A == 0 {
+				continue
+			}
+			drawn := c.A
+			if layer.Blend != BlendAlphaOver {
+				c.A = 255
 			}
+			layer.putDrawn(light, index, c, drawn)
 		}
 	}
+
+	if anim.Len() < 2 {
+		return
+	}
+	if !anim.Timed() {
+		layer.picture = (layer.picture + int(steps)) % anim.Len()
+		return
+	}
+	layer.shown += elapsed
+	for delay := max(anim.Delays[layer.picture], 1); layer.shown >= delay; {
+		layer.shown -= delay
+		layer.picture = (layer.picture + 1) % anim.Len()
+		delay = max(anim.Delays[layer.picture], 1)
+	}
 }
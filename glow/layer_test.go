@@ -52,6 +52,43 @@ func compareLayers(t *testing.T, want, got *Layer) {
 		t.Fatalf("Grid Origin got %d want %d",
 			got.Grid.Origin, want.Grid.Origin)
 	}
+	if got.Blend != want.Blend {
+		t.Fatalf("Blend got %d want %d",
+			got.Blend, want.Blend)
+	}
+	if got.OpacityPercent() != want.OpacityPercent() {
+		t.Fatalf("Opacity got %d want %d",
+			got.OpacityPercent(), want.OpacityPercent())
+	}
+	if got.BrightnessPercent() != want.BrightnessPercent() {
+		t.Fatalf("Brightness got %d want %d",
+			got.BrightnessPercent(), want.BrightnessPercent())
+	}
+	if got.Envelope != want.Envelope {
+		t.Fatalf("Envelope got %v want %v",
+			got.Envelope, want.Envelope)
+	}
+	if got.ScanMode != want.ScanMode {
+		t.Fatalf("ScanMode got %d want %d",
+			got.ScanMode, want.ScanMode)
+	}
+	if got.Speed != want.Speed {
+		t.Fatalf("Speed got %d want %d",
+			got.Speed, want.Speed)
+	}
+	if got.Noise != want.Noise {
+		t.Fatalf("Noise got %v want %v",
+			got.Noise, want.Noise)
+	}
+	if got.Particles.MakeCode() != want.Particles.MakeCode() {
+		t.Fatalf("Particles got %s want %s",
+			got.Particles.MakeCode(), want.Particles.MakeCode())
+	}
+	if got.Text.Message != want.Text.Message ||
+		got.Text.Direction != want.Text.Direction ||
+		got.Text.Gradient != want.Text.Gradient {
+		t.Fatalf("Text got %v want %v", got.Text, want.Text)
+	}
 }
 
 func TestLayerBasic(t *testing.T) {
@@ -0,0 +1,206 @@
+package glow
+
+import (
+	"fmt"
+	"os"
+)
+
+type Severity uint16
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+	SEVERITY_COUNT
+)
+
+func (severity Severity) String() string {
+	if severity == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// LintFrame is the layer of diagnostics about the frame itself.
+const LintFrame = -1
+
+// Diagnostic is a problem Lint finds with a frame. Warnings are
+// settings the engine quietly corrects or that have no effect, and
+// errors are ones it cannot render as meant. Field is the yaml name
+// of the setting, empty when the diagnostic is about the whole layer.
+type Diagnostic struct {
+	Severity Severity `yaml:"severity" json:"severity"`
+	Layer    int      `yaml:"layer" json:"layer"`
+	Field    string   `yaml:"field" json:"field"`
+	Message  string   `yaml:"message" json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	s := diagnostic.Severity.String()
+	if diagnostic.Layer == LintFrame {
+		s += " frame"
+	} else {
+		s += fmt.Sprintf(" layer %d", diagnostic.Layer+1)
+	}
+	if diagnostic.Field != "" {
+		s += " " + diagnostic.Field
+	}
+	return s + ": " + diagnostic.Message
+}
+
+// Lint reports the problems with the frame as stored, without
+// changing it. Scans are checked against the lights between begin
+// and end only once the frame has a length.
+func (frame *Frame) Lint() (diagnostics []Diagnostic) {
+	add := func(severity Severity, layer int, field, format string, a ...any) {
+		diagnostics = append(diagnostics, Diagnostic{Severity: severity,
+			Layer: layer, Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if frame.Interval > 0 && frame.Interval < MinimumInterval {
+		add(SeverityWarning, LintFrame, "interval",
+			"%d ms is below the minimum of %d ms", frame.Interval, MinimumInterval)
+	}
+
+	for i, layer := range frame.Layers {
+		if layer.Rate > 0 && layer.Rate < MinimumInterval {
+			add(SeverityWarning, i, "rate",
+				"%d ms is below the minimum of %d ms", layer.Rate, MinimumInterval)
+		}
+
+		for _, bound := range []struct {
+			field string
+			value uint16
+		}{{"begin", layer.Begin}, {"end", layer.End}} {
+			if bound.value > 100 {
+				add(SeverityWarning, i, bound.field,
+					"%d is over 100 percent and taken as %d", bound.value, bound.value%100)
+			}
+		}
+		begin, end := layer.span()
+		switch {
+		case begin == end:
+			add(SeverityWarning, i, "end",
+				"begin and end are both %d percent so the layer draws nothing", begin)
+		case layer.Begin <= 100 && layer.End <= 100 && layer.End != 0 && layer.Begin > layer.End:
+			add(SeverityWarning, i, "begin",
+				"%d is after end %d and the two are swapped", layer.Begin, layer.End)
+		}
+
+		if layer.Scan > 0 && frame.Length > 0 {
+			width := uint32(frame.Length) * uint32(end-begin) / 100
+			switch {
+			case layer.Scan > frame.Length:
+				add(SeverityWarning, i, "scan",
+					"%d is longer than the %d lights and is cut to fit", layer.Scan, frame.Length)
+			case uint32(layer.Scan) >= width && begin != end:
+				add(SeverityWarning, i, "scan",
+					"%d cannot move within the %d lights between begin and end", layer.Scan, width)
+			}
+		}
+
+		if IsSequence(layer.ImageName) {
+			if paths, err := SequencePaths(layer.ImageName); err != nil {
+				add(SeverityError, i, "image_name", "%v", err)
+			} else if len(paths) == 0 {
+				add(SeverityError, i, "image_name", "no files match %s", layer.ImageName)
+			}
+		} else if layer.ImageName != "" {
+			if _, err := os.Stat(layer.ImageName); err != nil {
+				add(SeverityError, i, "image_name", "%v", err)
+			}
+		}
+
+		for j, hsv := range layer.Chroma.Colors {
+			if hsv.Hue < 0 || hsv.Hue > 360 ||
+				hsv.Saturation < 0 || hsv.Saturation > 1 ||
+				hsv.Value < 0 || hsv.Value > 1 {
+				add(SeverityError, i, "chroma",
+					"color %d hue %g saturation %g value %g is outside 0-360, 0-1, 0-1",
+					j+1, hsv.Hue, hsv.Saturation, hsv.Value)
+			}
+		}
+
+		if layer.Expression != "" {
+			if _, err := CompileProgram(layer.Expression); err != nil {
+				add(SeverityError, i, "expression", "%v", err)
+			}
+		}
+
+		if above := frame.hiddenBy(i); above >= 0 {
+			add(SeverityWarning, i, "",
+				"hidden by layer %d which covers it at full opacity", above+1)
+		}
+	}
+	return
+}
+
+// span returns the begin and end percents as the layer draws them.
+func (layer *Layer) span() (begin, end uint16) {
+	begin, end = layer.Begin, layer.End
+	if end == 0 {
+		end = 100
+	}
+	if begin > 100 {
+		begin %= 100
+	}
+	if end > 100 {
+		end %= 100
+	}
+	if end < begin {
+		begin, end = end, begin
+	}
+	return
+}
+
+// moves reports whether any modulator moves one of targets.
+func (layer *Layer) moves(targets ...ModTarget) bool {
+	for _, mod := range layer.Modulators {
+		for _, target := range targets {
+			if mod.Target == target && mod.Depth != 0 && mod.Rate != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// opaque reports whether the layer replaces every light of its span.
+func (layer *Layer) opaque() bool {
+	return layer.Blend == BlendReplace &&
+		layer.OpacityPercent() >= MaximumPercent &&
+		layer.Mask == MaskNone && !layer.Clip && layer.Scan == 0 &&
+		layer.ImageName == "" && layer.Particles.Kind == ParticleNone &&
+		layer.Text.Message == "" &&
+		!layer.moves(ModBegin, ModEnd, ModScan, ModOpacity)
+}
+
+// hiddenBy returns the first layer above index drawn over all of it,
+// or -1. Masks, layers that draw nothing, layers clipped to from above
+// and layers above a mask are never counted.
+func (frame *Frame) hiddenBy(index int) int {
+	layer := frame.Layers[index]
+	if layer.Mask != MaskNone || layer.moves(ModBegin, ModEnd) ||
+		(index+1 < len(frame.Layers) && frame.Layers[index+1].Clip) {
+		return -1
+	}
+	for _, below := range frame.Layers[:index] {
+		if below.Mask != MaskNone {
+			return -1
+		}
+	}
+	begin, end := layer.span()
+	if begin == end {
+		return -1
+	}
+	for j := index + 1; j < len(frame.Layers); j++ {
+		above := frame.Layers[j]
+		if above.Mask != MaskNone {
+			return -1
+		}
+		aboveBegin, aboveEnd := above.span()
+		if above.opaque() && aboveBegin < aboveEnd && aboveBegin <= begin && aboveEnd >= end {
+			return j
+		}
+	}
+	return -1
+}
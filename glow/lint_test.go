@@ -0,0 +1,97 @@
+package glow
+
+import (
+	"testing"
+)
+
+func TestFrameLint(t *testing.T) {
+	color := func(hue, saturation, value float32) Chroma {
+		return Chroma{Colors: []HSV{{Hue: hue, Saturation: saturation, Value: value}}}
+	}
+	frame := &Frame{Length: 20, Rows: 1, Interval: 10, Layers: []*Layer{
+		{Chroma: color(0, 1, 1)},
+		{Begin: 80, End: 20, Chroma: color(400, 1, 1), Blend: BlendScreen},
+		{Begin: 50, End: 50, Rate: 5, Chroma: color(0, 2, 1)},
+		{Begin: 150, Scan: 30, ImageName: "missing.png", Chroma: color(0, 1, 1)},
+		{Begin: 40, End: 60, Scan: 4, Chroma: color(0, 1, 1), Expression: "hue = q",
+			ImageName: "missing_*.png"},
+		{Chroma: color(120, 1, 1)},
+	}}
+
+	want := []Diagnostic{
+		{SeverityWarning, LintFrame, "interval", ""},
+		{SeverityWarning, 0, "", ""},
+		{SeverityWarning, 1, "begin", ""},
+		{SeverityError, 1, "chroma", ""},
+		{SeverityWarning, 1, "", ""},
+		{SeverityWarning, 2, "rate", ""},
+		{SeverityWarning, 2, "end", ""},
+		{SeverityError, 2, "chroma", ""},
+		{SeverityWarning, 3, "begin", ""},
+		{SeverityWarning, 3, "scan", ""},
+		{SeverityError, 3, "image_name", ""},
+		{SeverityWarning, 3, "", ""},
+		{SeverityWarning, 4, "scan", ""},
+		{SeverityError, 4, "image_name", ""},
+		{SeverityError, 4, "expression", ""},
+		{SeverityWarning, 4, "", ""},
+	}
+	got := frame.Lint()
+	if len(got) != len(want) {
+		t.Fatalf("want %d diagnostics got %d %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i].Severity != want[i].Severity || got[i].Layer != want[i].Layer ||
+			got[i].Field != want[i].Field || got[i].Message == "" {
+			t.Fatalf("diagnostic %d want %v got %v", i, want[i], got[i])
+		}
+	}
+	if s := got[0].String(); s != "warning frame interval: 10 ms is below the minimum of 16 ms" {
+		t.Fatalf("string got %q", s)
+	}
+	if s := got[1].String(); s != "warning layer 1: hidden by layer 6 which covers it at full opacity" {
+		t.Fatalf("string got %q", s)
+	}
+	if frame.Layers[1].Begin != 80 || frame.Layers[3].Scan != 30 {
+		t.Fatalf("lint changed the frame")
+	}
+}
+
+func TestFrameLintHidden(t *testing.T) {
+	base := func() *Frame {
+		return &Frame{Interval: 48, Layers: []*Layer{
+			{Chroma: Chroma{Colors: []HSV{{Saturation: 1, Value: 1}}}},
+			{Chroma: Chroma{Colors: []HSV{{Saturation: 1, Value: 1}}}},
+		}}
+	}
+	tests := []struct {
+		change func(frame *Frame)
+		hidden bool
+	}{
+		{func(frame *Frame) {}, true},
+		{func(frame *Frame) { frame.Layers[1].Begin, frame.Layers[1].End = 10, 90 }, false},
+		{func(frame *Frame) {
+			frame.Layers[0].Begin, frame.Layers[0].End = 20, 80
+			frame.Layers[1].Begin, frame.Layers[1].End = 10, 90
+		}, true},
+		{func(frame *Frame) { frame.Layers[1].Opacity = Percent(50) }, false},
+		{func(frame *Frame) { frame.Layers[1].Scan = 5 }, false},
+		{func(frame *Frame) { frame.Layers[1].Clip = true }, false},
+		{func(frame *Frame) { frame.Layers[0].Mask = MaskAlpha }, false},
+		{func(frame *Frame) { frame.Layers[1].Particles.Kind = ParticleTwinkle }, false},
+		{func(frame *Frame) {
+			frame.Layers[1].Modulators = []Modulator{{Target: ModBegin, Rate: 100, Depth: 5}}
+		}, false},
+	}
+	for i, test := range tests {
+		frame := base()
+		test.change(frame)
+		hidden := false
+		for _, diagnostic := range frame.Lint() {
+			hidden = hidden || (diagnostic.Layer == 0 && diagnostic.Field == "")
+		}
+		if hidden != test.hidden {
+			t.Fatalf("test %d want hidden %v got %v", i, test.hidden, hidden)
+		}
+	}
+}
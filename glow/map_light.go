@@ -0,0 +1,45 @@
+package glow
+
+import "image/color"
+
+var _ Light = (*MapLight)(nil)
+
+// MapLight renders onto the virtual grid of a pixel map and
+// copies each mapped cell to its physical light on Refresh.
+type MapLight struct {
+	Map    *PixelMap
+	Target Light
+	cells  []color.NRGBA
+}
+
+func NewMapLight(pm *PixelMap, target Light) *MapLight {
+	ml := &MapLight{
+		Map:    pm,
+		Target: target,
+		cells:  make([]color.NRGBA, pm.Length()),
+	}
+	return ml
+}
+
+func (ml *MapLight) Length() uint16 {
+	return ml.Map.Length()
+}
+
+func (ml *MapLight) Rows() uint16 {
+	return ml.Map.Rows()
+}
+
+func (ml *MapLight) Get(i uint16) color.NRGBA {
+	return ml.cells[i]
+}
+
+func (ml *MapLight) Set(i uint16, c color.NRGBA) {
+	ml.cells[i] = c
+}
+
+func (ml *MapLight) Refresh() {
+	for i, offset := range ml.Map.lookup {
+		ml.Target.Set(uint16(i), ml.cells[offset])
+	}
+	ml.Target.Refresh()
+}
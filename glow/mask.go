@@ -0,0 +1,34 @@
+package glow
+
+import "image/color"
+
+// MaskMode is what a mask layer takes from its own drawing to let
+// the layers above it through.
+type MaskMode uint16
+
+const (
+	MaskNone MaskMode = iota
+	MaskLuminance
+	MaskAlpha
+	MASK_MODE_COUNT
+)
+
+// Cover returns how much a light drawn with c at alpha lets through,
+// from none to MaximumLevel. Alpha is how much of the light the layer
+// drew, so a dark color passes as much as a bright one.
+func (mode MaskMode) Cover(c color.NRGBA, alpha uint8) uint8 {
+	if mode != MaskLuminance {
+		return alpha
+	}
+	level := (77*uint32(c.R) + 150*uint32(c.G) + 29*uint32(c.B)) >> 8
+	return uint8(level * uint32(alpha) / MaximumLevel)
+}
+
+// mixLevel returns the color level of the way from one to the other.
+func mixLevel(from, to color.NRGBA, level uint8) color.NRGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(int(a) + (int(b)-int(a))*int(level)/MaximumLevel)
+	}
+	return color.NRGBA{R: mix(from.R, to.R), G: mix(from.G, to.G),
+		B: mix(from.B, to.B), A: 255}
+}
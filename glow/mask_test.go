@@ -0,0 +1,136 @@
+package glow
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestMaskCover(t *testing.T) {
+	tests := []struct {
+		mode  MaskMode
+		c     color.NRGBA
+		alpha uint8
+		want  uint8
+	}{
+		{MaskAlpha, color.NRGBA{R: 255, A: 255}, 255, 255},
+		{MaskAlpha, color.NRGBA{B: 20, A: 255}, 255, 255},
+		{MaskAlpha, color.NRGBA{G: 255, A: 255}, 51, 51},
+		{MaskLuminance, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, 255, 255},
+		{MaskLuminance, color.NRGBA{B: 255, A: 255}, 255, 28},
+		{MaskLuminance, color.NRGBA{}, 255, 0},
+	}
+	for _, test := range tests {
+		if got := test.mode.Cover(test.c, test.alpha); got != test.want {
+			t.Fatalf("mode %d %v at %d want %d got %d",
+				test.mode, test.c, test.alpha, test.want, got)
+		}
+	}
+}
+
+func maskFrame(t *testing.T, layers ...*Layer) *Frame {
+	frame := &Frame{Interval: 100}
+	frame.AddLayers(layers...)
+	if err := frame.Setup(10, 1); err != nil {
+		t.Fatalf("%v", err)
+	}
+	return frame
+}
+
+func solidLayer(hsv HSV) *Layer {
+	layer := &Layer{Grid: Grid{Orientation: Vertical}}
+	layer.Chroma.AddColors(hsv)
+	return layer
+}
+
+func TestFrameMask(t *testing.T) {
+	blue := color.NRGBA{B: 255, A: 255}
+	red := color.NRGBA{R: 255, A: 255}
+
+	band := solidLayer(HSV{Hue: 120, Saturation: 1, Value: 1})
+	band.Begin, band.End, band.Mask = 30, 60, MaskAlpha
+	frame := maskFrame(t,
+		solidLayer(HSV{Hue: 240, Saturation: 1, Value: 1}),
+		band,
+		solidLayer(HSV{Hue: 0, Saturation: 1, Value: 1}))
+
+	light := make(bufferLight, 10)
+	frame.Spin(light)
+	for i, c := range light {
+		want := blue
+		if i >= 3 && i < 6 {
+			want = red
+		}
+		if c != want {
+			t.Fatalf("light %d want %v got %v", i, want, c)
+		}
+	}
+
+	// an alpha mask passes all it draws however dark its color
+	band.Chroma.Colors[0].Value = .1
+	frame.Validate()
+	frame.Spin(light)
+	if light[3] != red {
+		t.Fatalf("dark alpha mask got %v", light[3])
+	}
+
+	// a dim luminance mask lets some of the layers above through
+	band.Mask = MaskLuminance
+	band.Begin, band.End = 0, 100
+	frame.Validate()
+	frame.Spin(light)
+	if light[0].R == 0 || light[0].B == 0 {
+		t.Fatalf("luminance mask got %v", light[0])
+	}
+}
+
+func TestFrameClip(t *testing.T) {
+	below := solidLayer(HSV{Hue: 240, Saturation: 1, Value: 1})
+	below.Begin, below.End = 0, 50
+	clipped := solidLayer(HSV{Hue: 0, Saturation: 1, Value: 1})
+	clipped.Clip = true
+	frame := maskFrame(t, below, clipped)
+
+	light := make(bufferLight, 10)
+	frame.Spin(light)
+	for i, c := range light {
+		want := color.NRGBA{A: 255}
+		if i < 5 {
+			want = color.NRGBA{R: 255, A: 255}
+		}
+		if c != want {
+			t.Fatalf("light %d want %v got %v", i, want, c)
+		}
+	}
+
+	// a dark layer below still shows all of the clipped layer
+	below.Chroma.Colors[0].Value = .1
+	frame.Validate()
+	frame.Spin(light)
+	if light[0] != (color.NRGBA{R: 255, A: 255}) {
+		t.Fatalf("clip to dark layer got %v", light[0])
+	}
+}
+
+func TestImageMask(t *testing.T) {
+	pic := image.NewNRGBA(image.Rect(0, 0, 10, 1))
+	pic.SetNRGBA(1, 0, color.NRGBA{B: 10, A: 255})
+	pic.SetNRGBA(2, 0, color.NRGBA{B: 10, A: 128})
+	mask := solidLayer(HSV{Hue: 120, Saturation: 1, Value: 1})
+	mask.Mask = MaskAlpha
+	frame := maskFrame(t,
+		solidLayer(HSV{Hue: 240, Saturation: 1, Value: 1}),
+		mask,
+		solidLayer(HSV{Hue: 0, Saturation: 1, Value: 1}))
+	mask.animation = &Animation{Frames: []*image.NRGBA{pic}, Delays: []uint32{0}}
+
+	light := make(bufferLight, 10)
+	frame.Spin(light)
+	// coverage follows the pixel alpha, not its dark color
+	want := []color.NRGBA{{B: 255, A: 255}, {R: 255, A: 255}, {R: 128, B: 127, A: 255}}
+	for i := range want {
+		if light[i] != want[i] {
+			t.Fatalf("light %d want %v got %v", i, want[i], light[i])
+		}
+	}
+}
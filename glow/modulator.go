@@ -0,0 +1,119 @@
+package glow
+
+import "fmt"
+
+// Waveform is the shape of a modulator's cycle.
+type Waveform uint16
+
+const (
+	WaveSine Waveform = iota
+	WaveTriangle
+	WaveSaw
+	WaveSquare
+	WaveRandom
+	WAVEFORM_COUNT
+)
+
+// ModTarget is the layer parameter a modulator moves.
+type ModTarget uint16
+
+const (
+	ModBegin ModTarget = iota
+	ModEnd
+	ModScan
+	ModOpacity
+	ModRate
+	ModHue
+	ModSaturation
+	ModValue
+	MOD_TARGET_COUNT
+)
+
+// waveUnit is the peak of a wave in 14 bit fixed point.
+const waveUnit = 1 << 14
+
+// Modulator swings a layer parameter about its set value. Each cycle
+// of Waveform lasts Rate milliseconds, starting Phase degrees in, and
+// moves the target up to Depth either way: percent for Begin, End,
+// Opacity, Saturation and Value, lights for Scan, milliseconds for
+// Rate and degrees for Hue. Hue, Saturation and Value change the
+// color at Stop of the layer's chroma.
+type Modulator struct {
+	Target   ModTarget `yaml:"target" json:"target"`
+	Waveform Waveform  `yaml:"waveform" json:"waveform"`
+	Rate     uint32    `yaml:"rate" json:"rate"`
+	Depth    int16     `yaml:"depth" json:"depth"`
+	Phase    uint16    `yaml:"phase" json:"phase"`
+	Stop     uint16    `yaml:"stop,omitempty" json:"stop,omitempty"`
+}
+
+func (mod *Modulator) Validate() {
+	if mod.Target >= MOD_TARGET_COUNT {
+		mod.Target = ModBegin
+	}
+	if mod.Waveform >= WAVEFORM_COUNT {
+		mod.Waveform = WaveSine
+	}
+	mod.Phase %= 360
+}
+
+// IsColor reports whether the modulator changes a chroma stop.
+func (mod *Modulator) IsColor() bool {
+	return mod.Target >= ModHue
+}
+
+// Offset returns the change to the target at time milliseconds.
+// A modulator without a rate stands still.
+func (mod *Modulator) Offset(time uint32) int32 {
+	if mod.Rate == 0 || mod.Depth == 0 {
+		return 0
+	}
+	return int32(mod.Depth) * mod.wave(time) / waveUnit
+}
+
+// wave returns the waveform at time between -waveUnit and waveUnit.
+func (mod *Modulator) wave(time uint32) int32 {
+	at := uint64(time)*65536/uint64(mod.Rate) + uint64(mod.Phase)*65536/360
+	turn := uint16(at)
+	switch mod.Waveform {
+	case WaveTriangle:
+		t := int32(turn)
+		if t < 16384 {
+			return t
+		}
+		if t < 49152 {
+			return 32768 - t
+		}
+		return t - 65536
+	case WaveSaw:
+		return int32(int16(turn)) / 2
+	case WaveSquare:
+		if turn < 32768 {
+			return waveUnit
+		}
+		return -waveUnit
+	case WaveRandom:
+		// wanders between a random level each cycle
+		cycle := uint32(at >> 16)
+		level := func(c uint32) int32 {
+			return int32(noiseHash(c, uint32(mod.Target), uint32(mod.Stop), 0)&0x7fff) - waveUnit
+		}
+		from, to := level(cycle), level(cycle+1)
+		return from + (to-from)*int32(turn>>2)/waveUnit
+	}
+	return fixedSine(turn)
+}
+
+func (mod *Modulator) MakeCode() string {
+	return fmt.Sprintf("{%d,%d,%d,%d,%d,%d}",
+		mod.Target, mod.Waveform, mod.Rate, mod.Depth, mod.Phase, mod.Stop)
+}
+
+// modOffsets are the changes the modulators give the layer this spin.
+type modOffsets struct {
+	begin, end, scan, opacity, rate int32
+}
+
+func clampOffset(value uint32, offset int32, low, high int32) uint32 {
+	return uint32(min(max(int32(value)+offset, low), high))
+}
@@ -0,0 +1,105 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestModulatorWaves(t *testing.T) {
+	tests := []struct {
+		waveform Waveform
+		want     []int32
+	}{
+		{WaveSine, []int32{0, 100, 0, -100}},
+		{WaveTriangle, []int32{0, 100, 0, -100}},
+		{WaveSaw, []int32{0, 50, -100, -50}},
+		{WaveSquare, []int32{100, 100, -100, -100}},
+	}
+	for _, test := range tests {
+		mod := Modulator{Waveform: test.waveform, Rate: 1000, Depth: 100}
+		for quarter, want := range test.want {
+			if got := mod.Offset(uint32(quarter * 250)); got != want {
+				t.Fatalf("waveform %d quarter %d want %d got %d",
+					test.waveform, quarter, want, got)
+			}
+		}
+	}
+
+	mod := Modulator{Waveform: WaveSine, Rate: 1000, Depth: 100, Phase: 90}
+	if got := mod.Offset(0); got != 100 {
+		t.Fatalf("phase 90 want 100 got %d", got)
+	}
+	mod.Rate = 0
+	if got := mod.Offset(250); got != 0 {
+		t.Fatalf("no rate got %d", got)
+	}
+
+	random := Modulator{Waveform: WaveRandom, Rate: 100, Depth: 50}
+	last := random.Offset(0)
+	for time := uint32(1); time < 1000; time++ {
+		got := random.Offset(time)
+		if got < -50 || got > 50 {
+			t.Fatalf("random at %d out of depth %d", time, got)
+		}
+		if got-last > 2 || last-got > 2 {
+			t.Fatalf("random at %d jumped from %d to %d", time, last, got)
+		}
+		last = got
+	}
+}
+
+func TestModulatorValidate(t *testing.T) {
+	mod := Modulator{Target: MOD_TARGET_COUNT, Waveform: WAVEFORM_COUNT, Phase: 450}
+	mod.Validate()
+	if mod.Target != ModBegin || mod.Waveform != WaveSine || mod.Phase != 90 {
+		t.Fatalf("validate got %v", mod)
+	}
+}
+
+func TestLayerModulate(t *testing.T) {
+	layer := &Layer{
+		Grid:  Grid{Orientation: Vertical},
+		Begin: 20,
+		End:   80,
+		Modulators: []Modulator{
+			{Target: ModBegin, Waveform: WaveSquare, Rate: 200, Depth: 10},
+			{Target: ModOpacity, Waveform: WaveSquare, Rate: 200, Depth: -50},
+			{Target: ModHue, Waveform: WaveSquare, Rate: 200, Depth: 120},
+		},
+	}
+	layer.Chroma.AddColors(HSV{Hue: 0, Saturation: 1, Value: 1})
+	if err := layer.SetupLength(10, 1); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	light := make(bufferLight, 10)
+	layer.spin(light, 100, 0, MaximumPercent, MaximumPercent)
+	if layer.first != 3 {
+		t.Fatalf("modulated first want 3 got %d", layer.first)
+	}
+	if light[2] != (color.NRGBA{}) {
+		t.Fatalf("light before first got %v", light[2])
+	}
+	if light[3].G == 0 || light[3].R != 0 {
+		t.Fatalf("modulated hue got %v", light[3])
+	}
+	if layer.alpha != uint8(percentLevel(50)) {
+		t.Fatalf("modulated opacity got %d", layer.alpha)
+	}
+
+	layer.spin(light, 100, 0, MaximumPercent, MaximumPercent)
+	if layer.first != 1 {
+		t.Fatalf("modulated first want 1 got %d", layer.first)
+	}
+	if hue := layer.Chroma.Colors[0].Hue; hue != 240 {
+		t.Fatalf("modulated hue want 240 got %f", hue)
+	}
+
+	if err := layer.Validate(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if layer.first != 2 || layer.Chroma.Colors[0].Hue != 0 {
+		t.Fatalf("validate kept modulation first %d hue %f",
+			layer.first, layer.Chroma.Colors[0].Hue)
+	}
+}
@@ -0,0 +1,145 @@
+package glow
+
+import "fmt"
+
+type NoiseKind uint16
+
+const (
+	NoiseNone NoiseKind = iota
+	NoiseFire
+	NoisePlasma
+	NoiseLava
+	NoiseClouds
+	NOISE_COUNT
+)
+
+// NoiseUnit is one lattice cell in the fixed point noise space.
+const NoiseUnit = 256
+
+type noisePreset struct {
+	scale   uint32
+	drift   uint32
+	octaves uint32
+}
+
+var noisePresets = [NOISE_COUNT]noisePreset{
+	{},
+	{scale: 64, drift: 48, octaves: 2},
+	{scale: 48, drift: 24, octaves: 1},
+	{scale: 32, drift: 8, octaves: 2},
+	{scale: 40, drift: 12, octaves: 3},
+}
+
+// Noise colors a layer from seeded value noise that evolves with each
+// step. Scale is the distance between lights and Drift the distance
+// travelled per step, both in 1/256 of a lattice cell. Zero selects
+// the default for the kind.
+type Noise struct {
+	Kind  NoiseKind `yaml:"kind" json:"kind"`
+	Seed  uint32    `yaml:"seed" json:"seed"`
+	Scale uint16    `yaml:"scale" json:"scale"`
+	Drift uint16    `yaml:"drift" json:"drift"`
+}
+
+func (noise *Noise) Validate() {
+	if noise.Kind >= NOISE_COUNT {
+		noise.Kind = NoiseNone
+	}
+}
+
+// Sample returns the noise level of the light at column x and row y
+// of a grid with rows rows after spins steps.
+func (noise *Noise) Sample(x, y, rows uint16, spins uint32) uint8 {
+	preset := noisePresets[noise.Kind]
+	scale, drift := uint32(noise.Scale), uint32(noise.Drift)
+	if scale == 0 {
+		scale = preset.scale
+	}
+	if drift == 0 {
+		drift = preset.drift
+	}
+
+	px, py := uint32(x)*scale, uint32(y)*scale
+	time := spins * drift
+	switch noise.Kind {
+	case NoiseFire:
+		// flames rise through a field that cools with height
+		v := noiseFractal(px, py+time, time/2, preset.octaves, noise.Seed)
+		heat := (uint32(y) + 1) * MaximumLevel / uint32(max(rows, 1))
+		return uint8(v * heat / MaximumLevel)
+	case NoisePlasma:
+		v := (noiseFractal(px, py, time, preset.octaves, noise.Seed) * 4) & 511
+		if v > MaximumLevel {
+			v = 511 - v
+		}
+		return uint8(v)
+	case NoiseLava:
+		v := int32(noiseFractal(px, py, time, preset.octaves, noise.Seed))*2 - MaximumLevel
+		if v < 0 {
+			v = -v
+		}
+		return uint8(MaximumLevel - v)
+	case NoiseClouds:
+		return uint8(noiseFractal(px, py, time, preset.octaves, noise.Seed))
+	}
+	return 0
+}
+
+func (noise *Noise) MakeCode() string {
+	return fmt.Sprintf("{%d,%d,%d,%d}",
+		noise.Kind, noise.Seed, noise.Scale, noise.Drift)
+}
+
+func noiseHash(x, y, z, seed uint32) uint32 {
+	h := seed ^ x*0x8da6b343 ^ y*0xd8163841 ^ z*0xcb1ab31f
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return h
+}
+
+// noiseFade eases t in 0..NoiseUnit with smoothstep.
+func noiseFade(t uint32) uint32 {
+	return t * t * (3*NoiseUnit - 2*t) / (NoiseUnit * NoiseUnit)
+}
+
+func noiseLerp(a, b, t uint32) uint32 {
+	return uint32(int32(a) + (int32(b)-int32(a))*int32(t)/NoiseUnit)
+}
+
+// noiseValue interpolates the lattice values around a fixed point
+// position, returning 0..255.
+func noiseValue(x, y, z, seed uint32) uint32 {
+	xi, yi, zi := x/NoiseUnit, y/NoiseUnit, z/NoiseUnit
+	u := noiseFade(x % NoiseUnit)
+	v := noiseFade(y % NoiseUnit)
+	w := noiseFade(z % NoiseUnit)
+
+	corner := func(dx, dy, dz uint32) uint32 {
+		return noiseHash(xi+dx, yi+dy, zi+dz, seed) & 0xff
+	}
+	plane := func(dz uint32) uint32 {
+		return noiseLerp(
+			noiseLerp(corner(0, 0, dz), corner(1, 0, dz), u),
+			noiseLerp(corner(0, 1, dz), corner(1, 1, dz), u), v)
+	}
+	return noiseLerp(plane(0), plane(1), w)
+}
+
+// noiseFractal sums octaves of noise at doubling frequency
+// and halving amplitude, returning 0..255.
+func noiseFractal(x, y, z, octaves, seed uint32) uint32 {
+	var sum, total uint32
+	amplitude := uint32(128)
+	for octave := uint32(0); octave < octaves; octave++ {
+		sum += noiseValue(x<<octave, y<<octave, z<<octave, seed+octave) * amplitude
+		total += amplitude
+		amplitude >>= 1
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
@@ -0,0 +1,83 @@
+package glow
+
+import "testing"
+
+func TestNoiseSample(t *testing.T) {
+	for kind := NoiseFire; kind < NOISE_COUNT; kind++ {
+		noise := Noise{Kind: kind, Seed: 7}
+		again := Noise{Kind: kind, Seed: 7}
+		other := Noise{Kind: kind, Seed: 8}
+
+		differs, moves := false, false
+		for x := uint16(0); x < 16; x++ {
+			for y := uint16(0); y < 4; y++ {
+				level := noise.Sample(x, y, 4, 10)
+				if level != again.Sample(x, y, 4, 10) {
+					t.Fatalf("kind %d not deterministic at %d,%d", kind, x, y)
+				}
+				differs = differs || level != other.Sample(x, y, 4, 10)
+				moves = moves || level != noise.Sample(x, y, 4, 11)
+			}
+		}
+		if !differs {
+			t.Fatalf("kind %d ignores seed", kind)
+		}
+		if !moves {
+			t.Fatalf("kind %d does not evolve", kind)
+		}
+	}
+
+	var none Noise
+	if none.Sample(3, 3, 4, 3) != 0 {
+		t.Fatalf("NoiseNone sampled")
+	}
+	invalid := Noise{Kind: NOISE_COUNT}
+	invalid.Validate()
+	if invalid.Kind != NoiseNone {
+		t.Fatalf("Validate kind got %d", invalid.Kind)
+	}
+}
+
+func TestNoiseFire(t *testing.T) {
+	fire := Noise{Kind: NoiseFire, Seed: 1}
+	var top, bottom uint32
+	for spin := uint32(0); spin < 32; spin++ {
+		for x := uint16(0); x < 16; x++ {
+			top += uint32(fire.Sample(x, 0, 8, spin))
+			bottom += uint32(fire.Sample(x, 7, 8, spin))
+		}
+	}
+	if bottom <= top*4 {
+		t.Fatalf("fire bottom %d not hotter than top %d", bottom, top)
+	}
+}
+
+func TestNoiseLayer(t *testing.T) {
+	render := func() *ImageLight {
+		var chroma Chroma
+		chroma.AddColors(HSV{HueRed, 1, 1}, HSV{HueBlue, 1, 1})
+		frame := &Frame{Interval: 48}
+		frame.AddLayers(&Layer{Chroma: chroma,
+			Noise: Noise{Kind: NoiseClouds, Seed: 3}})
+		light := NewImageLight(16, 2)
+		err := frame.Setup(light.Length(), light.Rows())
+		if err != nil {
+			t.Fatal(err)
+		}
+		frame.Spin(light)
+		frame.Spin(light)
+		return light
+	}
+
+	light, again := render(), render()
+	distinct := make(map[uint8]bool)
+	for i := uint16(0); i < light.Length(); i++ {
+		if light.Get(i) != again.Get(i) {
+			t.Fatalf("light %d want %v got %v", i, light.Get(i), again.Get(i))
+		}
+		distinct[light.Get(i).R] = true
+	}
+	if len(distinct) < 3 {
+		t.Fatalf("noise layer too flat %v", distinct)
+	}
+}
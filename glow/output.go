@@ -0,0 +1,123 @@
+package glow
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+const (
+	NeutralTemperature = 6500
+	MinimumTemperature = 1000
+	MaximumTemperature = 40000
+)
+
+// Output corrects colors on their way to physical lights. Gamma is
+// the exponent for the red, green and blue channels, WhitePoint the
+// level of each channel that makes white, Temperature the color
+// temperature in kelvin that white is shifted to and Brightness a
+// percentage cap on every channel. Zero selects no correction.
+type Output struct {
+	Gamma       [3]float32 `yaml:"gamma" json:"gamma"`
+	WhitePoint  [3]uint8   `yaml:"white_point" json:"white_point"`
+	Temperature uint16     `yaml:"temperature" json:"temperature"`
+	Brightness  uint16     `yaml:"brightness" json:"brightness"`
+
+	tables [3][256]uint8
+	ready  bool
+}
+
+// Setup clamps the settings and builds the lookup table of each channel.
+func (out *Output) Setup() {
+	if out.Temperature != 0 {
+		out.Temperature = max(min(out.Temperature, MaximumTemperature), MinimumTemperature)
+	}
+	if out.Brightness == 0 || out.Brightness > MaximumPercent {
+		out.Brightness = MaximumPercent
+	}
+
+	white := temperatureBalance(out.Temperature)
+	for ch := range out.tables {
+		gamma := float64(out.Gamma[ch])
+		if gamma <= 0 {
+			gamma = 1
+		}
+		scale := white[ch] * float64(out.Brightness) / MaximumPercent
+		if out.WhitePoint[ch] != 0 {
+			scale *= float64(out.WhitePoint[ch]) / MaximumLevel
+		}
+		for v := range out.tables[ch] {
+			level := math.Pow(float64(v)/MaximumLevel, gamma) * scale
+			out.tables[ch][v] = uint8(math.Round(level * MaximumLevel))
+		}
+	}
+	out.ready = true
+}
+
+// Correct returns c as it should be sent to the lights.
+func (out *Output) Correct(c color.NRGBA) color.NRGBA {
+	if !out.ready {
+		out.Setup()
+	}
+	c.R = out.tables[0][c.R]
+	c.G = out.tables[1][c.G]
+	c.B = out.tables[2][c.B]
+	return c
+}
+
+// MakeCode emits the lookup tables of red, green and blue.
+func (out *Output) MakeCode() string {
+	if !out.ready {
+		out.Setup()
+	}
+	var s strings.Builder
+	s.WriteString("{")
+	for _, table := range out.tables {
+		s.WriteString("{")
+		for _, v := range table {
+			fmt.Fprintf(&s, "%d,", v)
+		}
+		s.WriteString("},")
+	}
+	s.WriteString("}")
+	return s.String()
+}
+
+// temperatureBalance returns the level of red, green and blue that
+// shifts white from the neutral temperature to kelvin, using Tanner
+// Helland's fit of the blackbody curve.
+func temperatureBalance(kelvin uint16) (balance [3]float64) {
+	balance = [3]float64{1, 1, 1}
+	if kelvin == 0 || kelvin == NeutralTemperature {
+		return
+	}
+	target, neutral := blackbody(float64(kelvin)), blackbody(NeutralTemperature)
+	for ch := range balance {
+		balance[ch] = min(target[ch]/neutral[ch], 1)
+	}
+	return
+}
+
+func blackbody(kelvin float64) (rgb [3]float64) {
+	t := kelvin / 100
+	clamp := func(v float64) float64 {
+		return max(min(v, MaximumLevel), 0)
+	}
+	if t <= 66 {
+		rgb[0] = MaximumLevel
+		rgb[1] = clamp(99.4708025861*math.Log(t) - 161.1195681661)
+	} else {
+		rgb[0] = clamp(329.698727446 * math.Pow(t-60, -0.1332047592))
+		rgb[1] = clamp(288.1221695283 * math.Pow(t-60, -0.0755148492))
+	}
+	switch {
+	case t >= 66:
+		rgb[2] = MaximumLevel
+	case t <= 19:
+		rgb[2] = 0
+	default:
+		rgb[2] = clamp(138.5177312231*math.Log(t-10) - 305.0447927307)
+	}
+	return
+}
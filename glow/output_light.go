@@ -0,0 +1,41 @@
+package glow
+
+import "image/color"
+
+var _ Light = (*OutputLight)(nil)
+
+// OutputLight corrects each color set on it for the Target lights.
+// Get returns colors as they were set so layers blend uncorrected.
+type OutputLight struct {
+	Output *Output
+	Target Light
+	cells  []color.NRGBA
+}
+
+func NewOutputLight(out *Output, target Light) *OutputLight {
+	out.Setup()
+	ol := &OutputLight{
+		Output: out,
+		Target: target,
+	}
+	return ol
+}
+
+func (ol *OutputLight) Get(i uint16) color.NRGBA {
+	if int(i) >= len(ol.cells) {
+		return color.NRGBA{}
+	}
+	return ol.cells[i]
+}
+
+func (ol *OutputLight) Set(i uint16, c color.NRGBA) {
+	if int(i) >= len(ol.cells) {
+		ol.cells = append(ol.cells, make([]color.NRGBA, int(i)+1-len(ol.cells))...)
+	}
+	ol.cells[i] = c
+	ol.Target.Set(i, ol.Output.Correct(c))
+}
+
+func (ol *OutputLight) Refresh() {
+	ol.Target.Refresh()
+}
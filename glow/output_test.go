@@ -0,0 +1,76 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestOutputIdentity(t *testing.T) {
+	var out Output
+	out.Setup()
+	for v := 0; v < 256; v++ {
+		c := color.NRGBA{uint8(v), uint8(v), uint8(v), 255}
+		if got := out.Correct(c); got != c {
+			t.Fatalf("identity %d got %v", v, got)
+		}
+	}
+}
+
+func TestOutputCorrect(t *testing.T) {
+	out := Output{Gamma: [3]float32{2.2, 2.2, 2.2}}
+	out.Setup()
+	got := out.Correct(color.NRGBA{128, 255, 0, 255})
+	if got != (color.NRGBA{56, 255, 0, 255}) {
+		t.Fatalf("gamma got %v", got)
+	}
+
+	out = Output{Gamma: [3]float32{1, 2, 3}, Brightness: 50}
+	out.Setup()
+	got = out.Correct(color.NRGBA{255, 128, 128, 255})
+	if got != (color.NRGBA{128, 32, 16, 255}) {
+		t.Fatalf("per channel gamma with brightness got %v", got)
+	}
+
+	out = Output{WhitePoint: [3]uint8{255, 176, 240}}
+	out.Setup()
+	got = out.Correct(color.NRGBA{255, 255, 255, 255})
+	if got != (color.NRGBA{255, 176, 240, 255}) {
+		t.Fatalf("white point got %v", got)
+	}
+
+	warm := Output{Temperature: 3000}
+	warm.Setup()
+	got = warm.Correct(color.NRGBA{255, 255, 255, 255})
+	if got.R != 255 || got.G >= got.R || got.B >= got.G {
+		t.Fatalf("warm white got %v", got)
+	}
+
+	neutral := Output{Temperature: NeutralTemperature}
+	neutral.Setup()
+	if got = neutral.Correct(color.NRGBA{255, 255, 255, 255}); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("neutral white got %v", got)
+	}
+}
+
+func TestOutputLight(t *testing.T) {
+	target := newTestLight(4)
+	ol := NewOutputLight(&Output{Brightness: 50}, target)
+
+	layer := &Layer{}
+	layer.Chroma.AddColors(HSV{HueRed, 1, 1})
+	frame := &Frame{}
+	frame.AddLayers(layer)
+	if err := frame.Setup(4, 1); err != nil {
+		t.Fatal(err)
+	}
+	frame.Spin(ol)
+
+	for i := uint16(0); i < 4; i++ {
+		if ol.Get(i) != (color.NRGBA{255, 0, 0, 255}) {
+			t.Fatalf("light %d uncorrected got %v", i, ol.Get(i))
+		}
+		if target.Get(i) != (color.NRGBA{128, 0, 0, 255}) {
+			t.Fatalf("light %d corrected got %v", i, target.Get(i))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package glow
+
+import "fmt"
+
+// Palette is a named list of colors shared by layers. Positions
+// place the colors as in Chroma.
+type Palette struct {
+	Title     string   `yaml:"title" json:"title"`
+	Colors    []HSV    `yaml:"colors" json:"colors"`
+	Positions []uint16 `yaml:"positions,omitempty" json:"positions,omitempty"`
+}
+
+// PaletteLookup finds a palette by title.
+type PaletteLookup func(title string) (*Palette, error)
+
+// ApplyPalette copies the colors of palette unless the chroma
+// overrides them with its own.
+func (chroma *Chroma) ApplyPalette(palette *Palette) {
+	if chroma.Override || palette == nil {
+		return
+	}
+	chroma.Colors = append([]HSV{}, palette.Colors...)
+	chroma.Positions = nil
+	if len(palette.Positions) > 0 {
+		chroma.Positions = append([]uint16{}, palette.Positions...)
+	}
+}
+
+// ResolvePalettes applies the palettes referenced by each layer.
+// Layers whose palette is missing keep the colors saved with them.
+func (frame *Frame) ResolvePalettes(lookup PaletteLookup) (err error) {
+	for i, layer := range frame.Layers {
+		title := layer.Chroma.Palette
+		if title == "" || layer.Chroma.Override {
+			continue
+		}
+		palette, lookupErr := lookup(title)
+		if lookupErr != nil {
+			if err == nil {
+				err = fmt.Errorf("layer %d palette %s: %v", i+1, title, lookupErr)
+			}
+			continue
+		}
+		layer.Chroma.ApplyPalette(palette)
+	}
+	return
+}
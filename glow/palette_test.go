@@ -0,0 +1,47 @@
+package glow
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolvePalettes(t *testing.T) {
+	brand := &Palette{Title: "brand", Colors: []HSV{{200, 1, 1}, {40, 1, 1}}, Positions: []uint16{0, 80}}
+	lookup := func(title string) (*Palette, error) {
+		if title == brand.Title {
+			return brand, nil
+		}
+		return nil, fmt.Errorf("not found")
+	}
+
+	shared, own, missing := &Layer{}, &Layer{}, &Layer{}
+	shared.Chroma.Palette = "brand"
+	shared.Chroma.AddColors(HSV{0, 0, 1})
+	own.Chroma.Palette = "brand"
+	own.Chroma.Override = true
+	own.Chroma.AddColors(HSV{120, 1, 1})
+	missing.Chroma.Palette = "gone"
+	missing.Chroma.AddColors(HSV{60, 1, 1})
+
+	frame := &Frame{}
+	frame.AddLayers(shared, own, missing)
+	if err := frame.ResolvePalettes(lookup); err == nil {
+		t.Fatalf("missing palette not reported")
+	}
+
+	if len(shared.Chroma.Colors) != 2 || shared.Chroma.Colors[1] != brand.Colors[1] ||
+		shared.Chroma.Positions[1] != 80 {
+		t.Fatalf("shared got %v %v", shared.Chroma.Colors, shared.Chroma.Positions)
+	}
+	if len(own.Chroma.Colors) != 1 || own.Chroma.Colors[0] != (HSV{120, 1, 1}) {
+		t.Fatalf("override got %v", own.Chroma.Colors)
+	}
+	if len(missing.Chroma.Colors) != 1 || missing.Chroma.Colors[0] != (HSV{60, 1, 1}) {
+		t.Fatalf("missing got %v", missing.Chroma.Colors)
+	}
+
+	brand.Colors[0] = HSV{300, 1, 1}
+	if shared.Chroma.Colors[0] == brand.Colors[0] {
+		t.Fatalf("palette colors shared by reference")
+	}
+}
@@ -0,0 +1,227 @@
+package glow
+
+import (
+	"fmt"
+	"image/color"
+)
+
+type ParticleKind uint16
+
+const (
+	ParticleNone ParticleKind = iota
+	ParticleTwinkle
+	ParticleMeteor
+	ParticleConfetti
+	PARTICLE_COUNT
+)
+
+type FadeCurve uint16
+
+const (
+	FadeLinear FadeCurve = iota
+	FadeQuadratic
+	FadeSudden
+	FADE_COUNT
+)
+
+const (
+	MaxParticles     = 32
+	DefaultSpawn     = 50
+	DefaultLifetime  = 16
+	DefaultTail      = 4
+	DefaultParticles = 2463534242
+)
+
+type particle struct {
+	position uint16
+	color    uint16
+	age      uint16
+}
+
+// Particles spawns short lived lights. Spawn is the percent chance
+// of a new particle each step, every hundred adding one more, and
+// Lifetime the steps each lives. Meteors travel at the layer's speed
+// until their tail leaves the layer unless they have a lifetime.
+// Zero selects the default spawn, lifetime and tail.
+type Particles struct {
+	Kind     ParticleKind `yaml:"kind" json:"kind"`
+	Spawn    uint16       `yaml:"spawn" json:"spawn"`
+	Lifetime uint16       `yaml:"lifetime" json:"lifetime"`
+	Fade     FadeCurve    `yaml:"fade" json:"fade"`
+	Tail     uint16       `yaml:"tail" json:"tail"`
+	Seed     uint32       `yaml:"seed" json:"seed"`
+
+	pool  [MaxParticles]particle
+	count uint16
+	state uint32
+}
+
+// Validate clamps the settings and restarts the particles from the seed.
+func (p *Particles) Validate() {
+	if p.Kind >= PARTICLE_COUNT {
+		p.Kind = ParticleNone
+	}
+	if p.Fade >= FADE_COUNT {
+		p.Fade = FadeLinear
+	}
+	p.count = 0
+	p.state = p.Seed
+	if p.state == 0 {
+		p.state = DefaultParticles
+	}
+}
+
+func (p *Particles) Count() int {
+	return int(p.count)
+}
+
+func (p *Particles) MakeCode() string {
+	return fmt.Sprintf("{%d,%d,%d,%d,%d,%d}",
+		p.Kind, p.Spawn, p.Lifetime, p.Fade, p.Tail, p.Seed)
+}
+
+func (p *Particles) random() uint32 {
+	p.state ^= p.state << 13
+	p.state ^= p.state >> 17
+	p.state ^= p.state << 5
+	return p.state
+}
+
+func (p *Particles) lifetime() uint16 {
+	if p.Lifetime == 0 && p.Kind != ParticleMeteor {
+		return DefaultLifetime
+	}
+	return p.Lifetime
+}
+
+func (p *Particles) tail() uint16 {
+	if p.Tail == 0 {
+		return DefaultTail
+	}
+	return p.Tail
+}
+
+// level returns the brightness of a particle, or of the light k
+// behind the head of a meteor, shaped by the fade curve.
+func (p *Particles) level(pt *particle, k uint16) uint8 {
+	var remaining uint32
+	switch p.Kind {
+	case ParticleMeteor:
+		remaining = MaximumLevel - uint32(k)*MaximumLevel/(uint32(p.tail())+1)
+	case ParticleTwinkle:
+		t := int32(uint32(pt.age) * MaximumLevel / uint32(p.lifetime()))
+		t = 2*t - MaximumLevel
+		if t < 0 {
+			t = -t
+		}
+		remaining = uint32(MaximumLevel - t)
+	default:
+		remaining = MaximumLevel - uint32(pt.age)*MaximumLevel/uint32(p.lifetime())
+	}
+
+	switch p.Fade {
+	case FadeQuadratic:
+		remaining = remaining * remaining / MaximumLevel
+	case FadeSudden:
+		if remaining > 0 {
+			remaining = MaximumLevel
+		}
+	}
+	return uint8(remaining)
+}
+
+// update ages, moves and retires particles then spawns new ones
+// between first and last.
+func (p *Particles) update(first, last, length, speed uint16) {
+	span := last - first
+	if span == 0 {
+		return
+	}
+	if speed == 0 {
+		speed = 1
+	}
+
+	life := p.lifetime()
+	alive := uint16(0)
+	for i := uint16(0); i < p.count; i++ {
+		pt := p.pool[i]
+		pt.age++
+		if p.Kind == ParticleMeteor {
+			pt.position += speed
+			if pt.position-first >= span+p.tail() {
+				continue
+			}
+		}
+		if life > 0 && pt.age >= life {
+			continue
+		}
+		p.pool[alive] = pt
+		alive++
+	}
+	p.count = alive
+
+	spawn := uint32(p.Spawn)
+	if spawn == 0 {
+		spawn = DefaultSpawn
+	}
+	spawns := spawn / 100
+	if p.random()%100 < spawn%100 {
+		spawns++
+	}
+	for ; spawns > 0 && p.count < MaxParticles; spawns-- {
+		pt := particle{position: first}
+		if p.Kind != ParticleMeteor {
+			pt.position = first + uint16(p.random()%uint32(span))
+		}
+		pt.color = pt.position
+		if p.Kind != ParticleTwinkle {
+			pt.color = uint16(p.random() % uint32(length))
+		}
+		p.pool[p.count] = pt
+		p.count++
+	}
+}
+
+// spinParticles draws the particles. A replacing layer owns its range
+// and clears it first; other blend modes leave the lights beneath.
+func (layer *Layer) spinParticles(light Light, steps uint32) {
+	p := &layer.Particles
+	if layer.Blend == BlendReplace {
+		for i := layer.first; i < layer.last; i++ {
+			layer.putDrawn(light, layer.Grid.Map(i), color.NRGBA{A: 255}, 0)
+		}
+	}
+	for i := uint16(0); i < p.count; i++ {
+		pt := &p.pool[i]
+		tail := uint16(0)
+		if p.Kind == ParticleMeteor {
+			tail = p.tail()
+		}
+		for k := uint16(0); k <= tail; k++ {
+			if pt.position < layer.first+k {
+				break
+			}
+			at := pt.position - k
+			if at >= layer.last {
+				continue
+			}
+			layer.putLevel(light, layer.Grid.Map(at),
+				layer.Chroma.Map(pt.color), p.level(pt, k))
+		}
+	}
+
+	for i := uint32(0); i < steps; i++ {
+		p.update(layer.first, layer.last, layer.Length, layer.Speed)
+		layer.Chroma.UpdateColors()
+	}
+}
+
+func (layer *Layer) putLevel(light Light, i uint16, c color.NRGBA, level uint8) {
+	if level == 0 {
+		return
+	}
+	c.R = scaleColor(c.R, level)
+	c.G = scaleColor(c.G, level)
+	c.B = scaleColor(c.B, level)
+	layer.putDrawn(light, i, c, level)
+}
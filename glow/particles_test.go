@@ -0,0 +1,120 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+)
+
+func particleFrame(particles Particles) (*Frame, *Layer) {
+	var chroma Chroma
+	chroma.AddColors(HSV{HueRed, 1, 1}, HSV{HueBlue, 1, 1})
+	layer := &Layer{Chroma: chroma, Particles: particles}
+	frame := &Frame{Interval: 48}
+	frame.AddLayers(layer)
+	return frame, layer
+}
+
+func TestParticlesDeterministic(t *testing.T) {
+	for kind := ParticleTwinkle; kind < PARTICLE_COUNT; kind++ {
+		render := func(seed uint32) *ImageLight {
+			frame, _ := particleFrame(Particles{Kind: kind, Spawn: 150, Seed: seed})
+			light := NewImageLight(32, 1)
+			err := frame.Setup(light.Length(), light.Rows())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 10; i++ {
+				light.Fill(color.NRGBA{})
+				frame.Spin(light)
+			}
+			return light
+		}
+
+		light, again, other := render(3), render(3), render(4)
+		lit, differs := false, false
+		for i := uint16(0); i < light.Length(); i++ {
+			if light.Get(i) != again.Get(i) {
+				t.Fatalf("kind %d light %d want %v got %v",
+					kind, i, light.Get(i), again.Get(i))
+			}
+			lit = lit || light.Get(i) != color.NRGBA{}
+			differs = differs || light.Get(i) != other.Get(i)
+		}
+		if !lit {
+			t.Fatalf("kind %d nothing lit", kind)
+		}
+		if !differs {
+			t.Fatalf("kind %d ignores seed", kind)
+		}
+	}
+}
+
+func TestParticlesLifetime(t *testing.T) {
+	frame, layer := particleFrame(Particles{Kind: ParticleConfetti, Spawn: 1000, Lifetime: 3})
+	err := frame.Setup(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	light := NewImageLight(64, 1)
+	for i := 0; i < 8; i++ {
+		frame.Spin(light)
+		if layer.Particles.Count() > 30 {
+			t.Fatalf("spin %d count %d outlived lifetime", i, layer.Particles.Count())
+		}
+	}
+	if layer.Particles.Count() != 30 {
+		t.Fatalf("count want 30 got %d", layer.Particles.Count())
+	}
+
+	frame, layer = particleFrame(Particles{Kind: ParticleTwinkle, Spawn: 5000, Lifetime: 100})
+	frame.Setup(64, 1)
+	for i := 0; i < 4; i++ {
+		frame.Spin(light)
+	}
+	if layer.Particles.Count() != MaxParticles {
+		t.Fatalf("count want %d got %d", MaxParticles, layer.Particles.Count())
+	}
+
+	layer.Validate()
+	if layer.Particles.Count() != 0 {
+		t.Fatalf("Validate left %d particles", layer.Particles.Count())
+	}
+}
+
+func TestParticlesMeteor(t *testing.T) {
+	frame, layer := particleFrame(Particles{Kind: ParticleMeteor, Spawn: 100, Tail: 3})
+	layer.Speed = 2
+	layer.Chroma = Chroma{}
+	layer.Chroma.AddColors(HSV{HueRed, 1, 1})
+	err := frame.Setup(16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	light := NewImageLight(16, 1)
+	frame.Spin(light)
+	frame.Spin(light)
+	frame.Spin(light)
+
+	light.Fill(color.NRGBA{})
+	frame.Spin(light)
+	// a meteor launched each step, the oldest head at 4
+	want := []uint8{255, 192, 255, 192, 255, 0}
+	for i, level := range want {
+		got := light.Get(uint16(i)).R
+		if got != level {
+			t.Fatalf("light %d want %d got %d", i, level, got)
+		}
+	}
+
+	p := Particles{Kind: ParticleMeteor, Tail: 3}
+	levels := []uint8{255, 192, 128, 64}
+	for k, level := range levels {
+		if p.level(&particle{}, uint16(k)) != level {
+			t.Fatalf("tail %d want %d got %d", k, level, p.level(&particle{}, uint16(k)))
+		}
+	}
+	p.Fade = FadeSudden
+	if p.level(&particle{}, 3) != MaximumLevel {
+		t.Fatalf("sudden tail got %d", p.level(&particle{}, 3))
+	}
+}
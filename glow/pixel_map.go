@@ -0,0 +1,288 @@
+package glow
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Point is the position of a light in a pixel map.
+type Point struct {
+	X float32 `yaml:"x" json:"x"`
+	Y float32 `yaml:"y" json:"y"`
+}
+
+// PixelMap places each light of an irregular installation on a
+// virtual grid of Columns by Rows cells. Layers render the virtual
+// grid so they are sampled by position rather than by index.
+type PixelMap struct {
+	Name   string  `yaml:"name" json:"name"`
+	Scale  float32 `yaml:"scale" json:"scale"`
+	Points []Point `yaml:"points" json:"points"`
+
+	columns uint16
+	rows    uint16
+	lookup  []uint16
+}
+
+func NewPixelMap(name string, points ...Point) (pm *PixelMap, err error) {
+	pm = &PixelMap{Name: name, Points: points}
+	err = pm.Setup()
+	return
+}
+
+// Setup scales the points onto the virtual grid and
+// builds the lookup table of light index to grid offset.
+func (pm *PixelMap) Setup() error {
+	if len(pm.Points) == 0 {
+		return fmt.Errorf("PixelMap %s has no points", pm.Name)
+	}
+	if len(pm.Points) > math.MaxUint16 {
+		return fmt.Errorf("PixelMap %s has %d points", pm.Name, len(pm.Points))
+	}
+	if pm.Scale <= 0 {
+		pm.Scale = 1
+	}
+
+	minX, minY := pm.Points[0].X, pm.Points[0].Y
+	for _, p := range pm.Points {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+	}
+
+	cells := make([][2]int, len(pm.Points))
+	columns, rows := 1, 1
+	for i, p := range pm.Points {
+		x := int(math.Round(float64((p.X - minX) * pm.Scale)))
+		y := int(math.Round(float64((p.Y - minY) * pm.Scale)))
+		cells[i] = [2]int{x, y}
+		columns, rows = max(columns, x+1), max(rows, y+1)
+	}
+	if columns*rows > math.MaxUint16 {
+		return fmt.Errorf("PixelMap %s grid %dx%d is too large", pm.Name, columns, rows)
+	}
+
+	pm.columns, pm.rows = uint16(columns), uint16(rows)
+	pm.lookup = make([]uint16, len(cells))
+	for i, cell := range cells {
+		pm.lookup[i] = uint16(cell[1]*columns + cell[0])
+	}
+	return nil
+}
+
+func (pm *PixelMap) Columns() uint16 {
+	return pm.columns
+}
+
+func (pm *PixelMap) Rows() uint16 {
+	return pm.rows
+}
+
+// Length is the size of the virtual grid.
+func (pm *PixelMap) Length() uint16 {
+	return pm.columns * pm.rows
+}
+
+// Count is the number of physical lights.
+func (pm *PixelMap) Count() uint16 {
+	return uint16(len(pm.lookup))
+}
+
+// Offset returns the virtual grid offset of the light at index.
+func (pm *PixelMap) Offset(index uint16) uint16 {
+	return pm.lookup[index]
+}
+
+// Occupied reports which offsets of the virtual grid hold a light.
+func (pm *PixelMap) Occupied() []bool {
+	occupied := make([]bool, pm.Length())
+	for _, offset := range pm.lookup {
+		occupied[offset] = true
+	}
+	return occupied
+}
+
+func (pm *PixelMap) MakeCode() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "{%d,%d,{", pm.columns, pm.rows)
+	for _, offset := range pm.lookup {
+		fmt.Fprintf(&s, "%d,", offset)
+	}
+	s.WriteString("}}")
+	return s.String()
+}
+
+// LoadPixelMap reads a pixel map from a csv, json or xLights xmodel file.
+func LoadPixelMap(path string) (pm *PixelMap, err error) {
+	var rdr *os.File
+	rdr, err = os.Open(path)
+	if err != nil {
+		return
+	}
+	defer rdr.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		pm, err = DecodePixelMapCSV(rdr, name)
+	case ".json":
+		pm, err = DecodePixelMapJSON(rdr)
+	case ".xmodel":
+		pm, err = DecodePixelMapXModel(rdr)
+	default:
+		err = fmt.Errorf("LoadPixelMap unknown format %s", path)
+	}
+	return
+}
+
+func DecodePixelMapJSON(rdr io.Reader) (pm *PixelMap, err error) {
+	pm = &PixelMap{}
+	err = json.NewDecoder(rdr).Decode(pm)
+	if err == nil {
+		err = pm.Setup()
+	}
+	return
+}
+
+// DecodePixelMapCSV reads one light per line as either "x,y" in light
+// order or "index,x,y" in any order. Lines that do not begin with a
+// number are skipped as headers. Files with empty cells or more than
+// three columns are read as an xLights custom model grid.
+func DecodePixelMapCSV(rdr io.Reader, name string) (pm *PixelMap, err error) {
+	reader := csv.NewReader(rdr)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var records [][]string
+	records, err = reader.ReadAll()
+	if err != nil {
+		return
+	}
+
+	for _, record := range records {
+		if len(record) > 3 || hasEmptyCell(record) {
+			return decodeModelGrid(name, records)
+		}
+	}
+
+	type indexed struct {
+		index int
+		point Point
+	}
+	list := make([]indexed, 0, len(records))
+	for line, record := range records {
+		values := make([]float64, len(record))
+		for i, field := range record {
+			values[i], err = strconv.ParseFloat(field, 32)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if line == 0 {
+				err = nil
+				continue
+			}
+			err = fmt.Errorf("PixelMap %s line %d: %v", name, line+1, err)
+			return
+		}
+
+		item := indexed{index: len(list)}
+		switch len(values) {
+		case 2:
+			item.point = Point{float32(values[0]), float32(values[1])}
+		case 3:
+			item.index = int(values[0])
+			item.point = Point{float32(values[1]), float32(values[2])}
+		default:
+			err = fmt.Errorf("PixelMap %s line %d has %d fields", name, line+1, len(values))
+			return
+		}
+		list = append(list, item)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].index < list[j].index })
+	points := make([]Point, len(list))
+	for i, item := range list {
+		if i > 0 && item.index == list[i-1].index {
+			err = fmt.Errorf("PixelMap %s index %d repeated", name, item.index)
+			return
+		}
+		points[i] = item.point
+	}
+	return NewPixelMap(name, points...)
+}
+
+func hasEmptyCell(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+type xModel struct {
+	Name        string `xml:"name,attr"`
+	CustomModel string `xml:"CustomModel,attr"`
+}
+
+// DecodePixelMapXModel reads the first layer of an xLights custom model.
+func DecodePixelMapXModel(rdr io.Reader) (pm *PixelMap, err error) {
+	var model xModel
+	err = xml.NewDecoder(rdr).Decode(&model)
+	if err != nil {
+		return
+	}
+	layer, _, _ := strings.Cut(model.CustomModel, "|")
+	records := make([][]string, 0)
+	for _, row := range strings.Split(layer, ";") {
+		records = append(records, strings.Split(row, ","))
+	}
+	return decodeModelGrid(model.Name, records)
+}
+
+// decodeModelGrid places light n at the column and row of the
+// cell holding n, counting lights from one.
+func decodeModelGrid(name string, records [][]string) (pm *PixelMap, err error) {
+	located := make(map[int]Point)
+	count := 0
+	for y, record := range records {
+		for x, field := range record {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			var n int
+			n, err = strconv.Atoi(field)
+			if err != nil || n < 1 {
+				err = fmt.Errorf("PixelMap %s row %d column %d invalid light %q",
+					name, y+1, x+1, field)
+				return
+			}
+			if _, ok := located[n]; ok {
+				err = fmt.Errorf("PixelMap %s light %d repeated", name, n)
+				return
+			}
+			located[n] = Point{float32(x), float32(y)}
+			count = max(count, n)
+		}
+	}
+
+	points := make([]Point, count)
+	for n := 1; n <= count; n++ {
+		p, ok := located[n]
+		if !ok {
+			err = fmt.Errorf("PixelMap %s light %d missing", name, n)
+			return
+		}
+		points[n-1] = p
+	}
+	return NewPixelMap(name, points...)
+}
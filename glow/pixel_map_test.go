@@ -0,0 +1,158 @@
+package glow
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPixelMap(t *testing.T, pm *PixelMap, columns, rows uint16, lookup []uint16) {
+	if pm.Columns() != columns || pm.Rows() != rows {
+		t.Fatalf("%s grid want %dx%d got %dx%d", pm.Name,
+			columns, rows, pm.Columns(), pm.Rows())
+	}
+	if pm.Count() != uint16(len(lookup)) {
+		t.Fatalf("%s count want %d got %d", pm.Name, len(lookup), pm.Count())
+	}
+	for i, offset := range lookup {
+		if pm.Offset(uint16(i)) != offset {
+			t.Fatalf("%s offset %d want %d got %d", pm.Name,
+				i, offset, pm.Offset(uint16(i)))
+		}
+	}
+}
+
+func TestPixelMapCSV(t *testing.T) {
+	pm, err := DecodePixelMapCSV(strings.NewReader("x,y\n1,1\n3,1\n2,2\n2,0\n"), "diamond")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPixelMap(t, pm, 3, 3, []uint16{3, 5, 7, 1})
+
+	pm, err = DecodePixelMapCSV(strings.NewReader("2,0.5,0\n0,0,0\n1,0.25,0\n"), "indexed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm.Scale = 4
+	err = pm.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPixelMap(t, pm, 3, 1, []uint16{0, 1, 2})
+
+	pm, err = DecodePixelMapCSV(strings.NewReader(",1,\n4,,2\n,3,\n"), "grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPixelMap(t, pm, 3, 3, []uint16{1, 5, 7, 3})
+
+	_, err = DecodePixelMapCSV(strings.NewReader("0,1,1\n0,2,2\n"), "repeat")
+	if err == nil {
+		t.Fatal("repeated index accepted")
+	}
+	_, err = DecodePixelMapCSV(strings.NewReader(",1,\n,3,\n"), "missing")
+	if err == nil {
+		t.Fatal("missing light accepted")
+	}
+}
+
+func TestPixelMapFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	pm, err := LoadPixelMap(write("ring.json",
+		`{"name":"ring","scale":2,"points":[{"x":0,"y":-1},{"x":1,"y":0},{"x":0,"y":1},{"x":-1,"y":0}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPixelMap(t, pm, 5, 5, []uint16{2, 14, 22, 10})
+	if pm.MakeCode() != "{5,5,{2,14,22,10,}}" {
+		t.Fatalf("MakeCode got %s", pm.MakeCode())
+	}
+
+	pm, err = LoadPixelMap(write("letter.xmodel",
+		`<custommodel name="L" CustomModel="1,;2,;3,4|5,;,;,"/>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPixelMap(t, pm, 2, 3, []uint16{0, 2, 4, 5})
+
+	_, err = LoadPixelMap(write("map.txt", "0,0"))
+	if err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
+
+func TestMapLight(t *testing.T) {
+	pm, err := NewPixelMap("corner", Point{0, 0}, Point{2, 0}, Point{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := NewImageLight(pm.Count(), 1)
+	ml := NewMapLight(pm, target)
+	if ml.Length() != 6 || ml.Rows() != 2 {
+		t.Fatalf("MapLight want 6x2 got %dx%d", ml.Length(), ml.Rows())
+	}
+
+	for i := uint16(0); i < ml.Length(); i++ {
+		ml.Set(i, color.NRGBA{uint8(i), 0, 0, 255})
+	}
+	ml.Refresh()
+	for i, want := range []uint8{0, 2, 3} {
+		if target.Get(uint16(i)).R != want {
+			t.Fatalf("light %d want %d got %d", i, want, target.Get(uint16(i)).R)
+		}
+	}
+	if target.Spins() != 1 {
+		t.Fatalf("target not refreshed")
+	}
+
+	full := NewImageLight(ml.Length(), ml.Rows())
+	frame := previewFrame()
+	err = frame.Setup(ml.Length(), ml.Rows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame.Spin(full)
+	frame = previewFrame()
+	frame.Setup(ml.Length(), ml.Rows())
+	frame.Spin(ml)
+	for i := uint16(0); i < pm.Count(); i++ {
+		if target.Get(i) != full.Get(pm.Offset(i)) {
+			t.Fatalf("light %d want %v got %v", i, full.Get(pm.Offset(i)), target.Get(i))
+		}
+	}
+}
+
+func TestPreviewMap(t *testing.T) {
+	pm, err := NewPixelMap("corner", Point{0, 0}, Point{2, 0}, Point{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv := NewPreview()
+	pv.Spins = 1
+	pv.Map = pm
+	images, err := pv.Render(previewFrame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := pv.LedSize + pv.Spacing
+	if images[0].Bounds().Dx() != 3*step+pv.Spacing {
+		t.Fatalf("bounds %v", images[0].Bounds())
+	}
+	if images[0].NRGBAAt(pv.Spacing+step, pv.Spacing) != pv.Background {
+		t.Fatalf("unmapped light drawn")
+	}
+	if images[0].NRGBAAt(pv.Spacing+2*step, pv.Spacing) == pv.Background {
+		t.Fatalf("mapped light missing")
+	}
+}
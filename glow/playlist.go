@@ -0,0 +1,221 @@
+package glow
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+)
+
+// Transition is the way a playlist entry takes over from the one
+// before it.
+type Transition uint16
+
+const (
+	TransitionCut Transition = iota
+	TransitionCrossfade
+	TransitionWipe
+	TransitionDissolve
+	TRANSITION_COUNT
+)
+
+const DefaultDuration = 10000
+
+// Mix returns the light at index of length part way from one effect
+// to the next, level running from 0 to MaximumLevel.
+func (transition Transition) Mix(from, to color.NRGBA, index, length uint16, level uint8) color.NRGBA {
+	switch transition {
+	case TransitionCrossfade:
+		mix := func(a, b uint8) uint8 {
+			return uint8(int(a) + (int(b)-int(a))*int(level)/MaximumLevel)
+		}
+		return color.NRGBA{R: mix(from.R, to.R), G: mix(from.G, to.G),
+			B: mix(from.B, to.B), A: 255}
+	case TransitionWipe:
+		if uint32(index)*MaximumLevel < uint32(length)*uint32(level) {
+			return to
+		}
+		return from
+	case TransitionDissolve:
+		// each light switches at its own level
+		if uint8(uint32(index)*2654435761>>24) < level {
+			return to
+		}
+		return from
+	}
+	return to
+}
+
+// PlaylistEntry plays the effect titled Title in Folder for Duration
+// milliseconds, the first Fade of them taking over from the entry
+// before by its Transition.
+type PlaylistEntry struct {
+	Folder     string     `yaml:"folder" json:"folder"`
+	Title      string     `yaml:"title" json:"title"`
+	Duration   uint32     `yaml:"duration" json:"duration"`
+	Transition Transition `yaml:"transition" json:"transition"`
+	Fade       uint32     `yaml:"fade" json:"fade"`
+}
+
+// Playlist is a named sequence of effects a device cycles through.
+type Playlist struct {
+	Title   string          `yaml:"title" json:"title"`
+	Entries []PlaylistEntry `yaml:"entries" json:"entries"`
+}
+
+func (playlist *Playlist) Validate() error {
+	if len(playlist.Entries) == 0 {
+		return fmt.Errorf("playlist %s has no entries", playlist.Title)
+	}
+	for i := range playlist.Entries {
+		entry := &playlist.Entries[i]
+		if entry.Duration == 0 {
+			entry.Duration = DefaultDuration
+		}
+		if entry.Transition >= TRANSITION_COUNT {
+			entry.Transition = TransitionCut
+		}
+		if entry.Transition == TransitionCut {
+			entry.Fade = 0
+		}
+		entry.Fade = min(entry.Fade, entry.Duration)
+	}
+	return nil
+}
+
+// EffectLookup reads the effect titled title in folder.
+type EffectLookup func(folder, title string) (*Frame, error)
+
+// Sequence plays a playlist. Entries naming the same effect share
+// one frame, as they share the catalog frame on the device.
+type Sequence struct {
+	Playlist *Playlist
+	frames   []*Frame
+	current  int
+	clock    uint32
+	buffer   bufferLight
+}
+
+func NewSequence(playlist *Playlist, lookup EffectLookup) (*Sequence, error) {
+	err := playlist.Validate()
+	if err != nil {
+		return nil, err
+	}
+	seq := &Sequence{Playlist: playlist}
+	read := make(map[string]*Frame)
+	for _, entry := range playlist.Entries {
+		key := entry.Folder + "/" + entry.Title
+		frame, ok := read[key]
+		if !ok {
+			frame, err = lookup(entry.Folder, entry.Title)
+			if err != nil {
+				return nil, fmt.Errorf("playlist %s effect %s: %v", playlist.Title, key, err)
+			}
+			read[key] = frame
+		}
+		seq.frames = append(seq.frames, frame)
+	}
+	return seq, nil
+}
+
+// SetWiring wires every effect as the lights they play on.
+func (seq *Sequence) SetWiring(wiring Wiring) {
+	for _, frame := range seq.frames {
+		frame.Wiring = wiring
+	}
+}
+
+// Setup sets up every effect for the lights and restarts the sequence.
+func (seq *Sequence) Setup(length, rows uint16) (err error) {
+	for _, frame := range seq.frames {
+		err = frame.Setup(length, rows)
+		if err != nil {
+			return
+		}
+		if frame.Interval == 0 {
+			frame.Interval = DefaultInterval
+		}
+	}
+	seq.buffer = make(bufferLight, length)
+	seq.current, seq.clock = 0, 0
+	return
+}
+
+func (seq *Sequence) LoadImages() error {
+	var errs []error
+	for _, frame := range seq.frames {
+		errs = append(errs, frame.LoadImages())
+	}
+	return errors.Join(errs...)
+}
+
+func (seq *Sequence) SetParity(parity bool) {
+	for _, frame := range seq.frames {
+		frame.SetParity(parity)
+	}
+}
+
+// Current returns the index of the entry playing.
+func (seq *Sequence) Current() int {
+	return seq.current
+}
+
+// Tick returns the shortest interval any of the effects needs.
+func (seq *Sequence) Tick() uint32 {
+	tick := uint32(MaximumInterval)
+	for _, frame := range seq.frames {
+		tick = min(tick, frame.Tick())
+	}
+	return tick
+}
+
+func (seq *Sequence) Spin(light Light) {
+	seq.SpinFor(light, seq.frames[seq.current].Interval)
+}
+
+// SpinFor draws the entry playing, mixed with the one before while
+// it takes over, and advances both by elapsed milliseconds.
+func (seq *Sequence) SpinFor(light Light, elapsed uint32) {
+	entry := seq.Playlist.Entries[seq.current]
+	frame := seq.frames[seq.current]
+	previous := seq.frames[(seq.current+len(seq.frames)-1)%len(seq.frames)]
+	if seq.clock < entry.Fade && previous != frame {
+		previous.spinLayers(seq.buffer, elapsed)
+		frame.spinLayers(light, elapsed)
+		level := uint8(seq.clock * MaximumLevel / entry.Fade)
+		length := uint16(len(seq.buffer))
+		for i := uint16(0); i < length; i++ {
+			light.Set(i, entry.Transition.Mix(seq.buffer[i], light.Get(i), i, length, level))
+		}
+	} else {
+		frame.spinLayers(light, elapsed)
+	}
+	light.Refresh()
+
+	seq.clock += elapsed
+	if seq.clock >= entry.Duration {
+		seq.clock = 0
+		seq.current = (seq.current + 1) % len(seq.frames)
+	}
+}
+
+// bufferLight keeps the lights of the entry being replaced.
+type bufferLight []color.NRGBA
+
+func (bl bufferLight) Get(i uint16) color.NRGBA {
+	if int(i) < len(bl) {
+		return bl[i]
+	}
+	return color.NRGBA{}
+}
+
+func (bl bufferLight) Set(i uint16, c color.NRGBA) {
+	if int(i) < len(bl) {
+		bl[i] = c
+	}
+}
+
+func (bl bufferLight) Refresh() {}
+
+func (entry *PlaylistEntry) MakeCode(index string) string {
+	return fmt.Sprintf("{%s,%d,%d,%d}", index, entry.Duration, entry.Transition, entry.Fade)
+}
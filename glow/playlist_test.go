@@ -0,0 +1,136 @@
+package glow
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+func TestPlaylistValidate(t *testing.T) {
+	playlist := Playlist{Title: "empty"}
+	if playlist.Validate() == nil {
+		t.Fatalf("empty playlist validated")
+	}
+
+	playlist.Entries = []PlaylistEntry{
+		{Transition: TransitionCut, Fade: 500},
+		{Duration: 1000, Transition: TRANSITION_COUNT, Fade: 500},
+		{Duration: 1000, Transition: TransitionWipe, Fade: 5000},
+	}
+	if err := playlist.Validate(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	want := []PlaylistEntry{
+		{Duration: DefaultDuration},
+		{Duration: 1000},
+		{Duration: 1000, Transition: TransitionWipe, Fade: 1000},
+	}
+	for i := range want {
+		if playlist.Entries[i] != want[i] {
+			t.Fatalf("entry %d want %v got %v", i, want[i], playlist.Entries[i])
+		}
+	}
+}
+
+func TestTransitionMix(t *testing.T) {
+	from := color.NRGBA{R: 200, G: 0, B: 100, A: 255}
+	to := color.NRGBA{R: 0, G: 200, B: 100, A: 255}
+
+	got := TransitionCrossfade.Mix(from, to, 0, 10, 128)
+	if got != (color.NRGBA{R: 100, G: 100, B: 100, A: 255}) {
+		t.Fatalf("crossfade got %v", got)
+	}
+	if got = TransitionCut.Mix(from, to, 0, 10, 0); got != to {
+		t.Fatalf("cut got %v", got)
+	}
+	for i := uint16(0); i < 10; i++ {
+		got = TransitionWipe.Mix(from, to, i, 10, 127)
+		if (i < 5) != (got == to) {
+			t.Fatalf("wipe light %d got %v", i, got)
+		}
+	}
+
+	for _, transition := range []Transition{TransitionCrossfade, TransitionWipe, TransitionDissolve} {
+		for i := uint16(0); i < 10; i++ {
+			if got = transition.Mix(from, to, i, 10, 0); got != from {
+				t.Fatalf("%d light %d level 0 got %v", transition, i, got)
+			}
+			if got = transition.Mix(from, to, i, 10, MaximumLevel); got != to {
+				t.Fatalf("%d light %d full level got %v", transition, i, got)
+			}
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	colors := map[string]HSV{
+		"red":  {Hue: 0, Saturation: 1, Value: 1},
+		"blue": {Hue: 240, Saturation: 1, Value: 1},
+	}
+	reads := 0
+	lookup := func(folder, title string) (*Frame, error) {
+		hsv, ok := colors[title]
+		if !ok {
+			return nil, fmt.Errorf("missing")
+		}
+		reads++
+		layer := &Layer{}
+		layer.Chroma.AddColors(hsv)
+		frame := &Frame{Interval: 100}
+		frame.AddLayers(layer)
+		return frame, nil
+	}
+
+	playlist := &Playlist{Title: "colors", Entries: []PlaylistEntry{
+		{Title: "red", Duration: 300},
+		{Title: "blue", Duration: 400, Transition: TransitionCrossfade, Fade: 200},
+		{Title: "red", Duration: 200, Transition: TransitionWipe, Fade: 100},
+	}}
+	if _, err := NewSequence(&Playlist{Entries: []PlaylistEntry{{Title: "green"}}}, lookup); err == nil {
+		t.Fatalf("missing effect read")
+	}
+	reads = 0
+	seq, err := NewSequence(playlist, lookup)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if reads != 2 {
+		t.Fatalf("shared effects read %d times", reads)
+	}
+	if err = seq.Setup(4, 1); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	light := make(bufferLight, 4)
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	wantCurrent := []int{0, 0, 0, 1, 1, 1, 1, 2, 2, 0}
+	for spin, current := range wantCurrent {
+		if seq.Current() != current {
+			t.Fatalf("spin %d current want %d got %d", spin, current, seq.Current())
+		}
+		seq.Spin(light)
+		switch spin {
+		case 2:
+			if light[0] != red {
+				t.Fatalf("red got %v", light[0])
+			}
+		case 3:
+			if light[0] != red {
+				t.Fatalf("crossfade start got %v", light[0])
+			}
+		case 4:
+			if light[0].R == 0 || light[0].B == 0 {
+				t.Fatalf("crossfade middle got %v", light[0])
+			}
+		case 5:
+			if light[0] != blue {
+				t.Fatalf("blue got %v", light[0])
+			}
+		case 7:
+			if light[0] != blue || light[3] != blue {
+				t.Fatalf("wipe start got %v", light)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package glow
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+)
+
+const (
+	PowerSpins            = 256
+	DefaultChannelCurrent = 20
+	DefaultIdleCurrent    = 1
+	DefaultPowerBudget    = 10000
+)
+
+// PowerModel is the current in milliamps one LED draws with each
+// channel at full level, plus the current it draws while dark.
+type PowerModel struct {
+	Red   float32 `yaml:"red" json:"red"`
+	Green float32 `yaml:"green" json:"green"`
+	Blue  float32 `yaml:"blue" json:"blue"`
+	Idle  float32 `yaml:"idle" json:"idle"`
+}
+
+// NewPowerModel returns the typical draw of a WS2812B.
+func NewPowerModel() PowerModel {
+	return PowerModel{
+		Red:   DefaultChannelCurrent,
+		Green: DefaultChannelCurrent,
+		Blue:  DefaultChannelCurrent,
+		Idle:  DefaultIdleCurrent,
+	}
+}
+
+// Current returns the milliamps drawn above idle by an LED showing c.
+func (pm *PowerModel) Current(c color.NRGBA) float32 {
+	return (pm.Red*float32(c.R) + pm.Green*float32(c.G) + pm.Blue*float32(c.B)) / MaximumLevel
+}
+
+// PowerReport is the current a frame draws over the spins analyzed.
+// The worst spin is the one drawing the peak current.
+type PowerReport struct {
+	Lights    int     `yaml:"lights" json:"lights"`
+	Spins     int     `yaml:"spins" json:"spins"`
+	Peak      float32 `yaml:"peak" json:"peak"`
+	Average   float32 `yaml:"average" json:"average"`
+	WorstSpin int     `yaml:"worst_spin" json:"worst_spin"`
+}
+
+func (report *PowerReport) Exceeds(budget float32) bool {
+	return budget > 0 && report.Peak > budget
+}
+
+func (report *PowerReport) String() string {
+	return fmt.Sprintf("peak %.0f mA at spin %d, average %.0f mA",
+		report.Peak, report.WorstSpin, report.Average)
+}
+
+// PowerAnalyzer estimates the current a frame draws by spinning
+// it without a display against a model of each LED. With an output
+// the colors are corrected first, as they would be on the lights.
+// Budget is the supply limit in milliamps, zero for none.
+type PowerAnalyzer struct {
+	Columns int        `yaml:"columns" json:"columns"`
+	Rows    int        `yaml:"rows" json:"rows"`
+	Spins   int        `yaml:"spins" json:"spins"`
+	Budget  float32    `yaml:"budget" json:"budget"`
+	Model   PowerModel `yaml:"model" json:"model"`
+	Output  *Output    `yaml:"output,omitempty" json:"output,omitempty"`
+	Map     *PixelMap  `yaml:"-" json:"-"`
+}
+
+func NewPowerAnalyzer() *PowerAnalyzer {
+	pa := &PowerAnalyzer{
+		Columns: PreviewColumns,
+		Rows:    PreviewRows,
+		Spins:   PowerSpins,
+		Budget:  DefaultPowerBudget,
+		Model:   NewPowerModel(),
+	}
+	return pa
+}
+
+func (pa *PowerAnalyzer) Validate() error {
+	if pa.Map != nil {
+		pa.Columns, pa.Rows = int(pa.Map.Columns()), int(pa.Map.Rows())
+	}
+	if pa.Columns < 1 || pa.Rows < 1 {
+		return fmt.Errorf("PowerAnalyzer zero columns or rows")
+	}
+	if pa.Spins < 1 {
+		return fmt.Errorf("PowerAnalyzer zero spins")
+	}
+	if pa.Model == (PowerModel{}) {
+		pa.Model = NewPowerModel()
+	}
+	if pa.Output != nil {
+		pa.Output.Setup()
+	}
+	return nil
+}
+
+// Analyze spins a copy of the source frame and reports its current.
+func (pa *PowerAnalyzer) Analyze(source *Frame) (report PowerReport, err error) {
+	err = pa.Validate()
+	if err != nil {
+		return
+	}
+
+	var frame *Frame
+	frame, err = FrameDeepCopy(source)
+	if err != nil {
+		return
+	}
+
+	light := NewImageLight(uint16(pa.Columns*pa.Rows), uint16(pa.Rows))
+	light.Wiring = frame.Wiring
+	err = frame.Setup(light.Length(), light.Rows())
+	if err != nil {
+		return
+	}
+	// layers whose images fail to load draw their chroma instead
+	if e := frame.LoadImages(); e != nil {
+		log.Println("PowerAnalyzer LoadImages", e)
+	}
+
+	offsets := make([]uint16, 0, light.Length())
+	if pa.Map != nil {
+		for i := uint16(0); i < pa.Map.Count(); i++ {
+			offsets = append(offsets, pa.Map.Offset(i))
+		}
+	} else {
+		for i := uint16(0); i < light.Length(); i++ {
+			offsets = append(offsets, i)
+		}
+	}
+
+	report.Lights, report.Spins = len(offsets), pa.Spins
+	idle := pa.Model.Idle * float32(len(offsets))
+	var total float64
+	for spin := 0; spin < pa.Spins; spin++ {
+		frame.Spin(light)
+		current := idle
+		for _, offset := range offsets {
+			c := light.Get(offset)
+			if pa.Output != nil {
+				c = pa.Output.Correct(c)
+			}
+			current += pa.Model.Current(c)
+		}
+		total += float64(current)
+		if current > report.Peak {
+			report.Peak, report.WorstSpin = current, spin
+		}
+	}
+	report.Average = float32(total / float64(pa.Spins))
+	return
+}
@@ -0,0 +1,70 @@
+package glow
+
+import "image/color"
+
+var _ Light = (*PowerLimiter)(nil)
+
+// PowerLimiter holds the colors set on it until Refresh, then dims
+// them all evenly so the Target lights draw no more than Budget
+// milliamps. Every LED of the strip draws idle current, whether
+// set or not. Get returns colors as they were set.
+type PowerLimiter struct {
+	Model  PowerModel
+	Budget float32
+	Target Light
+	cells  []color.NRGBA
+	level  uint8
+}
+
+func NewPowerLimiter(model PowerModel, budget float32, target Light, length uint16) *PowerLimiter {
+	pl := &PowerLimiter{
+		Model:  model,
+		Budget: budget,
+		Target: target,
+		cells:  make([]color.NRGBA, length),
+		level:  MaximumLevel,
+	}
+	return pl
+}
+
+// Level is the brightness applied at the last Refresh, 255 when not limited.
+func (pl *PowerLimiter) Level() uint8 {
+	return pl.level
+}
+
+func (pl *PowerLimiter) Get(i uint16) color.NRGBA {
+	if int(i) >= len(pl.cells) {
+		return color.NRGBA{}
+	}
+	return pl.cells[i]
+}
+
+func (pl *PowerLimiter) Set(i uint16, c color.NRGBA) {
+	if int(i) >= len(pl.cells) {
+		pl.cells = append(pl.cells, make([]color.NRGBA, int(i)+1-len(pl.cells))...)
+	}
+	pl.cells[i] = c
+}
+
+func (pl *PowerLimiter) Refresh() {
+	var current float32
+	for _, c := range pl.cells {
+		current += pl.Model.Current(c)
+	}
+
+	pl.level = MaximumLevel
+	available := pl.Budget - pl.Model.Idle*float32(len(pl.cells))
+	if pl.Budget > 0 && current > available {
+		pl.level = uint8(max(available, 0) * MaximumLevel / current)
+	}
+
+	for i, c := range pl.cells {
+		if pl.level < MaximumLevel {
+			c.R = scaleColor(c.R, pl.level)
+			c.G = scaleColor(c.G, pl.level)
+			c.B = scaleColor(c.B, pl.level)
+		}
+		pl.Target.Set(uint16(i), c)
+	}
+	pl.Target.Refresh()
+}
@@ -0,0 +1,107 @@
+package glow
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestPowerAnalyze(t *testing.T) {
+	white := &Layer{}
+	white.Chroma.AddColors(HSV{HueRed, 0, 1})
+	frame := &Frame{Interval: 48}
+	frame.AddLayers(white)
+
+	pa := NewPowerAnalyzer()
+	pa.Columns, pa.Rows, pa.Spins = 10, 1, 4
+	report, err := pa.Analyze(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ten lights at 60 mA plus 1 mA idle
+	if report.Lights != 10 || report.Peak != 610 || report.Average != 610 {
+		t.Fatalf("white %+v", report)
+	}
+	if report.Exceeds(610) || !report.Exceeds(600) {
+		t.Fatalf("exceeds budget wrong for %v", report.Peak)
+	}
+
+	pa.Output = &Output{Brightness: 50}
+	report, err = pa.Analyze(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Peak >= 610/2+10 || report.Peak < 610/2-10 {
+		t.Fatalf("half brightness output peak %v", report.Peak)
+	}
+
+	// an image that fails to load leaves the chroma
+	white.ImageName = filepath.Join(t.TempDir(), "missing.png")
+	pa.Output = nil
+	report, err = pa.Analyze(frame)
+	if err != nil || report.Peak != 610 {
+		t.Fatalf("missing image %v %+v", err, report)
+	}
+}
+
+func TestPowerWorstSpin(t *testing.T) {
+	layer := &Layer{}
+	layer.Chroma.AddColors(HSV{HueRed, 1, 1})
+	layer.Envelope = Envelope{Attack: 4, Sustain: 4}
+	frame := &Frame{Interval: 48}
+	frame.AddLayers(layer)
+
+	pa := &PowerAnalyzer{Columns: 8, Rows: 1, Spins: 8,
+		Model: PowerModel{Red: 20}}
+	report, err := pa.Analyze(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.WorstSpin != 4 || report.Peak != 160 || report.Average >= report.Peak {
+		t.Fatalf("attack %+v", report)
+	}
+}
+
+func TestPowerLimiter(t *testing.T) {
+	target := newTestLight(10)
+	pl := NewPowerLimiter(NewPowerModel(), 310, target, 10)
+	for i := uint16(0); i < 10; i++ {
+		pl.Set(i, color.NRGBA{255, 255, 255, 255})
+	}
+	pl.Refresh()
+
+	var current float32
+	for i := uint16(0); i < 10; i++ {
+		if pl.Get(i) != (color.NRGBA{255, 255, 255, 255}) {
+			t.Fatalf("light %d not as set %v", i, pl.Get(i))
+		}
+		current += pl.Model.Current(target.Get(i)) + pl.Model.Idle
+	}
+	if current > 310 || current < 290 {
+		t.Fatalf("limited current %v level %d", current, pl.Level())
+	}
+
+	pl.Budget = 1000
+	pl.Refresh()
+	if pl.Level() != MaximumLevel || target.Get(0) != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("limited under budget level %d", pl.Level())
+	}
+}
+
+func TestPowerLimiterIdle(t *testing.T) {
+	target := newTestLight(10)
+	pl := NewPowerLimiter(NewPowerModel(), 160, target, 10)
+	for i := uint16(0); i < 5; i++ {
+		pl.Set(i, color.NRGBA{255, 255, 255, 255})
+	}
+	pl.Refresh()
+
+	// the five dark lights still draw idle current
+	current := pl.Model.Idle * 10
+	for i := uint16(0); i < 10; i++ {
+		current += pl.Model.Current(target.Get(i))
+	}
+	if current > 160 {
+		t.Fatalf("limited current %v level %d", current, pl.Level())
+	}
+}
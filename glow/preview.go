@@ -0,0 +1,258 @@
+package glow
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"log"
+)
+
+const (
+	PreviewColumns      = 16
+	PreviewRows         = 4
+	PreviewSpins        = 64
+	PreviewLedSize      = 12
+	PreviewSpacing      = 2
+	PreviewSheetColumns = 8
+)
+
+// Preview renders a frame without a display by spinning it against an
+// ImageLight and drawing each light as a square LED.
+type Preview struct {
+	Columns      int
+	Rows         int
+	Spins        int
+	LedSize      int
+	Spacing      int
+	SheetColumns int
+	Background   color.NRGBA
+	Off          color.NRGBA
+	Wiring       Wiring
+	Map          *PixelMap
+	occupied     []bool
+}
+
+func NewPreview() *Preview {
+	pv := &Preview{
+		Columns:      PreviewColumns,
+		Rows:         PreviewRows,
+		Spins:        PreviewSpins,
+		LedSize:      PreviewLedSize,
+		Spacing:      PreviewSpacing,
+		SheetColumns: PreviewSheetColumns,
+		Background:   color.NRGBA{0, 0, 0, 255},
+		Off:          color.NRGBA{48, 24, 16, 255},
+	}
+	return pv
+}
+
+// PreviewSettings are the preview options an accessor may give.
+// Settings left out keep the preview's defaults.
+type PreviewSettings struct {
+	Columns      int          `yaml:"columns,omitempty" json:"columns,omitempty"`
+	Rows         int          `yaml:"rows,omitempty" json:"rows,omitempty"`
+	Spins        int          `yaml:"spins,omitempty" json:"spins,omitempty"`
+	LedSize      int          `yaml:"led_size,omitempty" json:"led_size,omitempty"`
+	Spacing      *int         `yaml:"spacing,omitempty" json:"spacing,omitempty"`
+	SheetColumns int          `yaml:"sheet_columns,omitempty" json:"sheet_columns,omitempty"`
+	Background   *color.NRGBA `yaml:"background,omitempty" json:"background,omitempty"`
+	Off          *color.NRGBA `yaml:"off,omitempty" json:"off,omitempty"`
+	Wiring       Wiring       `yaml:"wiring,omitempty" json:"wiring,omitempty"`
+}
+
+// Apply sets the preview options given in settings.
+func (pv *Preview) Apply(settings *PreviewSettings) {
+	if settings == nil {
+		return
+	}
+	set := func(value *int, setting int) {
+		if setting != 0 {
+			*value = setting
+		}
+	}
+	set(&pv.Columns, settings.Columns)
+	set(&pv.Rows, settings.Rows)
+	set(&pv.Spins, settings.Spins)
+	set(&pv.LedSize, settings.LedSize)
+	set(&pv.SheetColumns, settings.SheetColumns)
+	if settings.Spacing != nil {
+		pv.Spacing = *settings.Spacing
+	}
+	if settings.Background != nil {
+		pv.Background = *settings.Background
+	}
+	if settings.Off != nil {
+		pv.Off = *settings.Off
+	}
+	if settings.Wiring != Progressive {
+		pv.Wiring = settings.Wiring
+	}
+}
+
+func (pv *Preview) Validate() error {
+	pv.occupied = nil
+	if pv.Map != nil {
+		pv.Columns, pv.Rows = int(pv.Map.Columns()), int(pv.Map.Rows())
+		pv.occupied = pv.Map.Occupied()
+	}
+	if pv.Columns < 1 || pv.Rows < 1 {
+		return fmt.Errorf("Preview zero columns or rows")
+	}
+	if pv.Spins < 1 {
+		return fmt.Errorf("Preview zero spins")
+	}
+	if pv.LedSize < 1 {
+		pv.LedSize = 1
+	}
+	if pv.Spacing < 0 {
+		pv.Spacing = 0
+	}
+	if pv.SheetColumns < 1 {
+		pv.SheetColumns = 1
+	}
+	return nil
+}
+
+// Render spins a copy of the source frame and returns one image per spin.
+func (pv *Preview) Render(source *Frame) (images []*image.NRGBA, err error) {
+	err = pv.Validate()
+	if err != nil {
+		return
+	}
+
+	var frame *Frame
+	frame, err = FrameDeepCopy(source)
+	if err != nil {
+		return
+	}
+
+	light := NewImageLight(uint16(pv.Columns*pv.Rows), uint16(pv.Rows))
+	frame.Wiring = pv.Wiring
+	light.Wiring = pv.Wiring
+	err = frame.Setup(light.Length(), light.Rows())
+	if err != nil {
+		return
+	}
+	// layers whose images fail to load draw their chroma instead
+	if e := frame.LoadImages(); e != nil {
+		log.Println("Preview LoadImages", e)
+	}
+	light.Fill(pv.Off)
+
+	images = make([]*image.NRGBA, 0, pv.Spins)
+	for i := 0; i < pv.Spins; i++ {
+		frame.Spin(light)
+		images = append(images, pv.Draw(light.Lights))
+	}
+	return
+}
+
+func (pv *Preview) Bounds() image.Rectangle {
+	step := pv.LedSize + pv.Spacing
+	return image.Rect(0, 0,
+		pv.Columns*step+pv.Spacing, pv.Rows*step+pv.Spacing)
+}
+
+// Draw scales lights into an image of LEDs on the background.
+// With a pixel map only the mapped cells are drawn.
+func (pv *Preview) Draw(lights *image.NRGBA) *image.NRGBA {
+	dst := image.NewNRGBA(pv.Bounds())
+	draw.Draw(dst, dst.Rect, image.NewUniform(pv.Background), image.Point{}, draw.Src)
+
+	step := pv.LedSize + pv.Spacing
+	b := lights.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if pv.occupied != nil && !pv.occupied[y*b.Dx()+x] {
+				continue
+			}
+			led := image.Rect(0, 0, pv.LedSize, pv.LedSize).
+				Add(image.Pt(pv.Spacing+x*step, pv.Spacing+y*step))
+			draw.Draw(dst, led, image.NewUniform(lights.NRGBAAt(x, y)),
+				image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
+
+func (pv *Preview) interval(frame *Frame) uint32 {
+	if frame.Interval < MinimumInterval {
+		return DefaultInterval
+	}
+	return frame.Interval
+}
+
+func (pv *Preview) WriteGIF(w io.Writer, frame *Frame) error {
+	images, err := pv.Render(frame)
+	if err != nil {
+		return err
+	}
+
+	delay := int(pv.interval(frame) / 10)
+	anim := &gif.GIF{}
+	for _, img := range images {
+		anim.Image = append(anim.Image, toPaletted(img))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+func (pv *Preview) WriteAPNG(w io.Writer, frame *Frame) error {
+	images, err := pv.Render(frame)
+	if err != nil {
+		return err
+	}
+	return EncodeAPNG(w, images, pv.interval(frame))
+}
+
+// WriteSprites writes every spin into a single png sheet,
+// SheetColumns spins across.
+func (pv *Preview) WriteSprites(w io.Writer, frame *Frame) error {
+	images, err := pv.Render(frame)
+	if err != nil {
+		return err
+	}
+
+	cell := pv.Bounds()
+	columns := min(pv.SheetColumns, len(images))
+	rows := (len(images) + columns - 1) / columns
+	sheet := image.NewNRGBA(image.Rect(0, 0,
+		columns*cell.Dx(), rows*cell.Dy()))
+	for i, img := range images {
+		at := image.Pt((i%columns)*cell.Dx(), (i/columns)*cell.Dy())
+		draw.Draw(sheet, cell.Add(at), img, image.Point{}, draw.Src)
+	}
+	return png.Encode(w, sheet)
+}
+
+func toPaletted(img *image.NRGBA) *image.Paletted {
+	colors := make(color.Palette, 0, 256)
+	seen := make(map[color.NRGBA]bool)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y && colors != nil; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if seen[c] {
+				continue
+			}
+			if len(colors) == 256 {
+				colors = nil
+				break
+			}
+			seen[c] = true
+			colors = append(colors, c)
+		}
+	}
+
+	if colors == nil {
+		colors = palette.Plan9
+	}
+	dst := image.NewPaletted(b, colors)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,148 @@
+package glow
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+func previewFrame() *Frame {
+	var chroma Chroma
+	chroma.AddColors(HSV{HueRed, 1, 1}, HSV{HueBlue, 1, 1})
+
+	layer := &Layer{Chroma: chroma, HueShift: 1}
+	scan := &Layer{Scan: 2}
+	scan.Chroma.AddColors(HSV{HueGreen, 1, 1})
+
+	frame := &Frame{Interval: 48}
+	frame.AddLayers(layer, scan)
+	return frame
+}
+
+func TestImageLight(t *testing.T) {
+	light := NewImageLight(36, 4)
+	if light.Length() != 36 {
+		t.Fatalf("Length want 36 got %d", light.Length())
+	}
+	if light.Rows() != 4 {
+		t.Fatalf("Rows want 4 got %d", light.Rows())
+	}
+
+	for i := uint16(0); i < light.Length(); i++ {
+		c := HSV{float32(i) * 10, 1, 1}
+		light.Set(i, c.ToRGB())
+	}
+	for i := uint16(0); i < light.Length(); i++ {
+		c := HSV{float32(i) * 10, 1, 1}
+		if light.Get(i) != c.ToRGB() {
+			t.Fatalf("Get(%d) want %v got %v", i, c.ToRGB(), light.Get(i))
+		}
+	}
+}
+
+func TestPreviewRender(t *testing.T) {
+	pv := NewPreview()
+	pv.Spins = 5
+
+	frame := previewFrame()
+	images, err := pv.Render(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != pv.Spins {
+		t.Fatalf("images want %d got %d", pv.Spins, len(images))
+	}
+
+	b := pv.Bounds()
+	if images[0].Bounds() != b {
+		t.Fatalf("bounds want %v got %v", b, images[0].Bounds())
+	}
+
+	if images[0].NRGBAAt(0, 0) != pv.Background {
+		t.Fatalf("spacing want %v got %v", pv.Background, images[0].NRGBAAt(0, 0))
+	}
+
+	if frame.Layers[0].Chroma.Colors[0].Hue != HueRed {
+		t.Fatalf("source frame was spun")
+	}
+
+	// an image that fails to load leaves the chroma
+	frame.Layers[0].ImageName = filepath.Join(t.TempDir(), "missing.png")
+	missing, err := pv.Render(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(missing[0].Pix, images[0].Pix) {
+		t.Fatalf("missing image changed the render")
+	}
+}
+
+func TestPreviewWrite(t *testing.T) {
+	pv := NewPreview()
+	pv.Spins = 4
+	frame := previewFrame()
+
+	var buf bytes.Buffer
+	err := pv.WriteGIF(&buf, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != pv.Spins {
+		t.Fatalf("gif frames want %d got %d", pv.Spins, len(anim.Image))
+	}
+
+	buf.Reset()
+	err = pv.WriteAPNG(&buf, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("acTL")) {
+		t.Fatal("apng missing acTL")
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("fcTL")); n != pv.Spins {
+		t.Fatalf("apng fcTL want %d got %d", pv.Spins, n)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != pv.Bounds() {
+		t.Fatalf("apng bounds want %v got %v", pv.Bounds(), img.Bounds())
+	}
+
+	buf.Reset()
+	pv.SheetColumns = 2
+	err = pv.WriteSprites(&buf, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := pv.Bounds()
+	if sheet.Bounds().Dx() != cell.Dx()*2 || sheet.Bounds().Dy() != cell.Dy()*2 {
+		t.Fatalf("sheet size %v", sheet.Bounds())
+	}
+}
+
+func TestPreviewApply(t *testing.T) {
+	pv := NewPreview()
+	pv.Apply(nil)
+	spacing := 0
+	background := color.NRGBA{R: 10, A: 255}
+	pv.Apply(&PreviewSettings{Columns: 30, LedSize: 6, Spacing: &spacing,
+		Background: &background, Wiring: Serpentine})
+	if pv.Columns != 30 || pv.Rows != PreviewRows || pv.LedSize != 6 ||
+		pv.Spacing != 0 || pv.Background != background || pv.Spins != PreviewSpins ||
+		pv.Wiring != Serpentine {
+		t.Fatalf("apply got %+v", pv)
+	}
+}
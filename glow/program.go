@@ -0,0 +1,492 @@
+package glow
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A program gives each light of a layer its color from a few
+// assignments such as "hue = (x*10 + t*3) % 360; val = sin(y+t)".
+// Statements are separated by semicolons or new lines. Programs read
+// the light's column x, row y, index along the layer, the layer's time
+// t in seconds and its spin count, and set hue in degrees and sat and
+// val from 0 to 1, which start as 0, 1 and 1. Other names assigned
+// are locals. There are no loops, division or modulo by zero gives 0
+// and every result is clamped into range, so a program always ends
+// and always gives a color.
+var (
+	ProgramInputs  = []string{"x", "y", "index", "t", "spin"}
+	ProgramOutputs = []string{"hue", "sat", "val"}
+)
+
+const MaxProgramLength = 1024
+
+// programFunctions holds the number of arguments of each function.
+var programFunctions = map[string]int{
+	"sin": 1, "cos": 1, "tan": 1, "abs": 1, "floor": 1, "ceil": 1,
+	"fract": 1, "sqrt": 1, "pow": 2, "min": 2, "max": 2,
+	"clamp": 3, "mix": 3, "if": 3,
+}
+
+type NodeKind uint16
+
+const (
+	NodeNumber NodeKind = iota
+	NodeVariable
+	NodeUnary
+	NodeBinary
+	NodeCall
+)
+
+// Node is one term of a program. Name is the variable, operator or
+// function, and Value the number.
+type Node struct {
+	Kind  NodeKind
+	Name  string
+	Value float64
+	Args  []*Node
+	slot  int
+}
+
+type Statement struct {
+	Target string
+	Value  *Node
+	slot   int
+}
+
+type ProgramInput struct {
+	X, Y, Index, T, Spin float64
+}
+
+type Program struct {
+	Source     string
+	Statements []Statement
+	Locals     []string
+	vars       []float64
+}
+
+// CompileProgram parses source, reporting the line and column of the
+// first error.
+func CompileProgram(source string) (*Program, error) {
+	if len(source) > MaxProgramLength {
+		return nil, fmt.Errorf("program longer than %d characters", MaxProgramLength)
+	}
+	parser := &programParser{source: source, slots: make(map[string]int)}
+	for i, name := range ProgramInputs {
+		parser.slots[name] = i
+	}
+	for i, name := range ProgramOutputs {
+		parser.slots[name] = len(ProgramInputs) + i
+	}
+	program, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	program.Source = source
+	program.vars = make([]float64, len(parser.slots))
+	return program, nil
+}
+
+// Name is the name of the program's function in generated code.
+func (program *Program) Name() string {
+	hash := fnv.New32a()
+	hash.Write([]byte(program.Source))
+	return fmt.Sprintf("expression_%08x", hash.Sum32())
+}
+
+// Eval runs the program for one light, returning hue within [0,360)
+// and saturation and value within [0,1].
+func (program *Program) Eval(in ProgramInput) (hue, saturation, value float64) {
+	vars := program.vars
+	clear(vars)
+	vars[0], vars[1], vars[2], vars[3], vars[4] = in.X, in.Y, in.Index, in.T, in.Spin
+	outputs := len(ProgramInputs)
+	vars[outputs+1], vars[outputs+2] = 1, 1
+	for i := range program.Statements {
+		statement := &program.Statements[i]
+		vars[statement.slot] = program.eval(statement.Value)
+	}
+	return programOutput(vars[outputs], vars[outputs+1], vars[outputs+2])
+}
+
+// programOutput brings the results of a program into range. Numbers
+// that are not finite give 0.
+func programOutput(hue, saturation, value float64) (float64, float64, float64) {
+	unit := func(v float64) float64 {
+		if math.IsNaN(v) {
+			return 0
+		}
+		return math.Min(math.Max(v, 0), 1)
+	}
+	if math.IsNaN(hue) || math.IsInf(hue, 0) {
+		hue = 0
+	}
+	hue = math.Mod(hue, 360)
+	if hue < 0 {
+		hue += 360
+	}
+	if hue >= 360 {
+		hue = 0
+	}
+	return hue, unit(saturation), unit(value)
+}
+
+// ProgramColor converts a program's results as the firmware does.
+func ProgramColor(hue, saturation, value float64) HSVColor {
+	return HSVColor{
+		Hue:        uint16(hue * float64(hue_limit) / 360),
+		Saturation: uint8(saturation * 255),
+		Value:      uint8(value * 255),
+	}
+}
+
+func (program *Program) eval(node *Node) float64 {
+	switch node.Kind {
+	case NodeNumber:
+		return node.Value
+	case NodeVariable:
+		return program.vars[node.slot]
+	case NodeUnary:
+		return -program.eval(node.Args[0])
+	case NodeBinary:
+		return programBinary(node.Name, program.eval(node.Args[0]), program.eval(node.Args[1]))
+	}
+	var args [3]float64
+	for i, arg := range node.Args {
+		args[i] = program.eval(arg)
+	}
+	return programCall(node.Name, args)
+}
+
+func programBinary(op string, a, b float64) float64 {
+	truth := func(ok bool) float64 {
+		if ok {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	case "%":
+		if b == 0 {
+			return 0
+		}
+		return math.Mod(a, b)
+	case "<":
+		return truth(a < b)
+	case ">":
+		return truth(a > b)
+	case "<=":
+		return truth(a <= b)
+	case ">=":
+		return truth(a >= b)
+	case "==":
+		return truth(a == b)
+	}
+	return truth(a != b)
+}
+
+// programCall keeps to arithmetic the firmware repeats exactly, so
+// min and max compare rather than follow math.Min and math.Max.
+func programCall(name string, args [3]float64) float64 {
+	least := func(a, b float64) float64 {
+		if a < b {
+			return a
+		}
+		return b
+	}
+	most := func(a, b float64) float64 {
+		if a > b {
+			return a
+		}
+		return b
+	}
+	a := args[0]
+	switch name {
+	case "sin":
+		return math.Sin(a)
+	case "cos":
+		return math.Cos(a)
+	case "tan":
+		return math.Tan(a)
+	case "abs":
+		return math.Abs(a)
+	case "floor":
+		return math.Floor(a)
+	case "ceil":
+		return math.Ceil(a)
+	case "fract":
+		return a - math.Floor(a)
+	case "sqrt":
+		return math.Sqrt(a)
+	case "pow":
+		return math.Pow(a, args[1])
+	case "min":
+		return least(a, args[1])
+	case "max":
+		return most(a, args[1])
+	case "clamp":
+		return least(most(a, args[1]), args[2])
+	case "mix":
+		return a + float64((args[1]-a)*args[2])
+	case "if":
+		if a != 0 {
+			return args[1]
+		}
+		return args[2]
+	}
+	return 0
+}
+
+type programParser struct {
+	source string
+	at     int
+	token  string
+	start  int
+	slots  map[string]int
+	locals []string
+}
+
+func (parser *programParser) fail(at int, format string, a ...any) error {
+	line := 1 + strings.Count(parser.source[:at], "\n")
+	column := at - strings.LastIndex(parser.source[:at], "\n")
+	return fmt.Errorf("%d:%d: %s", line, column, fmt.Sprintf(format, a...))
+}
+
+// next reads the following token, a new line being a separator.
+func (parser *programParser) next() error {
+	source := parser.source
+	for parser.at < len(source) && strings.ContainsRune(" \t\r", rune(source[parser.at])) {
+		parser.at++
+	}
+	parser.start = parser.at
+	if parser.at >= len(source) {
+		parser.token = ""
+		return nil
+	}
+	c := source[parser.at]
+	switch {
+	case isProgramDigit(c) || c == '.':
+		for parser.at < len(source) && (isProgramDigit(source[parser.at]) || source[parser.at] == '.') {
+			parser.at++
+		}
+	case isProgramLetter(c):
+		for parser.at < len(source) && (isProgramLetter(source[parser.at]) || isProgramDigit(source[parser.at])) {
+			parser.at++
+		}
+	case strings.Contains("<>=!", string(c)):
+		parser.at++
+		if parser.at < len(source) && source[parser.at] == '=' {
+			parser.at++
+		}
+		if c == '!' && parser.at-parser.start == 1 {
+			return parser.fail(parser.start, "unexpected !")
+		}
+	case strings.ContainsRune("+-*/%(),;\n", rune(c)):
+		parser.at++
+	default:
+		return parser.fail(parser.start, "unexpected %q", c)
+	}
+	parser.token = source[parser.start:parser.at]
+	return nil
+}
+
+func isProgramDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isProgramLetter(c byte) bool { return c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z') }
+
+func (parser *programParser) expect(token string) error {
+	if parser.token != token {
+		return parser.fail(parser.start, "expected %s", token)
+	}
+	return parser.next()
+}
+
+func (parser *programParser) parse() (*Program, error) {
+	program := &Program{}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	for parser.token != "" {
+		if parser.token == ";" || parser.token == "\n" {
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		statement, err := parser.statement()
+		if err != nil {
+			return nil, err
+		}
+		program.Statements = append(program.Statements, statement)
+		if parser.token != "" && parser.token != ";" && parser.token != "\n" {
+			return nil, parser.fail(parser.start, "expected ; or new line")
+		}
+	}
+	if len(program.Statements) == 0 {
+		return nil, parser.fail(0, "no statements")
+	}
+	program.Locals = parser.locals
+	return program, nil
+}
+
+func (parser *programParser) statement() (statement Statement, err error) {
+	at := parser.start
+	target := parser.token
+	if !isProgramLetter(target[0]) {
+		return statement, parser.fail(at, "expected a name to assign")
+	}
+	if _, ok := programFunctions[target]; ok || target == "pi" {
+		return statement, parser.fail(at, "cannot assign to %s", target)
+	}
+	for _, name := range ProgramInputs {
+		if target == name {
+			return statement, parser.fail(at, "cannot assign to %s", target)
+		}
+	}
+	if err = parser.next(); err != nil {
+		return
+	}
+	if err = parser.expect("="); err != nil {
+		return
+	}
+	value, err := parser.comparison()
+	if err != nil {
+		return
+	}
+	slot, ok := parser.slots[target]
+	if !ok {
+		slot = len(parser.slots)
+		parser.slots[target] = slot
+		parser.locals = append(parser.locals, target)
+	}
+	return Statement{Target: target, Value: value, slot: slot}, nil
+}
+
+func (parser *programParser) binary(operand func() (*Node, error), ops ...string) (*Node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		found := false
+		for _, op := range ops {
+			found = found || parser.token == op
+		}
+		if !found {
+			return left, nil
+		}
+		op := parser.token
+		if err = parser.next(); err != nil {
+			return nil, err
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Node{Kind: NodeBinary, Name: op, Args: []*Node{left, right}}
+	}
+}
+
+func (parser *programParser) comparison() (*Node, error) {
+	return parser.binary(parser.additive, "<", ">", "<=", ">=", "==", "!=")
+}
+
+func (parser *programParser) additive() (*Node, error) {
+	return parser.binary(parser.term, "+", "-")
+}
+
+func (parser *programParser) term() (*Node, error) {
+	return parser.binary(parser.unary, "*", "/", "%")
+}
+
+func (parser *programParser) unary() (*Node, error) {
+	if parser.token == "-" || parser.token == "+" {
+		negate := parser.token == "-"
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+		node, err := parser.unary()
+		if err != nil || !negate {
+			return node, err
+		}
+		return &Node{Kind: NodeUnary, Name: "-", Args: []*Node{node}}, nil
+	}
+	return parser.primary()
+}
+
+func (parser *programParser) primary() (*Node, error) {
+	at, token := parser.start, parser.token
+	switch {
+	case token == "":
+		return nil, parser.fail(at, "unexpected end")
+	case token == "(":
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+		node, err := parser.comparison()
+		if err != nil {
+			return nil, err
+		}
+		return node, parser.expect(")")
+	case isProgramDigit(token[0]) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, parser.fail(at, "bad number %s", token)
+		}
+		return &Node{Kind: NodeNumber, Value: value}, parser.next()
+	case !isProgramLetter(token[0]):
+		return nil, parser.fail(at, "unexpected %s", strings.ReplaceAll(token, "\n", "new line"))
+	}
+
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	if parser.token != "(" {
+		if token == "pi" {
+			return &Node{Kind: NodeNumber, Value: math.Pi}, nil
+		}
+		slot, ok := parser.slots[token]
+		if !ok {
+			return nil, parser.fail(at, "undefined variable %s", token)
+		}
+		return &Node{Kind: NodeVariable, Name: token, slot: slot}, nil
+	}
+
+	count, ok := programFunctions[token]
+	if !ok {
+		return nil, parser.fail(at, "unknown function %s", token)
+	}
+	node := &Node{Kind: NodeCall, Name: token}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+	for parser.token != ")" {
+		if len(node.Args) > 0 {
+			if err := parser.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := parser.comparison()
+		if err != nil {
+			return nil, err
+		}
+		node.Args = append(node.Args, arg)
+	}
+	if len(node.Args) != count {
+		return nil, parser.fail(at, "%s takes %d arguments", token, count)
+	}
+	return node, parser.next()
+}
@@ -0,0 +1,119 @@
+package glow
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestProgramEval(t *testing.T) {
+	tests := []struct {
+		source   string
+		in       ProgramInput
+		hue      float64
+		sat, val float64
+	}{
+		{"hue = 90", ProgramInput{}, 90, 1, 1},
+		{"hue = x*10 + y; sat = 0.5", ProgramInput{X: 3, Y: 2}, 32, .5, 1},
+		{"a = index + 1\nhue = a * a", ProgramInput{Index: 4}, 25, 1, 1},
+		{"hue = -30 - t*60", ProgramInput{T: 1}, 270, 1, 1},
+		{"hue = 725; val = 2; sat = -1", ProgramInput{}, 5, 0, 1},
+		{"hue = 5 / 0 + 7 % 0; val = 2 + -3 * 2 < 0", ProgramInput{}, 0, 1, 1},
+		{"hue = 7 % 4 + (spin == 2) * 10", ProgramInput{Spin: 2}, 13, 1, 1},
+		{"hue = if(x > 1, 100, 200) + min(3, 4) + max(3, 4)", ProgramInput{X: 2}, 107, 1, 1},
+		{"val = sqrt(-1)", ProgramInput{}, 0, 1, 0},
+		{"hue = mix(10, 20, 0.5) + clamp(9, 0, 5); val = fract(2.25)", ProgramInput{}, 20, 1, .25},
+		{"hue = floor(pi) + ceil(0.1) + abs(-2) + pow(2, 3)", ProgramInput{}, 14, 1, 1},
+	}
+	for _, test := range tests {
+		program, err := CompileProgram(test.source)
+		if err != nil {
+			t.Fatalf("%q %v", test.source, err)
+		}
+		hue, sat, val := program.Eval(test.in)
+		if math.Abs(hue-test.hue) > 1e-9 || sat != test.sat || val != test.val {
+			t.Fatalf("%q want %v %v %v got %v %v %v",
+				test.source, test.hue, test.sat, test.val, hue, sat, val)
+		}
+		// evaluation keeps nothing from the last light
+		if again, _, _ := program.Eval(test.in); again != hue {
+			t.Fatalf("%q again got %v", test.source, again)
+		}
+	}
+}
+
+func TestProgramErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"", "1:1: no statements"},
+		{"hue = q", "1:7: undefined variable q"},
+		{"x = 1", "1:1: cannot assign to x"},
+		{"sin = 1", "1:1: cannot assign to sin"},
+		{"hue = 1\nval = foo(1)", "2:7: unknown function foo"},
+		{"hue = min(1)", "1:7: min takes 2 arguments"},
+		{"hue = (1 + 2", "1:13: expected )"},
+		{"hue 1", "1:5: expected ="},
+		{"hue = 1 2", "1:9: expected ; or new line"},
+		{"hue = 1 $ 2", "1:9: unexpected '$'"},
+		{"hue = 1..2", "1:7: bad number 1..2"},
+		{"hue = a; a = 1", "1:7: undefined variable a"},
+	}
+	for _, test := range tests {
+		_, err := CompileProgram(test.source)
+		if err == nil || err.Error() != test.want {
+			t.Fatalf("%q want %q got %v", test.source, test.want, err)
+		}
+	}
+
+	long := make([]byte, MaxProgramLength+1)
+	for i := range long {
+		long[i] = ' '
+	}
+	if _, err := CompileProgram("hue = 1" + string(long)); err == nil {
+		t.Fatalf("long program compiled")
+	}
+}
+
+func TestLayerExpression(t *testing.T) {
+	layer := &Layer{Grid: Grid{Orientation: Vertical}, Expression: "hue = index * 120"}
+	layer.Chroma.AddColors(HSV{Hue: 0, Saturation: 1, Value: 1})
+	if err := layer.SetupLength(3, 1); err != nil {
+		t.Fatalf("%v", err)
+	}
+	light := make(bufferLight, 3)
+	layer.spin(light, 100, 0, MaximumPercent, MaximumPercent)
+	want := []color.NRGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	for i := range want {
+		if light[i] != want[i] {
+			t.Fatalf("light %d want %v got %v", i, want[i], light[i])
+		}
+	}
+
+	layer.Expression = "hue = nope"
+	if err := layer.Validate(); err != nil {
+		t.Fatalf("bad expression %v", err)
+	}
+	layer.spin(light, 100, 0, MaximumPercent, MaximumPercent)
+	if light[2] != want[0] {
+		t.Fatalf("bad expression want chroma got %v", light[2])
+	}
+}
+
+func TestLayerMakeCodeTrailing(t *testing.T) {
+	layer := &Layer{}
+	if code := layer.MakeCode(); !strings.HasSuffix(code, ",{},0,false,nullptr},") {
+		t.Fatalf("defaults %s", code)
+	}
+	layer.Mask, layer.Clip, layer.Expression = MaskAlpha, true, "hue = nope"
+	if code := layer.MakeCode(); !strings.HasSuffix(code, ",{},2,true,nullptr},") {
+		t.Fatalf("bad expression %s", code)
+	}
+	layer.Expression = "hue = 90"
+	program, _ := CompileProgram(layer.Expression)
+	if code := layer.MakeCode(); !strings.HasSuffix(code, ",{},2,true,"+program.Name()+"},") {
+		t.Fatalf("expression %s", code)
+	}
+}
@@ -0,0 +1,87 @@
+package glow
+
+import "math"
+
+type ScanMode uint16
+
+const (
+	ScanForward ScanMode = iota
+	ScanReverse
+	ScanPingPong
+	ScanRandom
+	ScanEase
+	ScanSine
+	ScanBounce
+	SCAN_MODE_COUNT
+)
+
+const scanSeed uint32 = 2463534242
+
+// travel returns the position within 0..travel for a phase
+// in 0..2*travel, out and back.
+func (mode ScanMode) travel(phase, travel uint32) uint32 {
+	out := phase
+	if out > travel {
+		out = 2*travel - phase
+	}
+
+	switch mode {
+	case ScanEase:
+		u, t := uint64(out), uint64(travel)
+		return uint32(u * u * (3*t - 2*u) / (t * t))
+	case ScanSine:
+		ratio := float64(phase) / float64(travel)
+		return uint32(float64(travel)*(1-math.Cos(math.Pi*ratio))/2 + 0.5)
+	case ScanBounce:
+		u, t := uint64(phase), uint64(travel)
+		return uint32(u * (2*t - u) / t)
+	}
+	return out
+}
+
+// xorshift keeps random jumps reproducible on the controller.
+func (layer *Layer) random() uint32 {
+	if layer.seed == 0 {
+		layer.seed = scanSeed
+	}
+	layer.seed ^= layer.seed << 13
+	layer.seed ^= layer.seed >> 17
+	layer.seed ^= layer.seed << 5
+	return layer.seed
+}
+
+func (layer *Layer) updateScanPosition() {
+	span := layer.last - layer.first
+	if span == 0 {
+		return
+	}
+
+	speed := uint32(max(layer.Speed, 1))
+	switch layer.ScanMode {
+	case ScanReverse:
+		p := int(layer.position) - int(speed)
+		for p < int(layer.first) {
+			p += int(span)
+		}
+		layer.position = uint16(p)
+
+	case ScanRandom:
+		layer.position = layer.first + uint16(layer.random()%uint32(span))
+
+	case ScanPingPong, ScanEase, ScanSine, ScanBounce:
+		travel := uint32(span - min(layer.scanLength(), span))
+		if travel == 0 {
+			layer.position = layer.first
+			return
+		}
+		layer.phase = (layer.phase + speed) % (2 * travel)
+		layer.position = layer.first +
+			uint16(layer.ScanMode.travel(layer.phase, travel))
+
+	default:
+		layer.position += uint16(speed)
+		if layer.position >= layer.last {
+			layer.position = layer.first + (layer.position-layer.last)%span
+		}
+	}
+}
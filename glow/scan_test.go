@@ -0,0 +1,65 @@
+package glow
+
+import "testing"
+
+func scanPositions(t *testing.T, mode ScanMode, speed uint16, spins int) []uint16 {
+	layer := &Layer{Scan: 2, ScanMode: mode, Speed: speed}
+	layer.Chroma.AddColors(HSV{HueRed, 1, 1})
+	err := layer.SetupLength(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	light := newTestLight(10)
+	positions := make([]uint16, 0, spins)
+	for i := 0; i < spins; i++ {
+		positions = append(positions, layer.position)
+		layer.Spin(light)
+	}
+	return positions
+}
+
+func TestScanModes(t *testing.T) {
+	tests := []struct {
+		mode  ScanMode
+		speed uint16
+		want  []uint16
+	}{
+		{ScanForward, 1, []uint16{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1}},
+		{ScanForward, 3, []uint16{0, 3, 6, 9, 2, 5, 8, 1}},
+		{ScanReverse, 1, []uint16{0, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0, 9}},
+		{ScanReverse, 4, []uint16{0, 6, 2, 8, 4, 0}},
+		{ScanPingPong, 1, []uint16{0, 1, 2, 3, 4, 5, 6, 7, 8, 7, 6, 5, 4, 3, 2, 1, 0, 1}},
+		{ScanEase, 1, []uint16{0, 0, 1, 2, 4, 5, 6, 7, 8, 7, 6, 5, 4, 2, 1, 0, 0, 0, 1}},
+		{ScanSine, 1, []uint16{0, 0, 1, 2, 4, 6, 7, 8, 8, 8, 7, 6, 4, 2, 1, 0, 0, 0}},
+		{ScanBounce, 1, []uint16{0, 1, 3, 4, 6, 6, 7, 7, 8, 7, 7, 6, 6, 4, 3, 1, 0, 1}},
+	}
+
+	for _, test := range tests {
+		got := scanPositions(t, test.mode, test.speed, len(test.want))
+		for i := range test.want {
+			if got[i] != test.want[i] {
+				t.Fatalf("mode %d speed %d want %v got %v",
+					test.mode, test.speed, test.want, got)
+			}
+		}
+	}
+}
+
+func TestScanRandom(t *testing.T) {
+	first := scanPositions(t, ScanRandom, 1, 32)
+	second := scanPositions(t, ScanRandom, 1, 32)
+	moved := false
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("random not reproducible %v %v", first, second)
+		}
+		if first[i] >= 10 {
+			t.Fatalf("random out of bounds %v", first)
+		}
+		moved = moved || first[i] != first[0]
+	}
+	if !moved {
+		t.Fatalf("random did not move %v", first)
+	}
+}
@@ -0,0 +1,150 @@
+package glow
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+type TextDirection uint16
+
+const (
+	TextLeft TextDirection = iota
+	TextRight
+	TextUp
+	TextDown
+	TextStatic
+	TEXT_DIRECTION_COUNT
+)
+
+// Text scrolls a message through the grid at the layer's speed.
+// The message is colored by the first chroma color, or with
+// Gradient by the whole chroma gradient across its width.
+type Text struct {
+	Message   string        `yaml:"message" json:"message"`
+	Direction TextDirection `yaml:"direction" json:"direction"`
+	Gradient  bool          `yaml:"gradient" json:"gradient"`
+
+	mask     *image.Alpha
+	rendered string
+	position uint16
+}
+
+// Validate clamps the direction and renders the message to fit rows.
+func (text *Text) Validate(rows uint16) {
+	if text.Direction >= TEXT_DIRECTION_COUNT {
+		text.Direction = TextLeft
+	}
+	text.position = 0
+	if text.Message == "" {
+		text.mask = nil
+		return
+	}
+	if text.mask == nil || text.rendered != text.Message ||
+		text.mask.Rect.Dy() != int(rows) {
+		text.mask = DrawTextMask(text.Message, int(rows))
+		text.rendered = text.Message
+	}
+}
+
+func (text *Text) Width() int {
+	if text.mask == nil {
+		return 0
+	}
+	return text.mask.Rect.Dx()
+}
+
+func (text *Text) Height() int {
+	if text.mask == nil {
+		return 0
+	}
+	return text.mask.Rect.Dy()
+}
+
+// period is the number of positions for the message to cross the grid.
+func (text *Text) period(columns, rows uint16) uint16 {
+	switch text.Direction {
+	case TextLeft, TextRight:
+		return uint16(text.Width()) + columns
+	case TextUp, TextDown:
+		return uint16(text.Height()) + rows
+	}
+	return 1
+}
+
+func (text *Text) update(speed, columns, rows uint16) {
+	if speed == 0 {
+		speed = 1
+	}
+	text.position = (text.position + speed) % text.period(columns, rows)
+}
+
+// sample returns the message column shown at column x and row y
+// of the grid and its coverage there.
+func (text *Text) sample(x, y, columns, rows uint16) (column int, alpha uint8) {
+	sx, sy := int(x), int(y)
+	width, height, position := text.Width(), text.Height(), int(text.position)
+	switch text.Direction {
+	case TextLeft:
+		sx += position - int(columns)
+	case TextRight:
+		sx += width - position
+	case TextUp:
+		sy += position - int(rows)
+	case TextDown:
+		sy += height - position
+	}
+	if sx < 0 || sx >= width || sy < 0 || sy >= height {
+		return sx, 0
+	}
+	return sx, text.mask.Pix[sy*text.mask.Stride+sx]
+}
+
+// MakeCode emits the rendered message as a bitmap of coverage.
+func (text *Text) MakeCode() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "{%d,%d,%d,%d,{", text.Direction, B2I(text.Gradient),
+		text.Width(), text.Height())
+	if text.mask != nil {
+		for y := 0; y < text.Height(); y++ {
+			row := text.mask.Pix[y*text.mask.Stride:]
+			for x := 0; x < text.Width(); x++ {
+				fmt.Fprintf(&s, "%d,", row[x])
+			}
+		}
+	}
+	s.WriteString("}}")
+	return s.String()
+}
+
+// spinText draws the message over the grid. A replacing layer
+// clears the lights around it; other blend modes leave them.
+func (layer *Layer) spinText(light Light, steps uint32) {
+	text := &layer.Text
+	columns, rows := layer.Grid.columns, layer.Rows
+	width := text.Width()
+	for y := uint16(0); y < rows; y++ {
+		for x := uint16(0); x < columns; x++ {
+			index := layer.Grid.Wiring.Wire(y*columns+x, columns, rows)
+			column, alpha := text.sample(x, y, columns, rows)
+			if alpha == 0 {
+				if layer.Blend == BlendReplace {
+					layer.putDrawn(light, index, color.NRGBA{A: 255}, 0)
+				}
+				continue
+			}
+
+			c := layer.Chroma.Map(0)
+			if text.Gradient && width > 1 {
+				c = layer.Chroma.Map(uint16(column * int(layer.Length-1) / (width - 1)))
+			}
+			layer.putLevel(light, index, c, alpha)
+		}
+	}
+
+	for i := uint32(0); i < steps; i++ {
+		text.update(layer.Speed, columns, rows)
+		layer.Chroma.UpdateColors()
+	}
+}
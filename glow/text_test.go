@@ -0,0 +1,135 @@
+package glow
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func textFrame(text Text, speed uint16, columns, rows uint16) (*Frame, *Layer, *ImageLight) {
+	var chroma Chroma
+	chroma.AddColors(HSV{HueRed, 1, 1}, HSV{HueBlue, 1, 1})
+	layer := &Layer{Chroma: chroma, Speed: speed, Text: text}
+	frame := &Frame{Interval: 48}
+	frame.AddLayers(layer)
+	light := NewImageLight(columns*rows, rows)
+	return frame, layer, light
+}
+
+func TestTextMask(t *testing.T) {
+	for _, rows := range []int{5, 8, 12, 16} {
+		mask := DrawTextMask("Hi!", rows)
+		if mask.Rect.Dy() != rows {
+			t.Fatalf("rows %d mask height %d", rows, mask.Rect.Dy())
+		}
+		if mask.Rect.Dx() < 2 {
+			t.Fatalf("rows %d mask width %d", rows, mask.Rect.Dx())
+		}
+		lit := 0
+		for _, alpha := range mask.Pix {
+			if alpha > 0 {
+				lit++
+			}
+		}
+		if lit == 0 {
+			t.Fatalf("rows %d nothing drawn", rows)
+		}
+	}
+}
+
+func TestTextScroll(t *testing.T) {
+	frame, layer, light := textFrame(Text{Message: "I"}, 1, 16, 8)
+	err := frame.Setup(light.Length(), light.Rows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := layer.Text.Width()
+
+	lit := func() (columns []int) {
+		for x := uint16(0); x < 16; x++ {
+			for y := uint16(0); y < 8; y++ {
+				if light.Get(y*16+x) != (color.NRGBA{A: 255}) {
+					columns = append(columns, int(x))
+					break
+				}
+			}
+		}
+		return
+	}
+
+	frame.Spin(light)
+	if columns := lit(); len(columns) != 0 {
+		t.Fatalf("message shown before entering %v", columns)
+	}
+	for i := 0; i < 8; i++ {
+		frame.Spin(light)
+	}
+	columns := lit()
+	if len(columns) == 0 {
+		t.Fatalf("message not shown")
+	}
+	frame.Spin(light)
+	moved := lit()
+	if len(moved) == 0 || moved[0] != columns[0]-1 {
+		t.Fatalf("message did not move left from %v to %v", columns, moved)
+	}
+
+	for i := 0; i < 16+width; i++ {
+		frame.Spin(light)
+	}
+	if again := lit(); len(again) != len(moved) || again[0] != moved[0] {
+		t.Fatalf("message did not wrap %v %v", moved, again)
+	}
+}
+
+func TestTextStatic(t *testing.T) {
+	frame, layer, light := textFrame(Text{Message: "Hi", Direction: TextStatic,
+		Gradient: true}, 1, 16, 8)
+	err := frame.Setup(light.Length(), light.Rows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame.Spin(light)
+	first := make([]color.NRGBA, light.Length())
+	for i := range first {
+		first[i] = light.Get(uint16(i))
+	}
+	frame.Spin(light)
+	for i := range first {
+		if light.Get(uint16(i)) != first[i] {
+			t.Fatalf("static message moved at %d", i)
+		}
+	}
+
+	var left, right color.NRGBA
+	for x := 0; x < layer.Text.Width(); x++ {
+		for y := 0; y < layer.Text.Height(); y++ {
+			if layer.Text.mask.AlphaAt(x, y).A == 255 {
+				c := light.Get(uint16(y*16 + x))
+				if left == (color.NRGBA{}) {
+					left = c
+				}
+				right = c
+			}
+		}
+	}
+	if left.R <= left.B || right.B <= right.R {
+		t.Fatalf("gradient want red to blue got %v to %v", left, right)
+	}
+}
+
+func TestTextMakeCode(t *testing.T) {
+	text := Text{Message: "Hi", Direction: TextUp}
+	text.Validate(8)
+	code := text.MakeCode()
+	count := strings.Count(code, ",")
+	if !strings.HasPrefix(code, "{2,0,") || count != 4+text.Width()*text.Height() {
+		t.Fatalf("MakeCode %s", code)
+	}
+
+	text.Message = ""
+	text.Validate(8)
+	if text.MakeCode() != "{2,0,0,0,{}}" {
+		t.Fatalf("MakeCode empty %s", text.MakeCode())
+	}
+}
@@ -0,0 +1,210 @@
+package glow
+
+import (
+	"fmt"
+	"image/color"
+)
+
+type ColorFormat uint16
+
+const (
+	FormatRGB ColorFormat = iota
+	FormatRGBW
+	FormatRGBWW
+	COLOR_FORMAT_COUNT
+)
+
+type WhiteMode uint16
+
+const (
+	WhiteMinimum WhiteMode = iota
+	WhiteTemperature
+	WHITE_MODE_COUNT
+)
+
+const WarmTemperature = 3000
+
+// RGBW is a color for lights with a white channel, and on RGBWW
+// lights a warm white channel WW.
+type RGBW struct {
+	R, G, B, W, WW uint8
+}
+
+// White extracts the white channels of colors for RGBW and RGBWW
+// lights. WhiteMinimum moves the level common to red, green and blue
+// to white, shared evenly by both whites of RGBWW lights.
+// WhiteTemperature moves as much as the white LEDs can show, given
+// Temperature, the color temperature in kelvin of the white LED, and
+// WarmTemperature that of the warm white LED, mixing both on RGBWW
+// lights. Zero temperatures select neutral and warm white.
+type White struct {
+	Format          ColorFormat `yaml:"format" json:"format"`
+	Mode            WhiteMode   `yaml:"mode" json:"mode"`
+	Temperature     uint16      `yaml:"temperature" json:"temperature"`
+	WarmTemperature uint16      `yaml:"warm_temperature" json:"warm_temperature"`
+
+	cool  [3]uint8
+	warm  [3]uint8
+	ready bool
+}
+
+// Setup clamps the settings and finds the red, green and blue
+// levels each white LED matches at full level.
+func (white *White) Setup() {
+	if white.Format >= COLOR_FORMAT_COUNT {
+		white.Format = FormatRGB
+	}
+	if white.Mode >= WHITE_MODE_COUNT {
+		white.Mode = WhiteMinimum
+	}
+	if white.Temperature == 0 {
+		white.Temperature = NeutralTemperature
+	}
+	if white.WarmTemperature == 0 {
+		white.WarmTemperature = WarmTemperature
+	}
+	white.Temperature = max(min(white.Temperature, MaximumTemperature), MinimumTemperature)
+	white.WarmTemperature = max(min(white.WarmTemperature, MaximumTemperature), MinimumTemperature)
+
+	white.cool = [3]uint8{MaximumLevel, MaximumLevel, MaximumLevel}
+	white.warm = white.cool
+	if white.Mode == WhiteTemperature {
+		white.cool = whiteLevels(white.Temperature)
+		white.warm = whiteLevels(white.WarmTemperature)
+	}
+	white.ready = true
+}
+
+func whiteLevels(kelvin uint16) (levels [3]uint8) {
+	rgb := blackbody(float64(kelvin))
+	for ch := range levels {
+		levels[ch] = uint8(rgb[ch] + 0.5)
+	}
+	return
+}
+
+// Extract returns c with its white channels for the format.
+func (white *White) Extract(c color.NRGBA) (w RGBW) {
+	if !white.ready {
+		white.Setup()
+	}
+	rgb := [3]uint8{c.R, c.G, c.B}
+	switch white.Format {
+	case FormatRGBW:
+		w.W = takeWhite(&rgb, white.cool)
+	case FormatRGBWW:
+		w.W, w.WW = mixWhite(&rgb, white.cool, white.warm)
+		if white.Mode == WhiteMinimum {
+			w.W, w.WW = w.W-w.W/2, w.W/2
+		}
+	}
+	w.R, w.G, w.B = rgb[0], rgb[1], rgb[2]
+	return
+}
+
+// levelFits returns the highest level of an LED of the given
+// levels that fits in rgb.
+func levelFits(rgb *[3]uint8, levels [3]uint8) uint8 {
+	amount := uint16(MaximumLevel)
+	for ch, level := range levels {
+		if level > 0 {
+			amount = min(amount, uint16(rgb[ch])*MaximumLevel/uint16(level))
+		}
+	}
+	return uint8(amount)
+}
+
+// takeWhite removes the most of an LED of the given levels
+// that fits in rgb and returns its level.
+func takeWhite(rgb *[3]uint8, levels [3]uint8) uint8 {
+	amount := levelFits(rgb, levels)
+	for ch, level := range levels {
+		rgb[ch] -= uint8(uint16(amount) * uint16(level) / MaximumLevel)
+	}
+	return amount
+}
+
+// mixWhite removes the mix of the cool and warm LEDs that fits in
+// rgb and takes the most light from it, returning their levels. The
+// best mix uses one LED alone or exactly fills two of the channels.
+func mixWhite(rgb *[3]uint8, cool, warm [3]uint8) (w, ww uint8) {
+	fits := func(a, b int32) bool {
+		for ch := range rgb {
+			if a*int32(cool[ch])+b*int32(warm[ch]) > int32(rgb[ch])*MaximumLevel {
+				return false
+			}
+		}
+		return true
+	}
+
+	coolSum := int32(cool[0]) + int32(cool[1]) + int32(cool[2])
+	warmSum := int32(warm[0]) + int32(warm[1]) + int32(warm[2])
+	best, bestW, bestWW := int32(-1), int32(0), int32(0)
+	try := func(a, b int32) {
+		a, b = min(a, MaximumLevel), min(b, MaximumLevel)
+		if a < 0 || b < 0 || !fits(a, b) {
+			return
+		}
+		if moved := a*coolSum + b*warmSum; moved > best {
+			best, bestW, bestWW = moved, a, b
+		}
+	}
+
+	try(int32(levelFits(rgb, cool)), 0)
+	try(0, int32(levelFits(rgb, warm)))
+	for i := 0; i < 2; i++ {
+		for j := i + 1; j < 3; j++ {
+			det := int32(cool[i])*int32(warm[j]) - int32(cool[j])*int32(warm[i])
+			if det == 0 {
+				continue
+			}
+			a := (int32(rgb[i])*int32(warm[j]) - int32(rgb[j])*int32(warm[i])) * MaximumLevel / det
+			b := (int32(cool[i])*int32(rgb[j]) - int32(cool[j])*int32(rgb[i])) * MaximumLevel / det
+			try(a, b)
+		}
+	}
+
+	for ch := range rgb {
+		rgb[ch] -= uint8((bestW*int32(cool[ch]) + bestWW*int32(warm[ch])) / MaximumLevel)
+	}
+	return uint8(bestW), uint8(bestWW)
+}
+
+// Combine returns the color an RGBW light shows.
+func (white *White) Combine(w RGBW) color.NRGBA {
+	if !white.ready {
+		white.Setup()
+	}
+	var rgb [3]uint8
+	for ch, v := range [3]uint8{w.R, w.G, w.B} {
+		sum := uint16(v) +
+			uint16(w.W)*uint16(white.cool[ch])/MaximumLevel +
+			uint16(w.WW)*uint16(white.warm[ch])/MaximumLevel
+		rgb[ch] = uint8(min(sum, MaximumLevel))
+	}
+	return color.NRGBA{rgb[0], rgb[1], rgb[2], 255}
+}
+
+// Tint returns the color of the white LED, or with warm that
+// of the warm white LED.
+func (white *White) Tint(warm bool) color.NRGBA {
+	if !white.ready {
+		white.Setup()
+	}
+	levels := white.cool
+	if warm {
+		levels = white.warm
+	}
+	return color.NRGBA{levels[0], levels[1], levels[2], 255}
+}
+
+// MakeCode emits the format, mode and levels of each white LED.
+func (white *White) MakeCode() string {
+	if !white.ready {
+		white.Setup()
+	}
+	return fmt.Sprintf("{%d,%d,{%d,%d,%d},{%d,%d,%d}}",
+		white.Format, white.Mode,
+		white.cool[0], white.cool[1], white.cool[2],
+		white.warm[0], white.warm[1], white.warm[2])
+}
@@ -0,0 +1,47 @@
+package glow
+
+import "image/color"
+
+// RGBWLight is a Light that also takes colors with white channels.
+type RGBWLight interface {
+	Light
+	SetRGBW(uint16, RGBW)
+}
+
+var _ Light = (*WhiteLight)(nil)
+
+// WhiteLight extracts the white channels of each color set on it
+// for the Target lights. Get returns colors as they were set.
+type WhiteLight struct {
+	White  *White
+	Target RGBWLight
+	cells  []color.NRGBA
+}
+
+func NewWhiteLight(white *White, target RGBWLight) *WhiteLight {
+	white.Setup()
+	wl := &WhiteLight{
+		White:  white,
+		Target: target,
+	}
+	return wl
+}
+
+func (wl *WhiteLight) Get(i uint16) color.NRGBA {
+	if int(i) >= len(wl.cells) {
+		return color.NRGBA{}
+	}
+	return wl.cells[i]
+}
+
+func (wl *WhiteLight) Set(i uint16, c color.NRGBA) {
+	if int(i) >= len(wl.cells) {
+		wl.cells = append(wl.cells, make([]color.NRGBA, int(i)+1-len(wl.cells))...)
+	}
+	wl.cells[i] = c
+	wl.Target.SetRGBW(i, wl.White.Extract(c))
+}
+
+func (wl *WhiteLight) Refresh() {
+	wl.Target.Refresh()
+}
@@ -0,0 +1,99 @@
+package glow
+
+import (
+	"image/color"
+	"testing"
+)
+
+type testRGBWLight struct {
+	*testLight
+	whites []RGBW
+}
+
+func (tl *testRGBWLight) SetRGBW(i uint16, w RGBW) { tl.whites[i] = w }
+
+func TestWhiteMinimum(t *testing.T) {
+	white := &White{Format: FormatRGBW}
+	got := white.Extract(color.NRGBA{200, 100, 50, 255})
+	if got != (RGBW{150, 50, 0, 50, 0}) {
+		t.Fatalf("rgbw minimum got %v", got)
+	}
+	if c := white.Combine(got); c != (color.NRGBA{200, 100, 50, 255}) {
+		t.Fatalf("rgbw combine got %v", c)
+	}
+
+	white = &White{Format: FormatRGBWW}
+	got = white.Extract(color.NRGBA{200, 100, 51, 255})
+	if got != (RGBW{149, 49, 0, 26, 25}) {
+		t.Fatalf("rgbww minimum got %v", got)
+	}
+
+	white = &White{}
+	got = white.Extract(color.NRGBA{200, 100, 50, 255})
+	if got != (RGBW{200, 100, 50, 0, 0}) {
+		t.Fatalf("rgb got %v", got)
+	}
+}
+
+func TestWhiteTemperature(t *testing.T) {
+	white := &White{Format: FormatRGBW, Mode: WhiteTemperature, Temperature: 3000}
+	warm := white.Tint(false)
+	if warm.R != 255 || warm.G >= warm.R || warm.B >= warm.G {
+		t.Fatalf("warm tint %v", warm)
+	}
+	got := white.Extract(warm)
+	if got.W != 255 || got.R > 1 || got.G > 1 || got.B > 1 {
+		t.Fatalf("warm white extract got %v", got)
+	}
+	got = white.Extract(color.NRGBA{255, 255, 255, 255})
+	if got.W != 255 || got.R != 0 || got.B <= got.G {
+		t.Fatalf("warm led with pure white got %v", got)
+	}
+
+	white = &White{Format: FormatRGBWW, Mode: WhiteTemperature}
+	got = white.Extract(white.Tint(true))
+	if got.WW != 255 || got.W != 0 {
+		t.Fatalf("warm tint on rgbww got %v", got)
+	}
+	got = white.Extract(color.NRGBA{255, 255, 255, 255})
+	if got.W != 255 || got.WW != 0 || got.R > 1 || got.G > 2 || got.B > 8 {
+		t.Fatalf("white on rgbww got %v", got)
+	}
+	if c := white.Combine(got); c.R < 253 || c.G < 253 || c.B < 253 {
+		t.Fatalf("white on rgbww combines to %v", c)
+	}
+	mix := white.Combine(RGBW{W: 100, WW: 100})
+	got = white.Extract(mix)
+	if got.W < 95 || got.WW < 95 || got.R > 3 || got.G > 3 || got.B > 3 {
+		t.Fatalf("mixed whites %v got %v", mix, got)
+	}
+}
+
+func TestWhiteLight(t *testing.T) {
+	target := &testRGBWLight{newTestLight(4), make([]RGBW, 4)}
+	wl := NewWhiteLight(&White{Format: FormatRGBW}, target)
+
+	layer := &Layer{}
+	layer.Chroma.AddColors(HSV{HueRed, 0.5, 1})
+	frame := &Frame{}
+	frame.AddLayers(layer)
+	if err := frame.Setup(4, 1); err != nil {
+		t.Fatal(err)
+	}
+	frame.Spin(wl)
+
+	for i := uint16(0); i < 4; i++ {
+		c := wl.Get(i)
+		w := target.whites[i]
+		if w.W != c.B || w.R != c.R-c.B || w.B != 0 {
+			t.Fatalf("light %d set %v got %v", i, c, w)
+		}
+	}
+}
+
+func TestWhiteMakeCode(t *testing.T) {
+	white := &White{Format: FormatRGBWW}
+	if got := white.MakeCode(); got != "{2,0,{255,255,255},{255,255,255}}" {
+		t.Fatalf("make code got %s", got)
+	}
+}
@@ -0,0 +1,56 @@
+package glow
+
+// Wiring is how the physical lights are connected. It belongs to the
+// profile of the lights an effect plays on, so frames and grids never
+// save it; set Frame.Wiring before Setup.
+type Wiring uint16
+
+const (
+	Progressive Wiring = iota
+	Serpentine
+	ColumnProgressive
+	ColumnSerpentine
+	WIRING_COUNT
+)
+
+// Wire maps a row major offset to the index of the physical light.
+func (wiring Wiring) Wire(offset, columns, rows uint16) uint16 {
+	if wiring == Progressive || offset >= columns*rows {
+		return offset
+	}
+
+	x, y := offset%columns, offset/columns
+	switch wiring {
+	case Serpentine:
+		if y&1 == 1 {
+			x = columns - x - 1
+		}
+		return y*columns + x
+	case ColumnSerpentine:
+		if x&1 == 1 {
+			y = rows - y - 1
+		}
+	}
+	return x*rows + y
+}
+
+// Locate returns the column and row of the physical light at index.
+func (wiring Wiring) Locate(index, columns, rows uint16) (x, y int) {
+	switch wiring {
+	case Serpentine:
+		x, y = int(index%columns), int(index/columns)
+		if y&1 == 1 {
+			x = int(columns) - x - 1
+		}
+	case ColumnProgressive:
+		x, y = int(index/rows), int(index%rows)
+	case ColumnSerpentine:
+		x, y = int(index/rows), int(index%rows)
+		if x&1 == 1 {
+			y = int(rows) - y - 1
+		}
+	default:
+		x, y = int(index%columns), int(index/columns)
+	}
+	return
+}
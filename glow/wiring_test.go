@@ -0,0 +1,61 @@
+package glow
+
+import "testing"
+
+var threeByFourSerpentine = []uint16{
+	0, 1, 2, 3,
+	7, 6, 5, 4,
+	8, 9, 10, 11,
+}
+
+var threeByFourColumnSerpentine = []uint16{
+	0, 5, 6, 11,
+	1, 4, 7, 10,
+	2, 3, 8, 9,
+}
+
+func TestWiring(t *testing.T) {
+	const columns, rows = 4, 3
+	for wiring := Progressive; wiring < WIRING_COUNT; wiring++ {
+		for offset := uint16(0); offset < columns*rows; offset++ {
+			index := wiring.Wire(offset, columns, rows)
+			x, y := wiring.Locate(index, columns, rows)
+			if uint16(y*columns+x) != offset {
+				t.Fatalf("wiring %d offset %d located at %d,%d", wiring, offset, x, y)
+			}
+		}
+	}
+
+	var grid Grid
+	grid.Wiring = Serpentine
+	testGridBase(t, &grid, 12, rows, TopLeft, Horizontal)
+	testTable(t, &grid, threeByFourSerpentine)
+
+	grid.Wiring = ColumnSerpentine
+	testGridBase(t, &grid, 12, rows, TopLeft, Horizontal)
+	testTable(t, &grid, threeByFourColumnSerpentine)
+}
+
+func TestWiringPreview(t *testing.T) {
+	pv := NewPreview()
+	pv.Spins = 3
+
+	frame := previewFrame()
+	want, err := pv.Render(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pv.Wiring = ColumnSerpentine
+	got, err := pv.Render(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		for p := range want[i].Pix {
+			if want[i].Pix[p] != got[i].Pix[p] {
+				t.Fatalf("spin %d differs at %d", i, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package imageio
+
+import (
+	"fmt"
+	"gglow/glow"
+	"gglow/iohandler"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var _ iohandler.OutHandler = (*ImageHandler)(nil)
+
+type ImageHandler struct {
+	Preview *glow.Preview
+	path    string
+	driver  string
+	folder  string
+}
+
+func NewImageHandler(driver, path string) (*ImageHandler, error) {
+	switch driver {
+	case iohandler.DRIVER_GIF, iohandler.DRIVER_APNG, iohandler.DRIVER_SPRITES:
+	default:
+		return nil, fmt.Errorf("unknown image driver %s", driver)
+	}
+
+	ih := &ImageHandler{
+		Preview: glow.NewPreview(),
+		path:    path,
+		driver:  driver,
+	}
+	return ih, nil
+}
+
+func (ih *ImageHandler) Create(path string) (err error) {
+	return makeFolder(path)
+}
+
+func (ih *ImageHandler) CreateFolder(title string) error {
+	ih.folder = title
+	return makeFolder(filepath.Join(ih.path, title))
+}
+
+func (ih *ImageHandler) UpdateEffect(folder, title string, frame *glow.Frame) error {
+	return ih.CreateEffect(folder, title, frame)
+}
+
+func (ih *ImageHandler) CreateEffect(folder, title string, frame *glow.Frame) (err error) {
+	var file *os.File
+	file, err = os.Create(filepath.Join(ih.path, folder, ih.FileName(title)))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	switch ih.driver {
+	case iohandler.DRIVER_GIF:
+		err = ih.Preview.WriteGIF(file, frame)
+	case iohandler.DRIVER_APNG:
+		err = ih.Preview.WriteAPNG(file, frame)
+	case iohandler.DRIVER_SPRITES:
+		err = ih.Preview.WriteSprites(file, frame)
+	}
+	if err != nil {
+		err = fmt.Errorf("%s/%s: %v", folder, title, err)
+	}
+	return
+}
+
+func (ih *ImageHandler) FileName(title string) string {
+	name := strings.ReplaceAll(title, " ", "_")
+	switch ih.driver {
+	case iohandler.DRIVER_GIF:
+		return name + ".gif"
+	case iohandler.DRIVER_APNG:
+		return name + ".apng"
+	}
+	return name + ".png"
+}
+
+func (ih *ImageHandler) OnExit() error {
+	return nil
+}
+
+func makeFolder(path string) (err error) {
+	var info fs.FileInfo
+	info, err = os.Stat(path)
+	if err == nil && info.IsDir() {
+		return
+	}
+	if err == nil {
+		return fmt.Errorf("%s exists but is not a directory", path)
+	}
+	return os.MkdirAll(path, os.ModePerm)
+}
@@ -0,0 +1,51 @@
+package imageio
+
+import (
+	"gglow/glow"
+	"gglow/iohandler"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageHandler(t *testing.T) {
+	path := t.TempDir()
+	frame := glow.NewFrame()
+
+	for _, driver := range []string{iohandler.DRIVER_GIF,
+		iohandler.DRIVER_APNG, iohandler.DRIVER_SPRITES} {
+
+		ih, err := NewImageHandler(driver, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ih.Preview.Spins = 3
+
+		err = ih.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ih.CreateFolder("examples")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ih.CreateEffect("examples", "Basic Frame", frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		name := filepath.Join(path, "examples", ih.FileName("Basic Frame"))
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Fatalf("%s is empty", name)
+		}
+	}
+
+	_, err := NewImageHandler(iohandler.DRIVER_CODE, path)
+	if err == nil {
+		t.Fatal("expected error for code driver")
+	}
+}
@@ -1,6 +1,7 @@
 package iohandler
 
 import (
+	"gglow/glow"
 	"io"
 	"os"
 
@@ -17,6 +18,11 @@ type Accessor struct {
 	Database string
 	Folder   string
 	Effect   string
+	PixelMap string
+	Output   *glow.Output          `yaml:",omitempty"`
+	White    *glow.White           `yaml:",omitempty"`
+	Wiring   glow.Wiring           `yaml:",omitempty"`
+	Preview  *glow.PreviewSettings `yaml:",omitempty"`
 }
 
 type AccessorView struct {
@@ -16,6 +16,9 @@ const DRIVER_MYSQL = "mysql"
 const DRIVER_SQLLITE3 = "sqlite3"
 const DRIVER_POSTGRES = "postgres"
 const DRIVER_CODE = "code"
+const DRIVER_GIF = "gif"
+const DRIVER_APNG = "apng"
+const DRIVER_SPRITES = "sprites"
 
 type KeyValue struct {
 	Key   string
@@ -0,0 +1,39 @@
+package iohandler
+
+import "gglow/glow"
+
+// PaletteHandler stores the shared palettes layers refer to by title.
+type PaletteHandler interface {
+	ListPalettes() ([]string, error)
+	ReadPalette(title string) (*glow.Palette, error)
+	WritePalette(palette *glow.Palette) error
+	RemovePalette(title string) error
+}
+
+// CopyPalettes writes every palette of dataIn to dataOut when both
+// store palettes.
+func CopyPalettes(dataIn InHandler, dataOut OutHandler) error {
+	in, ok := dataIn.(PaletteHandler)
+	if !ok {
+		return nil
+	}
+	out, ok := dataOut.(PaletteHandler)
+	if !ok {
+		return nil
+	}
+	titles, err := in.ListPalettes()
+	if err != nil {
+		return err
+	}
+	for _, title := range titles {
+		palette, err := in.ReadPalette(title)
+		if err != nil {
+			return err
+		}
+		err = out.WritePalette(palette)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package iohandler
+
+import "gglow/glow"
+
+// PlaylistHandler stores the playlists of effects a device cycles through.
+type PlaylistHandler interface {
+	ListPlaylists() ([]string, error)
+	ReadPlaylist(title string) (*glow.Playlist, error)
+	WritePlaylist(playlist *glow.Playlist) error
+	RemovePlaylist(title string) error
+}
+
+// CopyPlaylists writes every playlist of dataIn to dataOut when both
+// store playlists.
+func CopyPlaylists(dataIn InHandler, dataOut OutHandler) error {
+	in, ok := dataIn.(PlaylistHandler)
+	if !ok {
+		return nil
+	}
+	out, ok := dataOut.(PlaylistHandler)
+	if !ok {
+		return nil
+	}
+	titles, err := in.ListPlaylists()
+	if err != nil {
+		return err
+	}
+	for _, title := range titles {
+		playlist, err := in.ReadPlaylist(title)
+		if err != nil {
+			return err
+		}
+		err = out.WritePlaylist(playlist)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,16 @@ const (
 	GlowThemeScale
 	AccessFile
 	SplitOffset
+	StripWiring
+	StripMap
+	StripGamma
+	StripTemperature
+	StripBrightness
+	StripBudget
+	StripFormat
+	StripWhiteMode
+	StripWhiteTemperature
+	StripParity
 )
 
 var settings = []string{
@@ -24,6 +34,16 @@ var settings = []string{
 	"theme_scale",
 	"accessor",
 	"split_offset",
+	"strip_wiring",
+	"strip_map",
+	"strip_gamma",
+	"strip_temperature",
+	"strip_brightness",
+	"strip_budget",
+	"strip_format",
+	"strip_white_mode",
+	"strip_white_temperature",
+	"strip_parity",
 }
 
 func (s Settings) String() string {
@@ -0,0 +1,130 @@
+package sqlio
+
+import (
+	"database/sql"
+	"gglow/glow"
+	"path/filepath"
+	"testing"
+)
+
+func TestPalettes(t *testing.T) {
+	ioh, err := NewSqlHandler("sqlite3", filepath.Join(t.TempDir(), "glow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioh.OnExit()
+	if err = ioh.Create(""); err != nil {
+		t.Fatal(err)
+	}
+
+	brand := &glow.Palette{Title: "Brand", Colors: []glow.HSV{
+		{Hue: 200, Saturation: 1, Value: 1}, {Hue: 40, Saturation: 1, Value: 1}}}
+	if err = ioh.WritePalette(brand); err != nil {
+		t.Fatal(err)
+	}
+	layer := &glow.Layer{}
+	layer.Chroma.Palette = brand.Title
+	layer.Chroma.AddColors(glow.HSV{Value: 1})
+	frame := &glow.Frame{}
+	frame.AddLayers(layer)
+	if err = ioh.CreateFolder("folder"); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioh.CreateEffect("folder", "effect", frame); err != nil {
+		t.Fatal(err)
+	}
+
+	brand.Colors[0] = glow.HSV{Hue: 300, Saturation: 1, Value: 1}
+	if err = ioh.WritePalette(brand); err != nil {
+		t.Fatal(err)
+	}
+	titles, err := ioh.ListPalettes()
+	if err != nil || len(titles) != 1 || titles[0] != brand.Title {
+		t.Fatalf("list palettes got %v %v", titles, err)
+	}
+
+	read, err := ioh.ReadEffect("folder", "effect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := read.Layers[0].Chroma.Colors
+	if len(colors) != 2 || colors[0] != brand.Colors[0] {
+		t.Fatalf("resolved colors got %v", colors)
+	}
+
+	if err = ioh.RemovePalette(brand.Title); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioh.ReadPalette(brand.Title); err == nil {
+		t.Fatalf("removed palette read")
+	}
+	quoted := &glow.Palette{Title: "Brand's", Colors: brand.Colors}
+	if err = ioh.WritePalette(quoted); err != nil {
+		t.Fatal(err)
+	}
+	if read, err := ioh.ReadPalette(quoted.Title); err != nil || read.Title != quoted.Title {
+		t.Fatalf("quoted palette got %v %v", read, err)
+	}
+}
+
+func TestPalettesLegacyView(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glow.db")
+	// databases before the palette library had a palettes view
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"CREATE TABLE colors (title VARCHAR(80), palette TEXT);",
+		"CREATE VIEW palettes AS SELECT title, palette FROM colors;",
+	} {
+		if _, err = db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	ioh, err := NewSqlHandler("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioh.OnExit()
+	brand := &glow.Palette{Title: "brand", Colors: []glow.HSV{{Hue: 200, Saturation: 1, Value: 1}}}
+	if err = ioh.WritePalette(brand); err != nil {
+		t.Fatal(err)
+	}
+	ls, err := ioh.ListPalettes()
+	if err != nil || len(ls) != 1 {
+		t.Fatalf("legacy palettes %v %v", ls, err)
+	}
+
+	if _, err = ioh.db.Exec("DROP TABLE palettes;"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioh.db.Exec("CREATE VIEW palettes AS SELECT title, palette FROM colors;"); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioh.Create(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlterSchemas(t *testing.T) {
+	for _, schema := range Schemas {
+		ioh, err := NewSqlHandler("sqlite3", filepath.Join(t.TempDir(), "glow.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioh.schema = schema
+		if err = ioh.alter(); err != nil {
+			t.Fatalf("schema %d alter %v", schema.Version.Major, err)
+		}
+		if _, err = ioh.ListPalettes(); err != nil {
+			t.Fatalf("schema %d palettes %v", schema.Version.Major, err)
+		}
+		if _, err = ioh.ListPlaylists(); err != nil {
+			t.Fatalf("schema %d playlists %v", schema.Version.Major, err)
+		}
+		ioh.OnExit()
+	}
+}
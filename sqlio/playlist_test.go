@@ -0,0 +1,51 @@
+package sqlio
+
+import (
+	"gglow/glow"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlaylists(t *testing.T) {
+	ioh, err := NewSqlHandler("sqlite3", filepath.Join(t.TempDir(), "glow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioh.OnExit()
+	if err = ioh.Create(""); err != nil {
+		t.Fatal(err)
+	}
+
+	evening := &glow.Playlist{Title: "Evening's", Entries: []glow.PlaylistEntry{
+		{Folder: "effects", Title: "rainbow", Duration: 5000},
+		{Folder: "effects", Title: "scan", Duration: 8000,
+			Transition: glow.TransitionCrossfade, Fade: 1000}}}
+	if err = ioh.WritePlaylist(evening); err != nil {
+		t.Fatal(err)
+	}
+	evening.Entries[0].Transition = glow.TransitionWipe
+	evening.Entries[0].Fade = 500
+	if err = ioh.WritePlaylist(evening); err != nil {
+		t.Fatal(err)
+	}
+	titles, err := ioh.ListPlaylists()
+	if err != nil || len(titles) != 1 || titles[0] != evening.Title {
+		t.Fatalf("list playlists got %v %v", titles, err)
+	}
+
+	read, err := ioh.ReadPlaylist(evening.Title)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Entries) != 2 || read.Entries[0] != evening.Entries[0] ||
+		read.Entries[1] != evening.Entries[1] {
+		t.Fatalf("read entries got %v", read.Entries)
+	}
+
+	if err = ioh.RemovePlaylist(evening.Title); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioh.ReadPlaylist(evening.Title); err == nil {
+		t.Fatalf("removed playlist read")
+	}
+}
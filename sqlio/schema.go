@@ -12,6 +12,16 @@ func init() {
 		schema.WriteEffect = schema.writeEffect
 		schema.ExistsEffect = schema.existsEffect
 		schema.ReadEffect = schema.readEffect
+		schema.ListPalettes = schema.listPalettes
+		schema.ReadPalette = schema.readPalette
+		schema.ExistsPalette = schema.existsPalette
+		schema.WritePalette = schema.writePalette
+		schema.RemovePalette = schema.removePalette
+		schema.ListPlaylists = schema.listPlaylists
+		schema.ReadPlaylist = schema.readPlaylist
+		schema.ExistsPlaylist = schema.existsPlaylist
+		schema.WritePlaylist = schema.writePlaylist
+		schema.RemovePlaylist = schema.removePlaylist
 
 		// additions and overrides
 		switch schema.Version.Major {
@@ -26,6 +36,10 @@ type Schema struct {
 	AlterSQL  []string
 	CreateSQL []string
 	DropSQL   []string
+	// LegacyViews are views older databases have under the names of
+	// tables the schema makes. They are dropped before altering or
+	// creating when the database still has them.
+	LegacyViews []string
 
 	ListFolder   func(folder string) (query string)
 	SelectFolder func(folder string) (query string)
@@ -33,12 +47,38 @@ type Schema struct {
 	ReadEffect   func(folder, title string) (query string)
 	WriteEffect  func(update bool, items ...string) (query string)
 
+	ListPalettes  func() (query string)
+	ReadPalette   func(title string) (query string, args []any)
+	ExistsPalette func(title string) (query string, args []any)
+	WritePalette  func(update bool, title, source string) (query string, args []any)
+	RemovePalette func(title string) (query string, args []any)
+
+	ListPlaylists  func() (query string)
+	ReadPlaylist   func(title string) (query string, args []any)
+	ExistsPlaylist func(title string) (query string, args []any)
+	WritePlaylist  func(update bool, title, source string) (query string, args []any)
+	RemovePlaylist func(title string) (query string, args []any)
+
 	selectFolderSQL string
 	listEffectsSQL  string
 	readEffectSQL   string
 	existsEffectSQL string
 	insertEffectSQL string
 	updateEffectSQL string
+
+	listPalettesSQL  string
+	readPaletteSQL   string
+	existsPaletteSQL string
+	insertPaletteSQL string
+	updatePaletteSQL string
+	removePaletteSQL string
+
+	listPlaylistsSQL  string
+	readPlaylistSQL   string
+	existsPlaylistSQL string
+	insertPlaylistSQL string
+	updatePlaylistSQL string
+	removePlaylistSQL string
 }
 
 var SchemaMap = make(map[uint64]*Schema)
@@ -80,3 +120,52 @@ func (schema *Schema) existsEffect(folder, title string) string {
 func (schema *Schema) readEffect(folder, title string) string {
 	return fmt.Sprintf(schema.readEffectSQL, folder, title)
 }
+
+// The palette and playlist queries take their values as arguments
+// with ? placeholders.
+
+func (schema *Schema) listPalettes() string {
+	return schema.listPalettesSQL
+}
+
+func (schema *Schema) readPalette(title string) (string, []any) {
+	return schema.readPaletteSQL, []any{title}
+}
+
+func (schema *Schema) existsPalette(title string) (string, []any) {
+	return schema.existsPaletteSQL, []any{title}
+}
+
+func (schema *Schema) writePalette(update bool, title, source string) (string, []any) {
+	if update {
+		return schema.updatePaletteSQL, []any{source, title}
+	}
+	return schema.insertPaletteSQL, []any{title, source}
+}
+
+func (schema *Schema) removePalette(title string) (string, []any) {
+	return schema.removePaletteSQL, []any{title}
+}
+
+func (schema *Schema) listPlaylists() string {
+	return schema.listPlaylistsSQL
+}
+
+func (schema *Schema) readPlaylist(title string) (string, []any) {
+	return schema.readPlaylistSQL, []any{title}
+}
+
+func (schema *Schema) existsPlaylist(title string) (string, []any) {
+	return schema.existsPlaylistSQL, []any{title}
+}
+
+func (schema *Schema) writePlaylist(update bool, title, source string) (string, []any) {
+	if update {
+		return schema.updatePlaylistSQL, []any{source, title}
+	}
+	return schema.insertPlaylistSQL, []any{title, source}
+}
+
+func (schema *Schema) removePlaylist(title string) (string, []any) {
+	return schema.removePlaylistSQL, []any{title}
+}
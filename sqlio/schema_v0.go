@@ -3,8 +3,19 @@ package sqlio
 import "gglow/iohandler"
 
 var schema_v0 = &Schema{
-	Version:  iohandler.Version{Major: 0, Minor: 0, Patch: 0, Extension: 0},
-	AlterSQL: []string{},
+	Version: iohandler.Version{Major: 0, Minor: 0, Patch: 0, Extension: 0},
+	AlterSQL: []string{
+		`CREATE TABLE IF NOT EXISTS palettes (
+title VARCHAR(80) NOT NULL,
+palette TEXT,
+PRIMARY KEY (title)
+);`,
+		`CREATE TABLE IF NOT EXISTS playlists (
+title VARCHAR(80) NOT NULL,
+playlist TEXT,
+PRIMARY KEY (title)
+);`,
+	},
 	CreateSQL: []string{
 		`CREATE TABLE effects (
 folder VARCHAR(80) NOT NULL,
@@ -19,13 +30,25 @@ FROM effects
 WHERE title = '..'
 ORDER BY folder;
 `,
+		`CREATE TABLE palettes (
+title VARCHAR(80) NOT NULL,
+palette TEXT,
+PRIMARY KEY (title)
+);`,
+		`CREATE TABLE playlists (
+title VARCHAR(80) NOT NULL,
+playlist TEXT,
+PRIMARY KEY (title)
+);`,
 	},
 	DropSQL: []string{
-		"DROP VIEW IF EXISTS palettes;",
 		"DROP VIEW IF EXISTS folders;",
 		"DROP TABLE IF EXISTS effects;",
 		"DROP TABLE IF EXISTS colors;",
+		"DROP TABLE IF EXISTS palettes;",
+		"DROP TABLE IF EXISTS playlists;",
 	},
+	LegacyViews: []string{"palettes"},
 	// selectFolderSQL: "SELECT title FROM effects WHERE folder = '%s' ORDER BY title;",
 	selectFolderSQL: "SELECT folder,title FROM effects WHERE title = '..' ORDER BY folder;",
 	listEffectsSQL:  "SELECT title,folder FROM effects WHERE folder = '%s' ORDER BY folder,title;",
@@ -33,4 +56,18 @@ ORDER BY folder;
 	existsEffectSQL: "SELECT title FROM effects WHERE folder = '%s' AND title = '%s';",
 	updateEffectSQL: "UPDATE effects SET effect = '%s' WHERE folder = '%s' AND title = '%s'",
 	insertEffectSQL: "INSERT INTO effects (folder, title, effect) VALUES('%s', '%s', '%s')",
+
+	listPalettesSQL:  "SELECT title FROM palettes ORDER BY title;",
+	readPaletteSQL:   "SELECT palette FROM palettes WHERE title = ?",
+	existsPaletteSQL: "SELECT title FROM palettes WHERE title = ?;",
+	updatePaletteSQL: "UPDATE palettes SET palette = ? WHERE title = ?",
+	insertPaletteSQL: "INSERT INTO palettes (title, palette) VALUES(?, ?)",
+	removePaletteSQL: "DELETE FROM palettes WHERE title = ?",
+
+	listPlaylistsSQL:  "SELECT title FROM playlists ORDER BY title;",
+	readPlaylistSQL:   "SELECT playlist FROM playlists WHERE title = ?",
+	existsPlaylistSQL: "SELECT title FROM playlists WHERE title = ?;",
+	updatePlaylistSQL: "UPDATE playlists SET playlist = ? WHERE title = ?",
+	insertPlaylistSQL: "INSERT INTO playlists (title, playlist) VALUES(?, ?)",
+	removePlaylistSQL: "DELETE FROM playlists WHERE title = ?",
 }
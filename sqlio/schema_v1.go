@@ -3,8 +3,19 @@ package sqlio
 import "gglow/iohandler"
 
 var schema_v1 = &Schema{
-	Version:  iohandler.Version{Major: 1, Minor: 0, Patch: 0, Extension: 0},
-	AlterSQL: []string{},
+	Version: iohandler.Version{Major: 1, Minor: 0, Patch: 0, Extension: 0},
+	AlterSQL: []string{
+		`CREATE TABLE IF NOT EXISTS palettes (
+title VARCHAR(80) NOT NULL,
+palette TEXT,
+PRIMARY KEY (title)
+);`,
+		`CREATE TABLE IF NOT EXISTS playlists (
+title VARCHAR(80) NOT NULL,
+playlist TEXT,
+PRIMARY KEY (title)
+);`,
+	},
 	CreateSQL: []string{
 		`CREATE TABLE version (
 major SMALLINT,
@@ -29,11 +40,23 @@ title VARCHAR(80) NOT NULL,
 uuid VARCHAR(40) NOT NULL,
 PRIMARY KEY (title,uuid));`,
 		"CREATE INDEX tags_uuid ON tags (uuid,title);",
+		`CREATE TABLE palettes (
+title VARCHAR(80) NOT NULL,
+palette TEXT,
+PRIMARY KEY (title)
+);`,
+		`CREATE TABLE playlists (
+title VARCHAR(80) NOT NULL,
+playlist TEXT,
+PRIMARY KEY (title)
+);`,
 	},
 	DropSQL: []string{
 		"DROP TABLE IF EXISTS version;",
 		"DROP TABLE IF EXISTS content;",
 		"DROP TABLE IF EXISTS tags;",
+		"DROP TABLE IF EXISTS palettes;",
+		"DROP TABLE IF EXISTS playlists;",
 	},
 	selectFolderSQL: "SELECT folder FROM content WHERE title = '..' AND category = 'effect' ORDER BY folder;",
 	listEffectsSQL:  "SELECT title FROM content WHERE folder = '%s' AND category = 'effect' ORDER BY title;",
@@ -41,4 +64,18 @@ PRIMARY KEY (title,uuid));`,
 	existsEffectSQL: "SELECT title FROM effects WHERE folder = '%s' AND title = '%s';",
 	updateEffectSQL: "UPDATE effects SET effect = '%s' WHERE folder = '%s' AND title = '%s'",
 	insertEffectSQL: "INSERT INTO effects (folder, title, effect) VALUES('%s', '%s', '%s')",
+
+	listPalettesSQL:  "SELECT title FROM palettes ORDER BY title;",
+	readPaletteSQL:   "SELECT palette FROM palettes WHERE title = ?",
+	existsPaletteSQL: "SELECT title FROM palettes WHERE title = ?;",
+	updatePaletteSQL: "UPDATE palettes SET palette = ? WHERE title = ?",
+	insertPaletteSQL: "INSERT INTO palettes (title, palette) VALUES(?, ?)",
+	removePaletteSQL: "DELETE FROM palettes WHERE title = ?",
+
+	listPlaylistsSQL:  "SELECT title FROM playlists ORDER BY title;",
+	readPlaylistSQL:   "SELECT playlist FROM playlists WHERE title = ?",
+	existsPlaylistSQL: "SELECT title FROM playlists WHERE title = ?;",
+	updatePlaylistSQL: "UPDATE playlists SET playlist = ? WHERE title = ?",
+	insertPlaylistSQL: "INSERT INTO playlists (title, playlist) VALUES(?, ?)",
+	removePlaylistSQL: "DELETE FROM playlists WHERE title = ?",
 }
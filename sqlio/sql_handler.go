@@ -3,10 +3,12 @@ package sqlio
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"gglow/glow"
 	"gglow/iohandler"
 	"gglow/text"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -15,6 +17,8 @@ import (
 )
 
 var _ iohandler.IoHandler = (*SqlHandler)(nil)
+var _ iohandler.PaletteHandler = (*SqlHandler)(nil)
+var _ iohandler.PlaylistHandler = (*SqlHandler)(nil)
 
 type SqlHandler struct {
 	db         *sql.DB
@@ -35,6 +39,11 @@ func NewSqlHandler(driver, dsn string) (*SqlHandler, error) {
 		iohandler.LogError(text.MsgParseEffectPath.Format(dsn), err)
 		return nil, err
 	}
+	err = sqlh.alter()
+	if err != nil {
+		sqlh.db.Close()
+		return nil, err
+	}
 	return sqlh, nil
 }
 
@@ -76,6 +85,10 @@ func (sqlh *SqlHandler) ReadEffect(folder, title string) (frame *glow.Frame, err
 		return
 	}
 
+	resolveErr := frame.ResolvePalettes(sqlh.ReadPalette)
+	if resolveErr != nil {
+		iohandler.LogError(fmt.Sprintf("sqlh.ReadEffect palettes: %s/%s", folder, title), resolveErr)
+	}
 	return
 }
 
@@ -223,6 +236,10 @@ func (sqlh *SqlHandler) Create(name string) error {
 		// }
 	}
 
+	if err := sqlh.dropLegacyViews(ctx); err != nil {
+		iohandler.LogError("CreateNewDatabase", err)
+		return err
+	}
 	for _, query := range sqlh.schema.DropSQL {
 		_, err := sqlh.db.ExecContext(ctx, query)
 		if err != nil {
@@ -239,3 +256,225 @@ func (sqlh *SqlHandler) Create(name string) error {
 	}
 	return nil
 }
+
+// alter brings databases made before the current schema up to date.
+// It runs once, as the handler opens.
+func (sqlh *SqlHandler) alter() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := sqlh.dropLegacyViews(ctx); err != nil {
+		iohandler.LogError("AlterDatabase", err)
+		return err
+	}
+	for _, query := range sqlh.schema.AlterSQL {
+		_, err := sqlh.db.ExecContext(ctx, query)
+		if err != nil {
+			iohandler.LogError("AlterDatabase", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// dropLegacyViews drops the schema's legacy views the database has.
+// Dropping a view by a table's name is an error, so each is looked up.
+func (sqlh *SqlHandler) dropLegacyViews(ctx context.Context) error {
+	for _, name := range sqlh.schema.LegacyViews {
+		var found string
+		err := sqlh.db.QueryRowContext(ctx, sqlh.viewQuery(name)).Scan(&found)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = sqlh.db.ExecContext(ctx, fmt.Sprintf("DROP VIEW %s;", name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sqlh *SqlHandler) viewQuery(name string) string {
+	switch sqlh.driver {
+	case iohandler.DRIVER_SQLLITE3:
+		return fmt.Sprintf("SELECT name FROM sqlite_master WHERE type = 'view' AND name = '%s';", name)
+	case iohandler.DRIVER_MYSQL:
+		return fmt.Sprintf("SELECT table_name FROM information_schema.views WHERE table_schema = DATABASE() AND table_name = '%s';", name)
+	}
+	return fmt.Sprintf("SELECT table_name FROM information_schema.views WHERE table_schema = current_schema() AND table_name = '%s';", name)
+}
+
+// bind numbers the ? placeholders of query for postgres.
+func (sqlh *SqlHandler) bind(query string) string {
+	if sqlh.driver != iohandler.DRIVER_POSTGRES {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (sqlh *SqlHandler) ListPalettes() (ls []string, err error) {
+	ls = make([]string, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	query := sqlh.schema.ListPalettes()
+	rows, err := sqlh.db.QueryContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("ListPalettes '%s' %v", query, err)
+		return
+	}
+	defer rows.Close()
+
+	var scanTitle string
+	for rows.Next() {
+		err = rows.Scan(&scanTitle)
+		if err != nil {
+			break
+		}
+		ls = append(ls, scanTitle)
+	}
+	return ls, err
+}
+
+func (sqlh *SqlHandler) ReadPalette(title string) (palette *glow.Palette, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var source []byte
+	query, args := sqlh.schema.ReadPalette(title)
+	row := sqlh.db.QueryRowContext(ctx, sqlh.bind(query), args...)
+	err = row.Scan(&source)
+	if err != nil {
+		return nil, fmt.Errorf("ReadPalette %s: %v", title, err)
+	}
+
+	palette = &glow.Palette{}
+	err = json.Unmarshal(source, palette)
+	if err != nil {
+		return nil, fmt.Errorf("ReadPalette %s json: %v", title, err)
+	}
+	palette.Title = title
+	return
+}
+
+func (sqlh *SqlHandler) WritePalette(palette *glow.Palette) error {
+	source, err := json.Marshal(palette)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var found string
+	query, args := sqlh.schema.ExistsPalette(palette.Title)
+	row := sqlh.db.QueryRowContext(ctx, sqlh.bind(query), args...)
+	update := row.Scan(&found) == nil
+
+	query, args = sqlh.schema.WritePalette(update, palette.Title, string(source))
+	_, err = sqlh.db.ExecContext(ctx, sqlh.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("unable to execute query: '%s' %v", query, err)
+	}
+	return nil
+}
+
+func (sqlh *SqlHandler) RemovePalette(title string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	query, args := sqlh.schema.RemovePalette(title)
+	_, err := sqlh.db.ExecContext(ctx, sqlh.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("unable to execute query: '%s' %v", query, err)
+	}
+	return nil
+}
+
+func (sqlh *SqlHandler) ListPlaylists() (ls []string, err error) {
+	ls = make([]string, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	query := sqlh.schema.ListPlaylists()
+	rows, err := sqlh.db.QueryContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("ListPlaylists '%s' %v", query, err)
+		return
+	}
+	defer rows.Close()
+
+	var scanTitle string
+	for rows.Next() {
+		err = rows.Scan(&scanTitle)
+		if err != nil {
+			break
+		}
+		ls = append(ls, scanTitle)
+	}
+	return ls, err
+}
+
+func (sqlh *SqlHandler) ReadPlaylist(title string) (playlist *glow.Playlist, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var source []byte
+	query, args := sqlh.schema.ReadPlaylist(title)
+	row := sqlh.db.QueryRowContext(ctx, sqlh.bind(query), args...)
+	err = row.Scan(&source)
+	if err != nil {
+		return nil, fmt.Errorf("ReadPlaylist %s: %v", title, err)
+	}
+
+	playlist = &glow.Playlist{}
+	err = json.Unmarshal(source, playlist)
+	if err != nil {
+		return nil, fmt.Errorf("ReadPlaylist %s json: %v", title, err)
+	}
+	playlist.Title = title
+	return
+}
+
+func (sqlh *SqlHandler) WritePlaylist(playlist *glow.Playlist) error {
+	source, err := json.Marshal(playlist)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var found string
+	query, args := sqlh.schema.ExistsPlaylist(playlist.Title)
+	row := sqlh.db.QueryRowContext(ctx, sqlh.bind(query), args...)
+	update := row.Scan(&found) == nil
+
+	query, args = sqlh.schema.WritePlaylist(update, playlist.Title, string(source))
+	_, err = sqlh.db.ExecContext(ctx, sqlh.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("unable to execute query: '%s' %v", query, err)
+	}
+	return nil
+}
+
+func (sqlh *SqlHandler) RemovePlaylist(title string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	query, args := sqlh.schema.RemovePlaylist(title)
+	_, err := sqlh.db.ExecContext(ctx, sqlh.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("unable to execute query: '%s' %v", query, err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"fmt"
 	"gglow/codeio"
+	"gglow/glow"
+	"gglow/imageio"
 	"gglow/iohandler"
 	"gglow/sqlio"
 	"path/filepath"
@@ -41,8 +43,36 @@ func NewIoHandler(config *iohandler.Accessor) (handler iohandler.IoHandler, err
 }
 
 func NewOutHandler(config *iohandler.Accessor) (handler iohandler.OutHandler, err error) {
-	if config.Driver == iohandler.DRIVER_CODE {
-		handler, err = codeio.NewCodeHandler(config.Path)
+	switch config.Driver {
+	case iohandler.DRIVER_CODE:
+		var code *codeio.CodeHandler
+		code, err = codeio.NewCodeHandler(config.Path)
+		if err == nil && config.PixelMap != "" {
+			err = code.LoadPixelMap(config.PixelMap)
+		}
+		if config.Output != nil {
+			code.SetOutput(config.Output)
+		}
+		if config.White != nil {
+			code.SetWhite(config.White)
+		}
+		code.SetWiring(config.Wiring)
+		if err == nil {
+			handler = code
+		}
+		return
+	case iohandler.DRIVER_GIF, iohandler.DRIVER_APNG, iohandler.DRIVER_SPRITES:
+		var images *imageio.ImageHandler
+		images, err = imageio.NewImageHandler(config.Driver, config.Path)
+		if err == nil {
+			images.Preview.Apply(config.Preview)
+		}
+		if err == nil && config.PixelMap != "" {
+			images.Preview.Map, err = glow.LoadPixelMap(config.PixelMap)
+		}
+		if err == nil {
+			handler = images
+		}
 		return
 	}
 	handler, err = sqlio.NewSqlHandler(config.Driver, makeDSN(config))
@@ -66,6 +66,57 @@ const (
 	ReviewLabel
 	ImageLabel
 	ImageLoad
+	PreviewLabel
+	BlendLabel
+	OpacityLabel
+	BrightnessLabel
+	AttackLabel
+	SustainLabel
+	DecayLabel
+	ScanModeLabel
+	SpeedLabel
+	WiringLabel
+	PixelMapLabel
+	NoiseLabel
+	SeedLabel
+	ScaleLabel
+	DriftLabel
+	ParticlesLabel
+	SpawnLabel
+	LifetimeLabel
+	FadeLabel
+	TailLabel
+	MessageLabel
+	DirectionLabel
+	GammaLabel
+	TemperatureLabel
+	SupplyLabel
+	PowerLabel
+	FormatLabel
+	WhiteLabel
+	WhiteTemperatureLabel
+	InterpolationLabel
+	PositionLabel
+	PaletteLabel
+	PalettesLabel
+	NoPaletteLabel
+	PlaylistLabel
+	PlaylistsLabel
+	TransitionLabel
+	DurationLabel
+	PlayLabel
+	ModulatorsLabel
+	TargetLabel
+	WaveformLabel
+	DepthLabel
+	PhaseLabel
+	StopLabel
+	MaskLabel
+	ClipLabel
+	ExpressionLabel
+	DiagnosticsLabel
+	NoDiagnosticsLabel
+	SequenceLabel
 )
 
 var entryLabels = []string{
@@ -90,6 +141,24 @@ var entryLabels = []string{
 	"Action has errors. Check the log.",
 	"Action was successful!",
 	"Manage", "Review", "Image", "Image Loader",
+	"Preview", "Blend",
+	"Opacity (%)", "Brightness (%)",
+	"Attack", "Sustain", "Decay",
+	"Motion", "Speed",
+	"Wiring", "Pixel Map",
+	"Noise", "Seed", "Scale", "Drift",
+	"Particles", "Spawn (%)", "Lifetime", "Fade", "Tail",
+	"Message", "Direction",
+	"Gamma", "Temperature (K)",
+	"Supply (mA)", "Power",
+	"Format", "White", "White (K)",
+	"Interpolation", "Position",
+	"Palette", "Palettes", "None",
+	"Playlist", "Playlists", "Transition", "Duration (ms)", "Play",
+	"Modulators", "Target", "Waveform", "Depth", "Phase (°)", "Stop",
+	"Mask", "Clip to Layer Below", "Expression",
+	"Diagnostics", "No problems found",
+	"Numbered Sequence",
 }
 
 func (id LabelID) String() string {
@@ -106,6 +175,8 @@ var OrientationLabels = []string{
 	"Level",
 	"Upright",
 	"Tilted",
+	"Centred",
+	"Concentric",
 }
 
 func (id OrientationID) String() string {
@@ -132,3 +203,252 @@ func (id OriginID) String() string {
 func (id OriginID) PlaceHolder() string {
 	return strings.ToLower(OriginLabels[id])
 }
+
+type BlendID glow.BlendMode
+
+var BlendLabels = []string{
+	"Replace",
+	"Add",
+	"Multiply",
+	"Screen",
+	"Max",
+	"Min",
+	"Alpha Over",
+	"Difference",
+}
+
+func (id BlendID) String() string {
+	return BlendLabels[id]
+}
+
+func (id BlendID) PlaceHolder() string {
+	return strings.ToLower(BlendLabels[id])
+}
+
+type ScanModeID glow.ScanMode
+
+var ScanModeLabels = []string{
+	"Forward",
+	"Reverse",
+	"Ping Pong",
+	"Random",
+	"Ease",
+	"Sine",
+	"Bounce",
+}
+
+func (id ScanModeID) String() string {
+	return ScanModeLabels[id]
+}
+
+func (id ScanModeID) PlaceHolder() string {
+	return strings.ToLower(ScanModeLabels[id])
+}
+
+type WiringID glow.Wiring
+
+var WiringLabels = []string{
+	"Progressive",
+	"Serpentine",
+	"Column Progressive",
+	"Column Serpentine",
+}
+
+func (id WiringID) String() string {
+	return WiringLabels[id]
+}
+
+func (id WiringID) PlaceHolder() string {
+	return strings.ToLower(WiringLabels[id])
+}
+
+type NoiseID glow.NoiseKind
+
+var NoiseLabels = []string{
+	"None",
+	"Fire",
+	"Plasma",
+	"Lava",
+	"Clouds",
+}
+
+func (id NoiseID) String() string {
+	return NoiseLabels[id]
+}
+
+func (id NoiseID) PlaceHolder() string {
+	return strings.ToLower(NoiseLabels[id])
+}
+
+type ParticleID glow.ParticleKind
+
+var ParticleLabels = []string{
+	"None",
+	"Twinkle",
+	"Meteor",
+	"Confetti",
+}
+
+func (id ParticleID) String() string {
+	return ParticleLabels[id]
+}
+
+func (id ParticleID) PlaceHolder() string {
+	return strings.ToLower(ParticleLabels[id])
+}
+
+type FadeID glow.FadeCurve
+
+var FadeLabels = []string{
+	"Linear",
+	"Quadratic",
+	"Sudden",
+}
+
+func (id FadeID) String() string {
+	return FadeLabels[id]
+}
+
+func (id FadeID) PlaceHolder() string {
+	return strings.ToLower(FadeLabels[id])
+}
+
+type TextDirectionID glow.TextDirection
+
+var TextDirectionLabels = []string{
+	"Left",
+	"Right",
+	"Up",
+	"Down",
+	"Static",
+}
+
+func (id TextDirectionID) String() string {
+	return TextDirectionLabels[id]
+}
+
+func (id TextDirectionID) PlaceHolder() string {
+	return strings.ToLower(TextDirectionLabels[id])
+}
+
+type ColorFormatID glow.ColorFormat
+
+var ColorFormatLabels = []string{
+	"RGB",
+	"RGBW",
+	"RGBWW",
+}
+
+func (id ColorFormatID) String() string {
+	return ColorFormatLabels[id]
+}
+
+func (id ColorFormatID) PlaceHolder() string {
+	return strings.ToLower(ColorFormatLabels[id])
+}
+
+type WhiteModeID glow.WhiteMode
+
+var WhiteModeLabels = []string{
+	"Minimum",
+	"Temperature",
+}
+
+func (id WhiteModeID) String() string {
+	return WhiteModeLabels[id]
+}
+
+func (id WhiteModeID) PlaceHolder() string {
+	return strings.ToLower(WhiteModeLabels[id])
+}
+
+type InterpolationID glow.Interpolation
+
+var InterpolationLabels = []string{
+	"HSV",
+	"HSV Shortest",
+	"HSV Reverse",
+	"Linear RGB",
+	"OKLab",
+	"OKLCh",
+}
+
+func (id InterpolationID) String() string {
+	return InterpolationLabels[id]
+}
+
+func (id InterpolationID) PlaceHolder() string {
+	return strings.ToLower(InterpolationLabels[id])
+}
+
+type TransitionID glow.Transition
+
+var TransitionLabels = []string{
+	"Cut",
+	"Crossfade",
+	"Wipe",
+	"Dissolve",
+}
+
+func (id TransitionID) String() string {
+	return TransitionLabels[id]
+}
+
+func (id TransitionID) PlaceHolder() string {
+	return strings.ToLower(TransitionLabels[id])
+}
+
+type ModTargetID glow.ModTarget
+
+var ModTargetLabels = []string{
+	"Begin",
+	"End",
+	"Scan",
+	"Opacity",
+	"Rate",
+	"Hue",
+	"Saturation",
+	"Value",
+}
+
+func (id ModTargetID) String() string {
+	return ModTargetLabels[id]
+}
+
+func (id ModTargetID) PlaceHolder() string {
+	return strings.ToLower(ModTargetLabels[id])
+}
+
+type WaveformID glow.Waveform
+
+var WaveformLabels = []string{
+	"Sine",
+	"Triangle",
+	"Saw",
+	"Square",
+	"Random",
+}
+
+func (id WaveformID) String() string {
+	return WaveformLabels[id]
+}
+
+func (id WaveformID) PlaceHolder() string {
+	return strings.ToLower(WaveformLabels[id])
+}
+
+type MaskModeID glow.MaskMode
+
+var MaskModeLabels = []string{
+	"None",
+	"Luminance",
+	"Alpha",
+}
+
+func (id MaskModeID) String() string {
+	return MaskModeLabels[id]
+}
+
+func (id MaskModeID) PlaceHolder() string {
+	return strings.ToLower(MaskModeLabels[id])
+}